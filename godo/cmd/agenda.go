@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"godo/internal/report"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var agendaDays int
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "未完了のタスクを期限ごとに表示する",
+	Long: `未完了のタスクを 期限切れ / 今日 / 明日 / 今週 / それ以降 / 期限なし に分けて表示します。
+
+--days N を指定すると、期限が今日からN日以内（期限切れを含む）のタスクだけを表示します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		tasks := tm.GetTasks()
+		if agendaDays > 0 {
+			tasks = report.DueWithin(tasks, now, agendaDays)
+		}
+		report.WriteAgenda(os.Stdout, report.BuildAgenda(tasks, now))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(agendaCmd)
+	agendaCmd.Flags().IntVar(&agendaDays, "days", 0, "今日からN日以内に期限があるタスクだけを表示する")
+}
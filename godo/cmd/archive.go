@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/config"
+	"godo/pkg/models"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveOlderThan string
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "完了済みのタスクをアーカイブに移す",
+	Long: `完了済みのタスクを ~/.godo/archive.json に移します。
+
+--older-than を指定すると、完了してから指定期間が経過したタスクだけを移します（例: 7d, 2w, 36h）。
+アーカイブしたタスクは godo list --archived で確認し、godo restore で戻せます。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireLocal("アーカイブ"); err != nil {
+			return err
+		}
+		olderThan, err := config.ParseAge(archiveOlderThan)
+		if err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		archived, err := ts.ArchiveCompleted(tm, olderThan, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, task := range archived {
+			fmt.Println(formatTaskLine(task))
+		}
+		fmt.Printf("%d件のタスクをアーカイブしました\n", len(archived))
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "アーカイブしたタスクを戻す",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireLocal("アーカイブ"); err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		archive, err := ts.LoadArchive()
+		if err != nil {
+			return err
+		}
+		archived := models.NewTaskManager(archive)
+		index, err := findTaskIndex(archived, args[0])
+		if err != nil {
+			return fmt.Errorf("アーカイブに%w", err)
+		}
+
+		task := archived.GetTaskByIndex(index)
+		archived.DeleteTask(index)
+		// 先にタスクを保存し、アーカイブから消してもタスクが失われないようにする
+		restored := tm.RestoreTask(task)
+		if err := saveTasksThen(ts, tm, func() error { return ts.SaveArchive(archived.GetTasks()) }); err != nil {
+			return err
+		}
+
+		fmt.Printf("'%s' をアーカイブから戻しました (ID: %d)\n", restored.Title, restored.ID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(restoreCmd)
+	archiveCmd.Flags().StringVar(&archiveOlderThan, "older-than", "", "完了してから指定期間が経過したタスクだけをアーカイブする（例: 7d）")
+}
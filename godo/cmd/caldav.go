@@ -0,0 +1,90 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/caldav"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	caldavURL  string
+	caldavUser string
+)
+
+var caldavCmd = &cobra.Command{
+	Use:   "caldav",
+	Short: "CalDAVサーバーとタスクを同期する",
+}
+
+var caldavSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "CalDAVのカレンダーコレクションとタスクを双方向に同期する",
+	Long: `タスクを VTODO として CalDAV サーバーのカレンダーコレクションと双方向に同期します。
+
+--url にはコレクションのURL（例: https://dav.example.com/calendars/team/todo/）を指定します。
+URL とユーザー名は環境変数 GODO_CALDAV_URL / GODO_CALDAV_USER でも指定でき、
+パスワードは環境変数 GODO_CALDAV_PASSWORD で指定します。
+
+前回の同期の状態は ~/.godo/caldav.json に保存し、両方で変更されたタスクは更新日時の新しい方を採用します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := caldavURL
+		if url == "" {
+			url = os.Getenv("GODO_CALDAV_URL")
+		}
+		if url == "" {
+			return errors.New("CalDAVのURLを --url または GODO_CALDAV_URL で指定してください")
+		}
+		user := caldavUser
+		if user == "" {
+			user = os.Getenv("GODO_CALDAV_USER")
+		}
+
+		if err := requireLocal("CalDAVとの同期"); err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		statePath := filepath.Join(filepath.Dir(ts.GetFilePath()), "caldav.json")
+		state, err := caldav.LoadState(statePath)
+		if err != nil {
+			return err
+		}
+
+		client := caldav.NewClient(url, user, os.Getenv("GODO_CALDAV_PASSWORD"))
+		report, err := caldav.Sync(context.Background(), client, tm, state)
+		if err != nil {
+			return err
+		}
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+		if err := state.Save(statePath); err != nil {
+			return err
+		}
+
+		for _, err := range report.Errors {
+			fmt.Println("警告:", err)
+		}
+		fmt.Printf("取り込み %d / 書き込み %d / タスクを削除 %d / サーバーから削除 %d / 競合 %d\n",
+			report.Pulled, report.Pushed, report.DeletedLocal, report.DeletedRemote, report.Conflicts)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(caldavCmd)
+	caldavCmd.AddCommand(caldavSyncCmd)
+	caldavSyncCmd.Flags().StringVar(&caldavURL, "url", "", "カレンダーコレクションのURL")
+	caldavSyncCmd.Flags().StringVar(&caldavUser, "user", "", "ユーザー名")
+}
@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var compactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "ジャーナルの変更をスナップショットにまとめる",
+	Long: `--storage journal で記録した変更（~/.godo/journal.jsonl）を
+スナップショット（~/.godo/tasks.json）にまとめ、ジャーナルを空にします。
+
+ジャーナルは一定数の変更ごとに自動でまとめられるため、通常は実行する必要はありません。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireLocal("ジャーナルのまとめ"); err != nil {
+			return err
+		}
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storage.JournalMode)
+		unlock, err := ts.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if err := ts.Compact(); err != nil {
+			return err
+		}
+		fmt.Printf("ジャーナルを %s にまとめました\n", ts.GetFilePath())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compactCmd)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"godo/internal/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "設定を表示・変更する",
+	Long: `設定ファイル（~/.config/godo/config.toml、--config か環境変数 GODO_CONFIG で変更可）を扱います。
+
+設定できる項目:
+  storage.dir          タスクを保存するディレクトリ（デフォルトは ~/.godo）
+  storage.mode         保存形式（snapshot / journal）
+  list.sort            godo list の並び順（id / priority / due / created / updated / title）
+  display.date_format  TUI の日時の表示形式（Go のレイアウト、例: "01/02 15:04"）
+  display.theme        TUI の配色（default / light / mono）
+  keys.<操作>          TUI のキー割り当て（toggle, add, edit, delete, timer, pomodoro, board, agenda, archive, history, up, down, quit）
+  archive.auto         完了してから指定期間が経過したタスクを保存時にアーカイブする（例: 30d）
+  hooks.enabled        フックを実行するか（true / false）
+  hooks.dir            フックを置くディレクトリ（空なら storage.dir の hooks）
+  hooks.timeout        フックの1回の実行を打ち切るまでの時間（例: 5s）
+
+設定の値は環境変数 GODO_<セクション>_<キー>（例: GODO_DISPLAY_THEME=mono）で上書きでき、
+コマンドのフラグ（--storage、--auto-archive、--no-hooks、--sort）は設定と環境変数より優先します。`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [<name>]",
+	Short: "設定の値を表示する（省略するとすべて）",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			value, err := settings.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		}
+		for _, name := range config.Names() {
+			value, _ := settings.Get(name)
+			fmt.Printf("%s = %q\n", name, value)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <name> <value>",
+	Short: "設定ファイルの値を変更する",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Set(configPath, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("%s を %q に設定しました（%s）\n", args[0], args[1], configPath)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "設定ファイルをエディタ（$VISUAL / $EDITOR）で開く",
+	Long: `設定ファイルを環境変数 VISUAL か EDITOR のエディタで開きます（どちらもなければ vi、Windows では notepad）。
+設定ファイルがなければ、すべての項目をデフォルト値でコメントアウトしたひな形を作成します。
+エディタを閉じた後に設定を検証し、誤りがあれば行番号付きで表示します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(configPath, []byte(config.Template()), 0644); err != nil {
+				return err
+			}
+		}
+
+		editor := editorCommand()
+		c := exec.Command(editor[0], append(editor[1:], configPath)...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("エディタ %s を実行できません: %w", editor[0], err)
+		}
+
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return err
+		}
+		if err := config.Validate(configPath, string(data)); err != nil {
+			return fmt.Errorf("設定に誤りがあります（godo config edit で修正してください）\n%w", err)
+		}
+		fmt.Printf("%s を保存しました\n", configPath)
+		return nil
+	},
+}
+
+// editorCommand 設定ファイルを開くエディタのコマンドと引数を返す
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configEditCmd)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/report"
+	"godo/pkg/tasks"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var dueCmd = &cobra.Command{
+	Use:   "due <id> [date]",
+	Short: "タスクの期限を設定する",
+	Long: `タスクの期限を設定します。
+
+日付は 2025-01-31、"2025-01-31 15:00"、today、tomorrow の形式で指定できます。
+日付を省略すると期限の設定を解除します。`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		id, err := parseTaskID(args[0])
+		if err != nil {
+			return err
+		}
+
+		var due *time.Time
+		if len(args) == 2 {
+			t, err := parseDate(args[1], time.Now())
+			if err != nil {
+				return err
+			}
+			due = &t
+		}
+		task, err := tasks.Wrap(tm).SetDue(id, due)
+		if err != nil {
+			return err
+		}
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+
+		if due == nil {
+			fmt.Printf("'%s' の期限を解除しました\n", task.Title)
+		} else {
+			fmt.Printf("'%s' の期限を %s に設定しました\n", task.Title, report.FormatDue(*due))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dueCmd)
+}
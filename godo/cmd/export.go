@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/format"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportOutput   string
+	exportArchived bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "タスクを他の形式で書き出す",
+	Long: `タスクを他のツールの形式で書き出します。
+--output を省略すると標準出力に書き出します。--archived を指定するとアーカイブしたタスクも含めます。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		write, ok := exporters[exportFormat]
+		if !ok {
+			return fmt.Errorf("未対応の形式です: %q (対応: %s)", exportFormat, formatNames(exporters))
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		key, assignIDs := formatIDKeys[exportFormat]
+		if assignIDs {
+			if err := assignExportIDs(ts, tm, key); err != nil {
+				return err
+			}
+		}
+		tasks := tm.GetTasks()
+		if exportArchived {
+			if err := requireLocal("アーカイブの書き出し"); err != nil {
+				return err
+			}
+			archive, err := ts.LoadArchive()
+			if err != nil {
+				return err
+			}
+			if assignIDs && assignArchiveIDs(archive, key) {
+				if err := ts.SaveArchive(archive); err != nil {
+					return err
+				}
+			}
+			tasks = append(tasks, archive...)
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOutput != "" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("ファイルを作成できません: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		return write(out, tasks)
+	},
+}
+
+// assignExportIDs ID を記録していないタスクに ID を割り当てて保存する
+func assignExportIDs(ts *storage.TaskStorage, tm *models.TaskManager, key string) error {
+	changed := false
+	for i, task := range tm.GetTasks() {
+		if task.Meta[key] == "" {
+			format.EnsureID(tm, i, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveTasks(ts, tm)
+}
+
+// assignArchiveIDs ID を記録していないアーカイブのタスクに ID を割り当て、割り当てたかを返す
+func assignArchiveIDs(archive []*models.Task, key string) bool {
+	changed := false
+	for _, task := range archive {
+		if task.Meta[key] != "" {
+			continue
+		}
+		if task.Meta == nil {
+			task.Meta = map[string]string{}
+		}
+		task.Meta[key] = format.NewUUID()
+		changed = true
+	}
+	return changed
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "todotxt", "書き出す形式")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "書き出すファイル（省略時は標準出力）")
+	exportCmd.Flags().BoolVar(&exportArchived, "archived", false, "アーカイブしたタスクも含める")
+}
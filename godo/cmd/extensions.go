@@ -0,0 +1,199 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"godo/pkg/extension"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var extensionsCmd = &cobra.Command{
+	Use:   "extensions",
+	Short: "PATH から見つかった拡張機能を表示する",
+	Long: `PATH にある godo-<name> という名前の実行ファイルを拡張機能として表示します。
+
+拡張機能は git と同じように godo のサブコマンドを追加できるほか、タスクごとの計算項目を追加できます。
+計算項目は godo list --fields で表示でき、column を指定した項目は TUI の一覧にも表示します。
+拡張機能とは標準入出力の JSON でやり取りします（形式は pkg/extension を参照してください）。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		set := loadExtensions()
+		if len(set) == 0 {
+			fmt.Println("拡張機能は見つかりませんでした")
+			return nil
+		}
+		for _, e := range set {
+			fmt.Printf("%s  %s\n", e.Manifest.Name, e.Path)
+			if e.Manifest.Description != "" {
+				fmt.Printf("  %s\n", e.Manifest.Description)
+			}
+			for _, c := range e.Manifest.Commands {
+				if builtinCommand(c.Name) || set.Command(c.Name) != e {
+					fmt.Printf("  コマンド: %s（同じ名前のコマンドがあるため使えません）\n", c.Name)
+					continue
+				}
+				fmt.Printf("  コマンド: godo %s  %s\n", c.Name, c.Short)
+			}
+			for _, f := range e.Manifest.Fields {
+				if f.Column {
+					fmt.Printf("  項目: %s（TUI に表示）\n", f.Label())
+				} else {
+					fmt.Printf("  項目: %s\n", f.Label())
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// loadExtensions PATH から拡張機能を探す
+// 拡張機能の Describe の結果はキャッシュし、実行ファイルが変わった場合だけ問い合わせ直す
+func loadExtensions() extension.Set {
+	cache := extension.OpenCache(extensionCachePath())
+	set, err := extension.LoadCached(context.Background(), os.Getenv("PATH"), cache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "警告: %v\n", err)
+	}
+	cache.Save()
+	return set
+}
+
+// registerExtension args のサブコマンドが godo のコマンドでなければ、拡張機能のサブコマンドとして登録する
+// git と同じく、まず godo-<name> を探し、なければ他の拡張機能が追加したサブコマンドから探す
+// godo のコマンドを実行する場合は拡張機能を実行しない
+func registerExtension(args []string) {
+	name := commandName(args)
+	if name == "" || builtinCommand(name) {
+		return
+	}
+	cache := extension.OpenCache(extensionCachePath())
+	defer cache.Save()
+	var found *extension.Extension
+	if e := extension.Find(os.Getenv("PATH"), name); e != nil {
+		if _, err := cache.Describe(context.Background(), e); err != nil {
+			fmt.Fprintf(os.Stderr, "警告: %v\n", err)
+		} else if (extension.Set{e}).Command(name) != nil {
+			found = e
+		}
+	}
+	if found == nil {
+		set, _ := extension.LoadCached(context.Background(), os.Getenv("PATH"), cache)
+		found = set.Command(name)
+	}
+	if found == nil {
+		return
+	}
+	for _, c := range found.Manifest.Commands {
+		if c.Name == name {
+			rootCmd.AddCommand(newExtensionCommand(found, c))
+		}
+	}
+}
+
+// commandName 引数から実行するサブコマンドの名前（godo のフラグとその値を除いた最初の引数）を返す
+func commandName(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *pflag.Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			flag = rootCmd.PersistentFlags().Lookup(name)
+		} else if len(arg) == 2 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+		// 値を取るフラグは次の引数が値になる
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return ""
+}
+
+// extensionCachePath 拡張機能の Describe の結果を保存するファイルのパス
+func extensionCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = filepath.Join(settings.Storage.Dir, "cache")
+	}
+	return filepath.Join(dir, "godo", "extensions.json")
+}
+
+// builtinCommand godo のコマンドの名前か
+func builtinCommand(name string) bool {
+	if name == "help" || name == "completion" {
+		return true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// newExtensionCommand 拡張機能のサブコマンドを実行するコマンドを作成する
+// 引数とフラグはそのまま拡張機能に渡す
+func newExtensionCommand(e *extension.Extension, c extension.Command) *cobra.Command {
+	short := c.Short
+	if short == "" {
+		short = fmt.Sprintf("拡張機能 %s のコマンド", e.Manifest.Name)
+	}
+	return &cobra.Command{
+		Use:                c.Name,
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, tm, err := loadTasks()
+			if err != nil {
+				return err
+			}
+			// 拡張機能が godo を実行しても待ち続けないように、実行中はロックを外す
+			releaseTasks()
+			resp, err := e.Run(cmd.Context(), c.Name, args, tm.GetTasks())
+			if err != nil {
+				return err
+			}
+			if resp.Output != "" {
+				fmt.Print(resp.Output)
+				if !strings.HasSuffix(resp.Output, "\n") {
+					fmt.Println()
+				}
+			}
+			if len(resp.Tasks) == 0 && len(resp.Deleted) == 0 {
+				return nil
+			}
+			// 実行中に他のプロセスが変更したタスクを消さないように、読み込み直してから反映する
+			ts, tm, err := loadTasks()
+			if err != nil {
+				return err
+			}
+			if _, err := extension.Apply(tm, resp); err != nil {
+				return err
+			}
+			return saveTasks(ts, tm)
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(extensionsCmd)
+}
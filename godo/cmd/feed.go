@@ -0,0 +1,60 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var feedAddr string
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "タスクの変更を Server-Sent Events で配信する",
+	Long: `タスクの作成・変更・削除を Server-Sent Events（GET /api/events）で配信するサーバーを起動します。
+
+CLI や TUI、godo serve での変更は変更履歴（~/.godo/history.jsonl）を通じてすぐに配信されます。
+各イベントは event が created / updated / deleted、id がイベントID、data が変更内容と変更後のタスクのJSONです。
+
+接続が切れた場合は Last-Event-ID ヘッダー（または last_event_id パラメータ）に最後に受け取ったイベントIDを指定すると、
+その後の変更から受け取り直せます。指定しなければ接続した時点より後の変更を配信します。
+
+認証は godo serve と同じく --token か環境変数 GODO_API_TOKEN のトークンを使い、
+ブラウザの EventSource からは token パラメータで指定できます。
+godo serve でも同じ配信を /api/events で提供しています。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, err := serveToken()
+		if err != nil {
+			return err
+		}
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storageMode)
+
+		mux := http.NewServeMux()
+		tasks := api.NewAccess(api.NewStorageStore(ts, models.DefaultWorkflow()))
+		mux.Handle("GET /api/events", newFeedHandler(ts, tasks, token))
+		fmt.Printf("http://%s/api/events で変更を配信しています（Ctrl+C で終了）\n", feedAddr)
+		return listenAndServe(feedAddr, mux)
+	},
+}
+
+// newFeedHandler 変更履歴ファイルからタスクの変更を配信するハンドラーを作成する
+// 変更されたタスクの現在の内容は tasks から読み込むので、同じ tasks を使うサーバーのリクエストと重ならない
+func newFeedHandler(ts *storage.TaskStorage, tasks *api.Access, token string) *feed.Handler {
+	return feed.NewHandler(feed.NewHistory(ts.GetHistoryPath()), tasks.Tasks, token)
+}
+
+func init() {
+	rootCmd.AddCommand(feedCmd)
+	feedCmd.Flags().StringVar(&feedAddr, "addr", "127.0.0.1:8081", "待ち受けるアドレス")
+	feedCmd.Flags().BoolVar(&serveNoAuth, "no-auth", false, "トークンを指定していなくても認証なしで起動する")
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/format"
+	"godo/internal/format/csv"
+	"godo/internal/format/ical"
+	"godo/internal/format/markdown"
+	"godo/internal/format/org"
+	"godo/internal/format/taskwarrior"
+	"godo/internal/format/todotxt"
+	"godo/pkg/models"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// importer 形式ごとの読み込み処理
+type importer func(r io.Reader) (*format.Result, error)
+
+// exporter 形式ごとの書き出し処理
+type exporter func(w io.Writer, tasks []*models.Task) error
+
+var importers = map[string]importer{
+	"todotxt":     todotxt.Read,
+	"markdown":    markdown.Read,
+	"ics":         ical.Read,
+	"taskwarrior": taskwarrior.Read,
+	"org":         org.Read,
+	"csv": func(r io.Reader) (*format.Result, error) {
+		return csv.Read(r, csv.Options{Mapping: importMapping})
+	},
+	"tsv": func(r io.Reader) (*format.Result, error) {
+		return csv.Read(r, csv.Options{Comma: '\t', Mapping: importMapping})
+	},
+}
+
+var exporters = map[string]exporter{
+	"todotxt":     todotxt.Write,
+	"markdown":    markdown.Write,
+	"ics":         ical.Write,
+	"taskwarrior": taskwarrior.Write,
+	"org":         org.Write,
+}
+
+// formatIDKeys 書き出したタスクを後で見分けるための ID を記録する Meta のキー
+// 書き出すときに一度記録した ID を使い続け、読み込むときは同じ ID のタスクを新しく追加せずに更新する
+var formatIDKeys = map[string]string{
+	"ics":         ical.MetaUID,
+	"taskwarrior": taskwarrior.MetaUUID,
+	"org":         org.MetaUID,
+}
+
+// formatNames 対応している形式の一覧を表示用の文字列で返す
+func formatNames[T any](formats map[string]T) string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// openInput ファイルを開く。"-" の場合は標準入力を使う
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ファイルを開けません: %w", err)
+	}
+	return f, nil
+}
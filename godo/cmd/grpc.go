@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/internal/rpc"
+	"godo/internal/storage"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var grpcAddr string
+
+var grpcCmd = &cobra.Command{
+	Use:   "grpc",
+	Short: "タスクを gRPC の API として公開する",
+	Long: `タスクを gRPC の API（godo.v1.TaskService）として公開するサーバーを起動します。
+
+  List    一覧（完了状態・プロジェクト・優先度・状態・タグ・検索語・期限で絞り込み）
+  Get     取得
+  Create  作成
+  Update  update_mask で指定した項目の変更
+  Delete  削除
+  Watch   タスクの作成・変更・削除を受け取り続ける（after_event_id で途中から再開、0 なら履歴の最初から）
+
+サービスの定義は proto/godo/v1/tasks.proto にあり、Go からは godo/rpc/client パッケージで呼び出せます。
+認証は godo serve と同じく、メタデータの authorization: Bearer <トークン> で行います。
+Update と Delete に etag を指定すると、他のクライアントが先に変更していた場合は FAILED_PRECONDITION を返します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workflow, err := serveWorkflow()
+		if err != nil {
+			return err
+		}
+		token, err := serveToken()
+		if err != nil {
+			return err
+		}
+
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storageMode)
+		ts.SetAutoArchive(autoArchive)
+		// Webhook も同じ Access から読み込み、リクエストの読み書きと重ならないようにする
+		tasks := api.NewAccess(api.NewStorageStore(ts, workflow))
+		startWebhooks(ts, tasks)
+		svc := rpc.NewService(tasks, feed.NewHistory(ts.GetHistoryPath()))
+		server := rpc.NewServer(svc, token)
+
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		// Watch の呼び出しは終わらないので、待たずに止める
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			server.Stop()
+		}()
+		fmt.Printf("%s で gRPC の API を公開しています（Ctrl+C で終了）\n", grpcAddr)
+		if err := server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(grpcCmd)
+	grpcCmd.Flags().StringVar(&grpcAddr, "addr", "127.0.0.1:9090", "待ち受けるアドレス")
+	grpcCmd.Flags().BoolVar(&serveNoAuth, "no-auth", false, "トークンを指定していなくても認証なしで起動する")
+	grpcCmd.Flags().StringVar(&workflowSpec, "columns", "", "タスクの状態として使う列（例: todo,doing:3,review:2,done）")
+}
@@ -0,0 +1,169 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/hooks"
+	"godo/internal/report"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadTasks ストレージからタスクを読み込み、TaskManagerを作成する
+// --server を指定した場合は godo serve のサーバーから読み込む
+// ローカルのファイルから読み込む場合は、保存するまで他のプロセス（godo serve など）が書き込まないようにロックを取る
+// ロックは saveTasks で保存したとき、または releaseTasks で外す
+func loadTasks() (*storage.TaskStorage, *models.TaskManager, error) {
+	ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+	ts.SetMode(storageMode)
+	ts.SetAutoArchive(autoArchive)
+	if remote != nil {
+		tm, err := remote.Load()
+		if err == nil {
+			loadedTasks = hooks.Snapshot(tm.GetTasks())
+		}
+		return ts, tm, err
+	}
+	releaseTasks()
+	unlock, err := ts.Lock()
+	if err != nil {
+		return nil, nil, err
+	}
+	tm, err := ts.Load()
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	unlockTasks = unlock
+	loadedTasks = hooks.Snapshot(tm.GetTasks())
+	return ts, tm, nil
+}
+
+// loadTasks で読み込んだ時点のタスク（フックに渡す変更前の内容）
+var loadedTasks []*models.Task
+
+// loadTasks で取ったロックを外す関数（ロックを取っていなければ nil）
+var unlockTasks func()
+
+// releaseTasks loadTasks で取ったロックを外す
+// 保存しないコマンドのロックはコマンドの終了時に外す
+func releaseTasks() {
+	if unlockTasks != nil {
+		unlockTasks()
+		unlockTasks = nil
+	}
+}
+
+// saveTasks TaskManagerのタスクと変更履歴をストレージに保存する
+// 保存する前にフックを実行し、取り消された変更があれば他の変更を保存した上でエラーを返す
+// ローカルのファイルに保存した変更は Webhook にも送る（サーバーに接続している場合はサーバーが送る）
+func saveTasks(ts *storage.TaskStorage, tm *models.TaskManager) error {
+	return saveTasksThen(ts, tm, nil)
+}
+
+// saveTasksThen saveTasks と同じように保存し、loadTasks で取ったロックを外す前に then を実行する
+// アーカイブなどタスクと一緒に書き換えるファイルがある場合に使う
+func saveTasksThen(ts *storage.TaskStorage, tm *models.TaskManager, then func() error) error {
+	messages, hookErr := hookRunner.Run(tm, loadedTasks)
+	for _, message := range messages {
+		fmt.Println(message)
+	}
+	if remote != nil {
+		if err := remote.Save(tm); err != nil {
+			if errors.Is(err, api.ErrPreconditionFailed) {
+				return fmt.Errorf("%w\nもう一度実行してください", err)
+			}
+			return err
+		}
+		return hookErr
+	}
+	err := ts.Save(tm)
+	if err == nil && then != nil {
+		err = then()
+	}
+	releaseTasks()
+	if err != nil {
+		return err
+	}
+	flushWebhooks(ts)
+	return hookErr
+}
+
+// requireLocal --server でサーバーに接続している場合はエラーを返す
+// アーカイブや変更履歴などサーバーのAPIで扱えないファイルを使うコマンドで呼ぶ
+func requireLocal(feature string) error {
+	if remote != nil {
+		return fmt.Errorf("%sは --server でサーバーに接続している間は使えません", feature)
+	}
+	return nil
+}
+
+// parseTaskID 引数のタスクIDを解釈する
+func parseTaskID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return -1, fmt.Errorf("タスクIDが不正です: %q", arg)
+	}
+	return id, nil
+}
+
+// findTaskIndex 引数のタスクIDを解釈し、TaskManager上のインデックスを返す
+func findTaskIndex(tm *models.TaskManager, arg string) (int, error) {
+	id, err := parseTaskID(arg)
+	if err != nil {
+		return -1, err
+	}
+	index := tm.FindIndexByID(id)
+	if index < 0 {
+		return -1, fmt.Errorf("ID %d のタスクが見つかりません", id)
+	}
+	return index, nil
+}
+
+// parseDate 日付の指定を解釈する
+// "today" / "tomorrow" / "2006-01-02" / "2006-01-02 15:04" に対応する
+func parseDate(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日付の形式が不正です: %q (例: 2025-01-31, \"2025-01-31 15:00\", today, tomorrow)", value)
+}
+
+// formatTaskLine 一覧表示用にタスクを1行で表す
+func formatTaskLine(task *models.Task) string {
+	status := "○"
+	if task.Completed {
+		status = "✓"
+	}
+	line := fmt.Sprintf("#%-4d %s %s", task.ID, status, task.Title)
+	if task.Priority != "" {
+		line = fmt.Sprintf("#%-4d %s (%s) %s", task.ID, status, task.Priority, task.Title)
+	}
+	if task.Status != "" && !task.Completed {
+		line += fmt.Sprintf(" [%s]", task.Status)
+	}
+	if task.Project != "" {
+		line += " +" + task.Project
+	}
+	if task.DueAt != nil {
+		line += fmt.Sprintf("  (期限 %s)", report.FormatDue(*task.DueAt))
+	}
+	return line
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/format/csv"
+	"godo/pkg/models"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat          string
+	importMap             string
+	importDryRun          bool
+	importAllowDuplicates bool
+
+	importMapping map[string]string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "他の形式のファイルからタスクを読み込む",
+	Long: `他のツールの形式で書かれたファイルからタスクを読み込み、新しいIDで追加します。
+ファイルに "-" を指定すると標準入力から読み込みます。
+--format を省略するとファイルの拡張子から形式を判断します（判断できなければ todotxt）。
+
+CSV / TSV は見出し行の列をタスクの項目に対応づけて読み込みます。
+--map title=Summary,due=Deadline,priority=Pri のように 項目=列名 で指定し、
+指定しない項目は項目名と同じ見出しの列を使います。日付・真偽値・優先度は列の値から変換します。
+
+ics・taskwarrior・org 形式で godo が書き出した ID（UID・uuid）が既にあるタスクと同じタスクは、追加せずに内容を更新します。
+既にあるタスクと同じタイトルのタスクは読み込みません（--allow-duplicates で読み込みます）。
+--dry-run を指定すると、保存せずに読み込む内容を表で表示します。
+変換できなかった行や値は行番号付きで報告します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := importFormat
+		if name == "" {
+			name = formatFromPath(args[0])
+		}
+		read, ok := importers[name]
+		if !ok {
+			return fmt.Errorf("未対応の形式です: %q (対応: %s)", name, formatNames(importers))
+		}
+		if importMap != "" {
+			if name != "csv" && name != "tsv" {
+				return fmt.Errorf("--map は csv / tsv 形式でのみ指定できます")
+			}
+			mapping, err := csv.ParseMapping(importMap)
+			if err != nil {
+				return err
+			}
+			importMapping = mapping
+		}
+		in, err := openInput(args[0])
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		result, err := read(in)
+		if err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		tasks, duplicates := result.Tasks, []*models.Task(nil)
+		var matches []match
+		if key, ok := formatIDKeys[name]; ok {
+			tasks, matches = matchByID(tm, tasks, key)
+		}
+		if !importAllowDuplicates {
+			tasks, duplicates = dedupeByTitle(tm, tasks)
+		}
+
+		if importDryRun {
+			writePreview(os.Stdout, tasks)
+		} else {
+			for _, m := range matches {
+				tm.ApplyTask(m.index, m.task)
+			}
+			for _, task := range tasks {
+				tm.ImportTask(task)
+			}
+			if err := saveTasks(ts, tm); err != nil {
+				return err
+			}
+		}
+
+		for _, issue := range result.Issues {
+			fmt.Println("警告:", issue)
+		}
+		for _, task := range duplicates {
+			fmt.Printf("スキップ: 同じタイトルのタスクがあります (%s)\n", task.Title)
+		}
+		if importDryRun {
+			fmt.Printf("%d件のタスクを読み込めます（--dry-run のため保存していません）\n", len(tasks))
+		} else {
+			fmt.Printf("%d件のタスクを読み込みました\n", len(tasks))
+		}
+		if len(matches) > 0 {
+			if importDryRun {
+				fmt.Printf("%d件の既存のタスクを更新できます\n", len(matches))
+			} else {
+				fmt.Printf("%d件の既存のタスクを更新しました\n", len(matches))
+			}
+		}
+		return nil
+	},
+}
+
+// formatFromPath ファイルの拡張子から形式を判断する
+func formatFromPath(path string) string {
+	lower := strings.ToLower(path)
+	for _, f := range []struct{ ext, name string }{
+		{".csv", "csv"}, {".tsv", "tsv"}, {".ics", "ics"}, {".org", "org"},
+		{".md", "markdown"}, {".markdown", "markdown"}, {".json", "taskwarrior"}, {".jsonl", "taskwarrior"},
+	} {
+		if strings.HasSuffix(lower, f.ext) {
+			return f.name
+		}
+	}
+	return "todotxt"
+}
+
+// match 読み込んだタスクと、同じ ID を記録した既存のタスクのインデックス
+type match struct {
+	index int
+	task  *models.Task
+}
+
+// matchByID Meta[key] の ID が既にあるタスクと同じタスクを取り出す
+func matchByID(tm *models.TaskManager, tasks []*models.Task, key string) (rest []*models.Task, matches []match) {
+	indexes := map[string]int{}
+	for i, task := range tm.GetTasks() {
+		if id := task.Meta[key]; id != "" {
+			indexes[id] = i
+		}
+	}
+	for _, task := range tasks {
+		if i, ok := indexes[task.Meta[key]]; ok && task.Meta[key] != "" {
+			matches = append(matches, match{index: i, task: task})
+			continue
+		}
+		rest = append(rest, task)
+	}
+	return rest, matches
+}
+
+// dedupeByTitle 既にあるタスクや先に読み込んだタスクと同じタイトルのタスクを取り除く
+// タイトルは前後の空白と大文字小文字を区別せずに比べる
+func dedupeByTitle(tm *models.TaskManager, tasks []*models.Task) (kept, duplicates []*models.Task) {
+	seen := map[string]bool{}
+	for _, task := range tm.GetTasks() {
+		seen[titleKey(task.Title)] = true
+	}
+	for _, task := range tasks {
+		key := titleKey(task.Title)
+		if seen[key] {
+			duplicates = append(duplicates, task)
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, task)
+	}
+	return kept, duplicates
+}
+
+func titleKey(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
+
+// writePreview 読み込むタスクを表で書き出す
+func writePreview(w io.Writer, tasks []*models.Task) {
+	rows := [][]string{{"", "タイトル", "優先度", "プロジェクト", "期限", "タグ"}}
+	for _, task := range tasks {
+		status := "○"
+		if task.Completed {
+			status = "✓"
+		}
+		due := ""
+		if task.DueAt != nil {
+			due = task.DueAt.Format("2006-01-02")
+		}
+		rows = append(rows, []string{status, strings.Join(strings.Fields(task.Title), " "), task.Priority, task.Project, due, strings.Join(task.Tags, ",")})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell))
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "読み込む形式（省略時は拡張子から判断）")
+	importCmd.Flags().StringVar(&importMap, "map", "", "CSV / TSV の列の対応（例: title=Summary,due=Deadline）")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "保存せずに読み込む内容を表示する")
+	importCmd.Flags().BoolVar(&importAllowDuplicates, "allow-duplicates", false, "既にあるタスクと同じタイトルのタスクも読み込む")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"godo/pkg/extension"
+	"godo/pkg/tasks"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listArchived bool
+	listSearch   string
+	listFields   bool
+	listSort     string
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "タスクの一覧を表示する",
+	Long: `タスクの一覧を表示します。
+
+--archived を指定するとアーカイブしたタスクを表示します。
+--search を指定するとタイトルかプロジェクトに文字列を含むタスクだけを表示します（大文字小文字は区別しません）。
+--sort で並び順（id, priority, due, created, updated, title）を指定できます（省略すると設定の list.sort）。
+--fields を指定すると拡張機能（godo extensions）が計算した項目も表示します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		order := settings.List.Sort
+		if listSort != "" {
+			parsed, err := tasks.ParseSortOrder(listSort)
+			if err != nil {
+				return fmt.Errorf("--sort: %w", err)
+			}
+			order = parsed
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		list := tm.GetTasks()
+		if listArchived {
+			if err := requireLocal("アーカイブの表示"); err != nil {
+				return err
+			}
+			if list, err = ts.LoadArchive(); err != nil {
+				return err
+			}
+		}
+		list = tasks.Select(list, tm.Workflow(), tasks.Filter{Query: listSearch})
+		tasks.Sort(list, order)
+
+		var extensions extension.Set
+		var values map[int]map[string]string
+		if listFields {
+			extensions = loadExtensions()
+			values, err = extensions.Values(context.Background(), list)
+			if err != nil {
+				fmt.Printf("警告: %v\n", err)
+			}
+		}
+
+		for _, task := range list {
+			fmt.Println(formatTaskLine(task))
+			if line := formatFields(extensions.Fields(), values[task.ID]); line != "" {
+				fmt.Println("    " + line)
+			}
+		}
+		if len(list) == 0 {
+			fmt.Println("該当するタスクはありません")
+		}
+		return nil
+	},
+}
+
+// formatFields 拡張機能が計算した項目を "表示名: 値" の形で並べる（値がなければ空）
+func formatFields(fields []extension.Field, values map[string]string) string {
+	var parts []string
+	for _, f := range fields {
+		if value := values[f.Name]; value != "" {
+			parts = append(parts, f.Label()+": "+value)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().BoolVar(&listArchived, "archived", false, "アーカイブしたタスクを表示する")
+	listCmd.Flags().StringVarP(&listSearch, "search", "s", "", "タイトルかプロジェクトで絞り込む")
+	listCmd.Flags().BoolVar(&listFields, "fields", false, "拡張機能が計算した項目も表示する")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "並び順（id, priority, due, created, updated, title）")
+}
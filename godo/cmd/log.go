@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/pkg/models"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log [id]",
+	Short: "タスクの変更履歴を表示する",
+	Long: `タスクの作成・タイトル変更・完了切り替え・優先度変更・削除などの変更履歴を古い順に表示します。
+
+IDを指定するとそのタスクの履歴だけを表示します。削除したタスクの履歴も表示できます。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireLocal("変更履歴の表示"); err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		history, err := ts.LoadHistory()
+		if err != nil {
+			return err
+		}
+		tm.LoadHistory(history)
+
+		events := tm.AllHistory()
+		if len(args) == 1 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("タスクIDが不正です: %q", args[0])
+			}
+			events = tm.History(id)
+		}
+
+		if len(events) == 0 {
+			fmt.Println("履歴はありません")
+			return nil
+		}
+		for _, e := range events {
+			fmt.Println(formatEventLine(e))
+		}
+		return nil
+	},
+}
+
+// formatEventLine 履歴表示用に変更履歴を1行で表す
+func formatEventLine(e models.Event) string {
+	return fmt.Sprintf("%s  #%-4d %s", e.Time.Format("2006-01-02 15:04:05"), e.TaskID, e.Summary())
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+}
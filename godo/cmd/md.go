@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"godo/internal/format/markdown"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var mdCmd = &cobra.Command{
+	Use:   "md",
+	Short: "Markdownのチェックリストと連携する",
+}
+
+var mdSyncCmd = &cobra.Command{
+	Use:   "sync <file>",
+	Short: "Markdownのチェックリストとタスクを双方向に同期する",
+	Long: `Markdownファイルの "- [ ] 項目" / "- [x] 項目" とタスクを双方向に同期します。
+
+各項目の行末に "<!-- godo:ID -->" のコメントでタスクIDを埋め込み、項目とタスクを対応づけます。
+IDのない項目はタスクとして追加し、ファイルにないタスクは見出し（プロジェクト）ごとに追記します。
+両方で変更された場合は、ファイルの更新日時とタスクの更新日時の新しい方を優先します。
+チェックリスト以外の行はそのまま残します。ファイルがなければ新しく作成します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		var data []byte
+		var modTime time.Time
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+			if data, err = os.ReadFile(path); err != nil {
+				return fmt.Errorf("ファイルを読み込めません: %w", err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("ファイルを読み込めません: %w", err)
+		}
+
+		doc, issues, err := markdown.Parse(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		report := markdown.Sync(doc, tm, modTime)
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+		if err := doc.WriteFile(path); err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Println("警告:", issue)
+		}
+		fmt.Printf("タスク: 追加 %d / 更新 %d / 削除 %d\n", report.StoreAdded, report.StoreUpdated, report.StoreDeleted)
+		fmt.Printf("ファイル: 追加 %d / 更新 %d / 削除 %d\n", report.FileAdded, report.FileUpdated, report.FileRemoved)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mdCmd)
+	mdCmd.AddCommand(mdSyncCmd)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/pkg/tasks"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var priorityCmd = &cobra.Command{
+	Use:   "priority <id> [A-Z]",
+	Short: "タスクの優先度を設定する",
+	Long: `タスクの優先度を A〜Z の1文字で設定します（A が最も高い）。
+
+優先度を省略すると設定を解除します。`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		id, err := parseTaskID(args[0])
+		if err != nil {
+			return err
+		}
+
+		priority := ""
+		if len(args) == 2 {
+			priority = strings.ToUpper(args[1])
+		}
+		task, err := tasks.Wrap(tm).SetPriority(id, priority)
+		if err != nil {
+			return err
+		}
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+
+		if priority == "" {
+			fmt.Printf("'%s' の優先度を解除しました\n", task.Title)
+		} else {
+			fmt.Printf("'%s' の優先度を %s に設定しました\n", task.Title, priority)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(priorityCmd)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/pkg/tasks"
+
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project <id> [name]",
+	Short: "タスクのプロジェクトを設定する",
+	Long: `タスクのプロジェクトを設定します。プロジェクトはタイムシートの集計に使われます。
+
+名前を省略するとプロジェクトの設定を解除します。`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		id, err := parseTaskID(args[0])
+		if err != nil {
+			return err
+		}
+
+		project := ""
+		if len(args) == 2 {
+			project = args[1]
+		}
+		task, err := tasks.Wrap(tm).SetProject(id, project)
+		if err != nil {
+			return err
+		}
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+
+		if task.Project == "" {
+			fmt.Printf("'%s' のプロジェクトを解除しました\n", task.Title)
+		} else {
+			fmt.Printf("'%s' のプロジェクトを %s に設定しました\n", task.Title, task.Project)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+}
@@ -5,8 +5,14 @@ package cmd
 
 import (
 	"fmt"
+	"godo/internal/api"
+	"godo/internal/config"
+	"godo/internal/hooks"
+	"godo/internal/storage"
 	"godo/internal/ui"
+	"godo/pkg/models"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -23,19 +29,162 @@ bubbleteaを使ったターミナルUIで、直感的にタスク管理ができ
   n         - 新しいタスクを追加
   e         - 選択したタスクを編集
   d         - 選択したタスクを削除
+  s         - 選択したタスクのタイマーを開始/停止
+  p         - 選択したタスクでポモドーロを開始/停止
+  b         - リスト表示とボード表示を切り替え
+  H/L       - ボード表示で選択したタスクを前後の列に移動
+  a         - アジェンダ表示（期限ごとの一覧と月間カレンダー）を切り替え
+  A         - 完了済みのタスクをアーカイブ
+  i         - 選択したタスクの変更履歴を表示
   ↑/↓ or j/k - タスクの選択を移動
-  q         - アプリケーションを終了`,
+  q         - アプリケーションを終了
+
+キーの割り当て・日時の表示形式・配色などは設定ファイルで変更できます（godo config を参照）。`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configPath == "" {
+			configPath = config.DefaultPath()
+		}
+		loaded, err := config.Load(configPath)
+		settings = loaded
+		// 設定に誤りがあっても godo config で直せるようにする
+		if err != nil && cmd.Parent() != configCmd {
+			return fmt.Errorf("設定に誤りがあります（godo config edit で修正できます）\n%w", err)
+		}
+		appOptions.DataDir = settings.Storage.Dir
+		appOptions.DateFormat = settings.Display.DateFormat
+		appOptions.Theme, _ = ui.LookupTheme(settings.Display.Theme)
+		appOptions.Keys = settings.Keys
+
+		autoArchive = settings.Archive.Auto
+		if autoArchiveSpec != "" {
+			d, err := config.ParseAge(autoArchiveSpec)
+			if err != nil {
+				return fmt.Errorf("--auto-archive: %w", err)
+			}
+			autoArchive = d
+		}
+		appOptions.AutoArchive = autoArchive
+
+		if storageModeName == "" {
+			storageModeName = os.Getenv("GODO_STORAGE")
+		}
+		if storageModeName == "" {
+			storageModeName = string(settings.Storage.Mode)
+		}
+		mode, err := storage.ParseMode(storageModeName)
+		if err != nil {
+			return fmt.Errorf("--storage: %w", err)
+		}
+		storageMode = mode
+		appOptions.StorageMode = mode
+
+		if serverURL == "" {
+			serverURL = os.Getenv("GODO_SERVER")
+		}
+		if apiToken == "" {
+			apiToken = os.Getenv("GODO_API_TOKEN")
+		}
+		if !noHooks && settings.Hooks.Enabled {
+			hookRunner = hooks.NewRunner(settings.HooksDir())
+			hookRunner.Timeout = settings.Hooks.Timeout
+			appOptions.Hooks = hookRunner
+		}
+
+		if serverURL != "" && cmd.Name() != "serve" && cmd.Name() != "feed" && cmd.Name() != "web" && cmd.Name() != "grpc" {
+			remote = api.NewRemote(serverURL, apiToken, models.DefaultWorkflow())
+		}
+		return nil
+	},
+	// サブコマンドのエラーはExecuteで表示する
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	Run: func(cmd *cobra.Command, args []string) {
+		if workflowSpec != "" {
+			workflow, err := models.ParseWorkflow(workflowSpec)
+			if err != nil {
+				fmt.Printf("設定エラー: %v\n", err)
+				os.Exit(1)
+			}
+			appOptions.Workflow = workflow
+		}
+		if remote != nil {
+			remote.Workflow = appOptions.Workflow
+			appOptions.Remote = remote
+		} else {
+			ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+			ts.SetMode(storageMode)
+			appOptions.Webhooks = newWebhookDispatcher(ts)
+		}
+		appOptions.Extensions = loadExtensions()
+		if err := appOptions.Pomodoro.Validate(); err != nil {
+			fmt.Printf("設定エラー: %v\n", err)
+			os.Exit(1)
+		}
+
 		// TUIアプリケーションを開始
-		if err := ui.RunApp(); err != nil {
+		if err := ui.RunApp(appOptions); err != nil {
 			fmt.Printf("アプリケーション実行エラー: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
 
+// TUIアプリケーションの設定
+var appOptions = ui.DefaultOptions()
+
+// 設定ファイルのパス（未指定の場合は環境変数 GODO_CONFIG か ~/.config/godo/config.toml）
+var configPath string
+
+// 設定ファイルと環境変数から読み込んだ設定
+var settings = config.Default()
+
+// 自動アーカイブの期間（"30d" など、0で無効）
+var (
+	autoArchiveSpec string
+	autoArchive     time.Duration
+)
+
+// タスクの保存形式（未指定の場合は環境変数 GODO_STORAGE を使う）
+var (
+	storageModeName string
+	storageMode     storage.Mode
+)
+
+// godo serve のサーバーに接続する場合のURLとトークン
+// （未指定の場合は環境変数 GODO_SERVER / GODO_API_TOKEN を使う）
+var (
+	serverURL string
+	apiToken  string
+	remote    *api.Remote // サーバーに接続する場合のみ設定される
+)
+
+// タスクの変更時に実行するフック（--no-hooks の場合は nil）
+var (
+	noHooks    bool
+	hookRunner *hooks.Runner
+)
+
+// ボード表示の列の指定（例: "todo,doing:3,review:2,done"）
+var workflowSpec string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "設定ファイルのパス（環境変数 GODO_CONFIG でも指定可）")
+	rootCmd.PersistentFlags().StringVar(&autoArchiveSpec, "auto-archive", "", "完了してから指定期間が経過したタスクを保存時にアーカイブする（例: 30d）")
+	rootCmd.PersistentFlags().StringVar(&storageModeName, "storage", "", "タスクの保存形式: snapshot または journal（環境変数 GODO_STORAGE でも指定可）")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "godo serve のサーバーに接続してタスクを読み書きする（例: http://127.0.0.1:8080、環境変数 GODO_SERVER でも指定可）")
+	rootCmd.PersistentFlags().StringVar(&apiToken, "token", "", "APIの認証トークン（環境変数 GODO_API_TOKEN でも指定可）")
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "フック（~/.godo/hooks）を実行しない")
+	rootCmd.Flags().DurationVar(&appOptions.Pomodoro.Work, "pomodoro-work", appOptions.Pomodoro.Work, "ポモドーロの作業時間")
+	rootCmd.Flags().DurationVar(&appOptions.Pomodoro.ShortBreak, "pomodoro-break", appOptions.Pomodoro.ShortBreak, "ポモドーロの休憩時間")
+	rootCmd.Flags().DurationVar(&appOptions.Pomodoro.LongBreak, "pomodoro-long-break", appOptions.Pomodoro.LongBreak, "ポモドーロの長い休憩時間")
+	rootCmd.Flags().StringVar(&workflowSpec, "columns", "", "ボード表示の列とWIP制限（例: todo,doing:3,review:2,done）")
+	rootCmd.Flags().IntVar(&appOptions.Pomodoro.LongBreakEvery, "pomodoro-long-every", appOptions.Pomodoro.LongBreakEvery, "長い休憩を取る作業回数の間隔（0で無効）")
+}
+
 func Execute() {
+	registerExtension(os.Args[1:])
 	err := rootCmd.Execute()
+	releaseTasks()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
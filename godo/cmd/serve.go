@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr   string
+	serveNoAuth bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "タスクを JSON の REST API として公開する",
+	Long: `タスクを JSON の REST API として公開するサーバーを起動します。
+
+  GET    /api/tasks              一覧（completed, project, priority, status, tag, q, due_before で絞り込み）
+  POST   /api/tasks              作成
+  GET    /api/tasks/{id}         取得
+  PATCH  /api/tasks/{id}         変更
+  DELETE /api/tasks/{id}         削除
+  POST   /api/tasks/{id}/toggle  完了/未完了の切り替え
+
+リクエストには Authorization: Bearer <トークン> が必要です。トークンは --token か
+環境変数 GODO_API_TOKEN で指定し、指定しなければ起動時に生成して表示します。
+変更系のリクエストに If-Match で ETag を指定すると、他のクライアントが先に変更していた場合は 412 を返します。
+GET /api/events ではタスクの変更を Server-Sent Events で配信します（godo feed を参照）。
+
+他の端末からは godo --server http://127.0.0.1:8080 で CLI や TUI をこのサーバーのクライアントとして使えます。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workflow, err := serveWorkflow()
+		if err != nil {
+			return err
+		}
+		token, err := serveToken()
+		if err != nil {
+			return err
+		}
+
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storageMode)
+		ts.SetAutoArchive(autoArchive)
+		// フィードと Webhook も同じ Access から読み込み、リクエストの読み書きと重ならないようにする
+		tasks := api.NewAccess(api.NewStorageStore(ts, workflow))
+		startWebhooks(ts, tasks)
+		server := api.NewServer(tasks, token)
+		mux := http.NewServeMux()
+		mux.Handle("/api/tasks", server)
+		mux.Handle("/api/tasks/", server)
+		mux.Handle("GET /api/events", newFeedHandler(ts, tasks, token))
+
+		fmt.Printf("http://%s/api/tasks で待ち受けています（Ctrl+C で終了）\n", serveAddr)
+		return listenAndServe(serveAddr, mux)
+	},
+}
+
+// serveWorkflow --columns で指定したワークフロー（未指定ならデフォルト）を返す
+func serveWorkflow() (models.Workflow, error) {
+	if workflowSpec == "" {
+		return models.DefaultWorkflow(), nil
+	}
+	workflow, err := models.ParseWorkflow(workflowSpec)
+	if err != nil {
+		return models.Workflow{}, fmt.Errorf("--columns: %w", err)
+	}
+	return workflow, nil
+}
+
+// serveToken APIの認証トークンを返す
+// --token も GODO_API_TOKEN も指定していなければ生成して表示する（--no-auth の場合は空）
+func serveToken() (string, error) {
+	if apiToken != "" || serveNoAuth {
+		return apiToken, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	fmt.Printf("認証トークン: %s\n", token)
+	fmt.Println("（GODO_API_TOKEN に設定すると --server で接続するクライアントから使えます）")
+	return token, nil
+}
+
+// listenAndServe Ctrl+C で終了するまでHTTPサーバーを動かす
+func listenAndServe(addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "待ち受けるアドレス")
+	serveCmd.Flags().BoolVar(&serveNoAuth, "no-auth", false, "トークンを指定していなくても認証なしで起動する")
+	serveCmd.Flags().StringVar(&workflowSpec, "columns", "", "タスクの状態として使う列（例: todo,doing:3,review:2,done）")
+}
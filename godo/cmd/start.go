@@ -0,0 +1,52 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var startNote string
+
+var startCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "タスクの作業時間の計測を開始する",
+	Long: `指定したタスクのタイマーを開始します。
+
+同時に計測できるタイマーは1つだけです。
+他のタスクで計測中のタイマーがある場合は自動的に停止します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		index, err := findTaskIndex(tm, args[0])
+		if err != nil {
+			return err
+		}
+
+		task := tm.GetTaskByIndex(index)
+		if task.RunningEntry() != nil {
+			return fmt.Errorf("'%s' は既に計測中です", task.Title)
+		}
+		if prev := tm.RunningTimerIndex(); prev >= 0 {
+			fmt.Printf("'%s' のタイマーを停止しました\n", tm.GetTaskByIndex(prev).Title)
+		}
+		tm.StartTimer(index, startNote)
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+
+		fmt.Printf("'%s' のタイマーを開始しました\n", task.Title)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().StringVar(&startNote, "note", "", "作業内容のメモ")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"godo/internal/report"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsDays     int
+	statsWeeks    int
+	statsJSON     bool
+	statsArchived bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "タスクの完了状況の統計を表示する",
+	Long: `日別・週別の完了数、作成から完了までの平均リードタイム、連続記録、プロジェクト別の集計を表示します。
+
+--archived を指定するとアーカイブしたタスクも集計に含めます。--json を指定するとJSONで出力します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		tasks := tm.GetTasks()
+		// サーバーに接続している場合はサーバーのタスクだけを集計する
+		if statsArchived && remote == nil {
+			archive, err := ts.LoadArchive()
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, archive...)
+		}
+
+		stats := report.BuildStats(tasks, time.Now(), statsDays, statsWeeks)
+		if statsJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stats)
+		}
+		report.WriteStats(os.Stdout, stats)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().IntVar(&statsDays, "days", 14, "日別の完了数を集計する日数")
+	statsCmd.Flags().IntVar(&statsWeeks, "weeks", 8, "週別の完了数を集計する週数")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "JSONで出力する")
+	statsCmd.Flags().BoolVar(&statsArchived, "archived", false, "アーカイブしたタスクも集計に含める")
+}
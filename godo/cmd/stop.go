@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/report"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "計測中のタイマーを停止する",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		task := tm.StopTimer()
+		if task == nil {
+			fmt.Println("計測中のタイマーはありません")
+			return nil
+		}
+		if err := saveTasks(ts, tm); err != nil {
+			return err
+		}
+
+		last := task.TimeEntries[len(task.TimeEntries)-1]
+		fmt.Printf("'%s' のタイマーを停止しました (%s, 合計 %s)\n",
+			task.Title,
+			report.FormatDuration(last.Duration(time.Now())),
+			report.FormatDuration(task.TrackedTime(time.Now())))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
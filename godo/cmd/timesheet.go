@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"godo/internal/report"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var timesheetWeek bool
+
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "記録した作業時間を日別・プロジェクト別に集計する",
+	Long: `記録した作業時間を日別・プロジェクト別に集計して表示します。
+
+デフォルトでは今日の作業時間を、--week を指定すると今週（月曜始まり）の作業時間を集計します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, tm, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		from, to := report.DayRange(now)
+		if timesheetWeek {
+			from, to = report.WeekRange(now)
+		}
+		report.WriteTimesheet(os.Stdout, report.BuildTimesheet(tm.GetTasks(), from, to, now))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timesheetCmd)
+	timesheetCmd.Flags().BoolVar(&timesheetWeek, "week", false, "今週の作業時間を集計する")
+}
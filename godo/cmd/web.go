@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/storage"
+	"godo/internal/web"
+	"net"
+
+	"github.com/spf13/cobra"
+)
+
+var webAddr string
+
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "ブラウザでタスクを操作するWeb画面を起動する",
+	Long: `ブラウザでタスクの一覧・追加・編集・完了の切り替え・削除・絞り込みができるWeb画面を起動します。
+
+TUI と同じ ~/.godo/tasks.json を読み書きし、CLI や他の画面での変更は自動で反映されます。
+認証がないため、localhost（127.0.0.1 など）のアドレスでだけ待ち受け、
+Host が localhost などのループバックのアドレスと待ち受けるポートでないリクエストは拒否します。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isLoopback(webAddr) {
+			return fmt.Errorf("Web画面には認証がないため、localhost 以外のアドレスでは起動できません: %q", webAddr)
+		}
+		workflow, err := serveWorkflow()
+		if err != nil {
+			return err
+		}
+
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storageMode)
+		ts.SetAutoArchive(autoArchive)
+		// 変更の配信と Webhook も同じ Access から読み込み、画面からの読み書きと重ならないようにする
+		tasks := api.NewAccess(api.NewStorageStore(ts, workflow))
+		startWebhooks(ts, tasks)
+		events := newFeedHandler(ts, tasks, "")
+		server, err := web.NewServer(tasks, events, webAddr)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("http://%s/ をブラウザで開いてください（Ctrl+C で終了）\n", webAddr)
+		return listenAndServe(webAddr, server)
+	},
+}
+
+// isLoopback 待ち受けるアドレスがこのコンピューターからしか接続できないかを返す
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	rootCmd.AddCommand(webCmd)
+	webCmd.Flags().StringVar(&webAddr, "addr", "127.0.0.1:8090", "待ち受けるアドレス")
+	webCmd.Flags().StringVar(&workflowSpec, "columns", "", "タスクの状態として使う列（例: todo,doing:3,review:2,done）")
+}
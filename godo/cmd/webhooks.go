@@ -0,0 +1,257 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/internal/storage"
+	"godo/internal/webhook"
+	"godo/pkg/models"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookSecret string
+	webhookEvents []string
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "タスクの変更を送る Webhook を設定する",
+	Long: `タスクの作成・変更・完了・削除を、設定した URL に JSON で POST します。
+
+本文には Webhook ごとの秘密鍵による HMAC-SHA256 の署名を X-Godo-Signature ヘッダー（sha256=<16進数>）で付け、
+X-Godo-Event に変更の種類（task.created / task.updated / task.completed / task.deleted）、
+X-Godo-Delivery に変更のID（送り直しても同じ値）を付けます。
+
+変更は CLI や TUI で保存したときと、godo serve / web / grpc の実行中に送ります。
+送れなかった変更は ~/.godo/webhooks-queue.json に残し、間隔を倍にしながら（最大1時間）送り直します。
+設定は ~/.godo/webhooks.json に保存します。`,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "設定した Webhook と送信待ちの件数を表示する",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := newWebhookDispatcher(storage.NewTaskStorageAt(settings.Storage.Dir))
+		config, err := webhook.LoadConfig(d.ConfigPath)
+		if err != nil {
+			return err
+		}
+		if len(config.Webhooks) == 0 {
+			fmt.Println("Webhook は設定されていません")
+			return nil
+		}
+		q, err := webhook.LoadQueue(d.QueuePath)
+		if err != nil {
+			return err
+		}
+		pending := q.Pending()
+		for _, w := range config.Webhooks {
+			events := "すべて"
+			if len(w.Events) > 0 {
+				events = strings.Join(w.Events, ",")
+			}
+			fmt.Printf("%s  変更: %s  送信待ち: %d件\n", w.URL, events, pending[w.URL])
+		}
+		return nil
+	},
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Webhook を追加する",
+	Long: `変更を送る URL を追加します。追加した後の変更から送ります。
+
+--secret を指定しなければ秘密鍵を生成して表示します。受信側ではこの秘密鍵で本文の署名を確認してください。
+--events で送る変更の種類を絞り込めます（例: --events task.completed,task.deleted）。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		u, err := url.Parse(args[0])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("URL が不正です: %q", args[0])
+		}
+		if err := webhook.ValidateEvents(webhookEvents); err != nil {
+			return fmt.Errorf("--events: %w", err)
+		}
+		d := newWebhookDispatcher(storage.NewTaskStorageAt(settings.Storage.Dir))
+		config, err := webhook.LoadConfig(d.ConfigPath)
+		if err != nil {
+			return err
+		}
+		if config.Find(args[0]) != nil {
+			return fmt.Errorf("%s はすでに設定されています", args[0])
+		}
+
+		secret := webhookSecret
+		if secret == "" {
+			b := make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			secret = hex.EncodeToString(b)
+			fmt.Printf("秘密鍵: %s\n", secret)
+		}
+		first := len(config.Webhooks) == 0
+		config.Webhooks = append(config.Webhooks, &webhook.Webhook{URL: args[0], Secret: secret, Events: webhookEvents})
+		if err := config.Save(d.ConfigPath); err != nil {
+			return err
+		}
+		// 他に Webhook がなかった間の変更は送らない
+		if first {
+			if err := d.Reset(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s を追加しました\n", args[0])
+		return nil
+	},
+}
+
+var webhooksRemoveCmd = &cobra.Command{
+	Use:   "remove <url>",
+	Short: "Webhook を削除する（送信待ちの変更も捨てる）",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := newWebhookDispatcher(storage.NewTaskStorageAt(settings.Storage.Dir))
+		config, err := webhook.LoadConfig(d.ConfigPath)
+		if err != nil {
+			return err
+		}
+		w := config.Find(args[0])
+		if w == nil {
+			return fmt.Errorf("%s は設定されていません", args[0])
+		}
+		var kept []*webhook.Webhook
+		for _, other := range config.Webhooks {
+			if other != w {
+				kept = append(kept, other)
+			}
+		}
+		config.Webhooks = kept
+		if err := config.Save(d.ConfigPath); err != nil {
+			return err
+		}
+		fmt.Printf("%s を削除しました\n", args[0])
+		return nil
+	},
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test [url]",
+	Short: "Webhook に確認用の ping を送る",
+	Long: `設定した Webhook（URL を指定した場合はその Webhook）に event が ping の本文を署名付きで送り、結果を表示します。
+送信待ちのキューには積まず、失敗しても送り直しません。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := newWebhookDispatcher(storage.NewTaskStorageAt(settings.Storage.Dir))
+		config, err := webhook.LoadConfig(d.ConfigPath)
+		if err != nil {
+			return err
+		}
+		targets := config.Webhooks
+		if len(args) == 1 {
+			w := config.Find(args[0])
+			if w == nil {
+				return fmt.Errorf("%s は設定されていません", args[0])
+			}
+			targets = []*webhook.Webhook{w}
+		}
+		if len(targets) == 0 {
+			return errors.New("Webhook が設定されていません。godo webhooks add <url> で追加してください")
+		}
+
+		id, body, err := webhook.Ping(time.Now())
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, w := range targets {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			err := webhook.Send(ctx, d.HTTP, w, id, webhook.EventPing, body)
+			cancel()
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", w.URL, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s\n", w.URL)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d件の Webhook に送れませんでした", failed)
+		}
+		return nil
+	},
+}
+
+var webhooksFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "送信待ちの変更をすぐに送る",
+	Long:  `送り直しの間隔を待たずに、送信待ちの変更をすべて送ります。`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts := storage.NewTaskStorageAt(settings.Storage.Dir)
+		ts.SetMode(storageMode)
+		d := newWebhookDispatcher(ts)
+		d.Force = true
+		if err := d.Flush(context.Background()); err != nil {
+			return fmt.Errorf("送れなかった変更は送信待ちに残しました: %w", err)
+		}
+		q, err := webhook.LoadQueue(d.QueuePath)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		if q != nil {
+			pending = len(q.Deliveries)
+		}
+		fmt.Printf("送信待ち: %d件\n", pending)
+		return nil
+	},
+}
+
+// newWebhookDispatcher タスクと同じディレクトリの設定とキューを使って変更を送る Dispatcher を作成する
+func newWebhookDispatcher(ts *storage.TaskStorage) *webhook.Dispatcher {
+	current := func() ([]*models.Task, error) {
+		return ts.LoadTasks()
+	}
+	return webhook.NewDispatcher(filepath.Dir(ts.GetFilePath()), feed.NewHistory(ts.GetHistoryPath()), current)
+}
+
+// flushWebhooks 保存した変更を Webhook に送る
+// 送れなかった変更は次に保存したときなどに送り直すので、警告だけを表示する
+func flushWebhooks(ts *storage.TaskStorage) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := newWebhookDispatcher(ts).Flush(ctx); err != nil {
+		fmt.Printf("警告: Webhook に送れませんでした（後で送り直します）: %v\n", err)
+	}
+}
+
+// startWebhooks サーバーの実行中に、定期的に変更を Webhook に送る
+// 変更されたタスクの現在の内容はサーバーと同じ tasks から読み込み、リクエストの読み書きと重ならないようにする
+func startWebhooks(ts *storage.TaskStorage, tasks *api.Access) {
+	d := newWebhookDispatcher(ts)
+	d.Current = tasks.Tasks
+	go d.Run(context.Background(), 10*time.Second)
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksListCmd, webhooksAddCmd, webhooksRemoveCmd, webhooksTestCmd, webhooksFlushCmd)
+	webhooksAddCmd.Flags().StringVar(&webhookSecret, "secret", "", "署名に使う秘密鍵（省略すると生成する）")
+	webhooksAddCmd.Flags().StringSliceVar(&webhookEvents, "events", nil, "送る変更の種類（task.created, task.updated, task.completed, task.deleted）")
+}
@@ -0,0 +1,293 @@
+// Package api はタスクを JSON の REST API として公開するサーバーと、そのクライアントを提供する
+//
+// エンドポイント:
+//
+//	GET    /api/tasks              タスクの一覧（completed, project, priority, status, tag, q, due_before で絞り込み）
+//	POST   /api/tasks              タスクの作成
+//	GET    /api/tasks/{id}         タスクの取得
+//	PATCH  /api/tasks/{id}         タスクの一部の項目の変更
+//	DELETE /api/tasks/{id}         タスクの削除
+//	POST   /api/tasks/{id}/toggle  完了/未完了の切り替え
+//
+// 1件のタスクを返す応答には ETag を付け、変更系のリクエストに If-Match を指定すると
+// 他のクライアントが先に変更していた場合は 412 Precondition Failed を返す。
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"godo/pkg/models"
+	"godo/pkg/tasks"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ETag タスクの内容から決まる ETag を返す
+// クライアントは一覧で受け取ったタスクからも同じ値を計算できる
+func ETag(task *models.Task) string {
+	data, _ := json.Marshal(task)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Patch タスクの変更内容。nil の項目は変更しない
+// JSON では due_at に null を指定すると期限を解除する
+type Patch struct {
+	Title       *string
+	Completed   *bool
+	Status      *string
+	Priority    *string
+	Project     *string
+	Tags        *[]string
+	Contexts    *[]string
+	DueAt       *time.Time
+	ClearDue    bool
+	TimeEntries *[]models.TimeEntry
+	Annotations *[]models.Annotation
+	Pomodoros   *int
+	Meta        *map[string]string // 指定した場合はメタ情報全体を置き換える
+}
+
+// IsEmpty 変更する項目がないかを返す
+func (p Patch) IsEmpty() bool {
+	return p.Title == nil && p.Completed == nil && p.Status == nil && p.Priority == nil &&
+		p.Project == nil && p.Tags == nil && p.Contexts == nil && p.DueAt == nil && !p.ClearDue &&
+		p.TimeEntries == nil && p.Annotations == nil && p.Pomodoros == nil && p.Meta == nil
+}
+
+// MarshalJSON 変更する項目だけを JSON にする
+func (p Patch) MarshalJSON() ([]byte, error) {
+	m := map[string]any{}
+	set := func(key string, ok bool, v any) {
+		if ok {
+			m[key] = v
+		}
+	}
+	set("title", p.Title != nil, p.Title)
+	set("completed", p.Completed != nil, p.Completed)
+	set("status", p.Status != nil, p.Status)
+	set("priority", p.Priority != nil, p.Priority)
+	set("project", p.Project != nil, p.Project)
+	set("tags", p.Tags != nil, p.Tags)
+	set("contexts", p.Contexts != nil, p.Contexts)
+	set("due_at", p.DueAt != nil, p.DueAt)
+	set("due_at", p.ClearDue, nil)
+	set("time_entries", p.TimeEntries != nil, p.TimeEntries)
+	set("annotations", p.Annotations != nil, p.Annotations)
+	set("pomodoros", p.Pomodoros != nil, p.Pomodoros)
+	set("meta", p.Meta != nil, p.Meta)
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON JSON の変更内容を解釈する。知らない項目はエラーにする
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*p = Patch{}
+	for key, raw := range m {
+		var err error
+		switch key {
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "completed":
+			err = json.Unmarshal(raw, &p.Completed)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "priority":
+			err = json.Unmarshal(raw, &p.Priority)
+		case "project":
+			err = json.Unmarshal(raw, &p.Project)
+		case "tags":
+			err = json.Unmarshal(raw, &p.Tags)
+		case "contexts":
+			err = json.Unmarshal(raw, &p.Contexts)
+		case "due_at":
+			if string(raw) == "null" {
+				p.ClearDue = true
+			} else {
+				err = json.Unmarshal(raw, &p.DueAt)
+			}
+		case "time_entries":
+			err = json.Unmarshal(raw, &p.TimeEntries)
+		case "annotations":
+			err = json.Unmarshal(raw, &p.Annotations)
+		case "pomodoros":
+			err = json.Unmarshal(raw, &p.Pomodoros)
+		case "meta":
+			err = json.Unmarshal(raw, &p.Meta)
+		default:
+			return fmt.Errorf("変更できない項目です: %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Diff old から task への変更を Patch にする
+func Diff(old, task *models.Task) Patch {
+	var p Patch
+	if old.Title != task.Title {
+		p.Title = &task.Title
+	}
+	if old.Completed != task.Completed {
+		p.Completed = &task.Completed
+	}
+	if old.Status != task.Status {
+		p.Status = &task.Status
+	}
+	if old.Priority != task.Priority {
+		p.Priority = &task.Priority
+	}
+	if old.Project != task.Project {
+		p.Project = &task.Project
+	}
+	if !slices.Equal(old.Tags, task.Tags) {
+		p.Tags = &task.Tags
+	}
+	if !slices.Equal(old.Contexts, task.Contexts) {
+		p.Contexts = &task.Contexts
+	}
+	switch {
+	case task.DueAt == nil && old.DueAt != nil:
+		p.ClearDue = true
+	case task.DueAt != nil && (old.DueAt == nil || !old.DueAt.Equal(*task.DueAt)):
+		p.DueAt = task.DueAt
+	}
+	if !slices.EqualFunc(old.TimeEntries, task.TimeEntries, sameEntry) {
+		p.TimeEntries = &task.TimeEntries
+	}
+	if !slices.EqualFunc(old.Annotations, task.Annotations, sameAnnotation) {
+		p.Annotations = &task.Annotations
+	}
+	if old.Pomodoros != task.Pomodoros {
+		p.Pomodoros = &task.Pomodoros
+	}
+	if !maps.Equal(old.Meta, task.Meta) {
+		meta := task.Meta
+		if meta == nil {
+			meta = map[string]string{}
+		}
+		p.Meta = &meta
+	}
+	return p
+}
+
+// Unsent Diff で送れない変更があれば、その項目名を返す
+// Patch で送った結果がローカルのタスクと一致するかを、更新日時など送らずに決まる項目を除いて比べる
+func Unsent(old, task *models.Task) []string {
+	sent := old.Clone()
+	p := Diff(old, task)
+	apply := func(ok bool, set func()) {
+		if ok {
+			set()
+		}
+	}
+	apply(p.Title != nil, func() { sent.Title = *p.Title })
+	apply(p.Completed != nil, func() { sent.Completed = *p.Completed })
+	apply(p.Status != nil, func() { sent.Status = *p.Status })
+	apply(p.Priority != nil, func() { sent.Priority = *p.Priority })
+	apply(p.Project != nil, func() { sent.Project = *p.Project })
+	apply(p.Tags != nil, func() { sent.Tags = *p.Tags })
+	apply(p.Contexts != nil, func() { sent.Contexts = *p.Contexts })
+	apply(p.DueAt != nil || p.ClearDue, func() { sent.DueAt = p.DueAt })
+	apply(p.TimeEntries != nil, func() { sent.TimeEntries = *p.TimeEntries })
+	apply(p.Annotations != nil, func() { sent.Annotations = *p.Annotations })
+	apply(p.Pomodoros != nil, func() { sent.Pomodoros = *p.Pomodoros })
+	apply(p.Meta != nil, func() { sent.Meta = *p.Meta })
+	// サーバーが設定する項目
+	sent.UpdatedAt, sent.CompletedAt = task.UpdatedAt, task.CompletedAt
+
+	var a, b map[string]json.RawMessage
+	sentJSON, _ := json.Marshal(sent)
+	taskJSON, _ := json.Marshal(task)
+	json.Unmarshal(sentJSON, &a)
+	json.Unmarshal(taskJSON, &b)
+	var fields []string
+	for key := range b {
+		if !bytes.Equal(a[key], b[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func sameAnnotation(a, b models.Annotation) bool {
+	return a.Text == b.Text && a.Time.Equal(b.Time)
+}
+
+func sameEntry(a, b models.TimeEntry) bool {
+	if !a.Start.Equal(b.Start) || a.Note != b.Note || (a.End == nil) != (b.End == nil) {
+		return false
+	}
+	return a.End == nil || a.End.Equal(*b.End)
+}
+
+// Filter タスクの一覧の絞り込み条件
+type Filter = tasks.Filter
+
+// FilterValues 絞り込み条件をクエリパラメータにする
+func FilterValues(f Filter) url.Values {
+	v := url.Values{}
+	if f.Completed != nil {
+		v.Set("completed", strconv.FormatBool(*f.Completed))
+	}
+	for key, value := range map[string]string{"project": f.Project, "priority": f.Priority, "status": f.Status, "tag": f.Tag, "q": f.Query} {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	if !f.DueBefore.IsZero() {
+		v.Set("due_before", f.DueBefore.Format(time.RFC3339))
+	}
+	return v
+}
+
+// ParseFilter クエリパラメータを絞り込み条件として解釈する
+func ParseFilter(v url.Values) (Filter, error) {
+	f := Filter{
+		Project:  v.Get("project"),
+		Priority: v.Get("priority"),
+		Status:   v.Get("status"),
+		Tag:      v.Get("tag"),
+		Query:    v.Get("q"),
+	}
+	if s := v.Get("completed"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return f, fmt.Errorf("completed は true か false で指定してください: %q", s)
+		}
+		f.Completed = &b
+	}
+	if s := v.Get("due_before"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t, err = time.ParseInLocation("2006-01-02", s, time.Local)
+		}
+		if err != nil {
+			return f, fmt.Errorf("due_before は 2006-01-02 か RFC3339 の形式で指定してください: %q", s)
+		}
+		f.DueBefore = t
+	}
+	return f, nil
+}
+
+// errorResponse エラー時の応答
+type errorResponse struct {
+	Error string `json:"error"`
+}
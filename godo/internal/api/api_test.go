@@ -0,0 +1,324 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"godo/internal/api/apitest"
+	"godo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "secret-token"
+
+func newTestServer(t *testing.T, titles ...string) (*apitest.Store, *Client) {
+	t.Helper()
+	store := apitest.NewStore(titles...)
+	srv := httptest.NewServer(NewServer(NewAccess(store), testToken))
+	t.Cleanup(srv.Close)
+	return store, NewClient(srv.URL, testToken)
+}
+
+func TestServerCRUD(t *testing.T) {
+	store, c := newTestServer(t, "既存のタスク")
+	ctx := context.Background()
+
+	created, etag, err := c.Create(ctx, &models.Task{Title: "新しいタスク", Priority: "A", Tags: []string{"work"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 2 || created.Priority != "A" || etag != ETag(created) {
+		t.Fatalf("Create = %+v, %q", created, etag)
+	}
+
+	got, etag, err := c.Get(ctx, 2)
+	if err != nil || got.Title != "新しいタスク" || etag != ETag(got) {
+		t.Fatalf("Get = %+v, %q, %v", got, etag, err)
+	}
+
+	title, project := "変更後", "home"
+	due := time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)
+	patched, etag, err := c.Patch(ctx, 2, Patch{Title: &title, Project: &project, DueAt: &due}, etag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Title != title || patched.Project != project || patched.DueAt == nil || !patched.DueAt.Equal(due) {
+		t.Fatalf("Patch = %+v", patched)
+	}
+	patched, etag, err = c.Patch(ctx, 2, Patch{ClearDue: true}, etag)
+	if err != nil || patched.DueAt != nil {
+		t.Fatalf("Patch(ClearDue) = %+v, %v", patched, err)
+	}
+
+	toggled, etag, err := c.Toggle(ctx, 2, etag)
+	if err != nil || !toggled.Completed || toggled.CompletedAt == nil {
+		t.Fatalf("Toggle = %+v, %v", toggled, err)
+	}
+
+	if err := c.Delete(ctx, 2, etag); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Get(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// サーバーでの変更は変更履歴にも残る
+	var types []models.EventType
+	for _, e := range store.Events()[1:] {
+		types = append(types, e.Type)
+	}
+	want := []models.EventType{models.EventCreated, models.EventRenamed, models.EventUpdated, models.EventUpdated, models.EventUpdated, models.EventToggled, models.EventDeleted}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("events = %v, want %v", types, want)
+		}
+	}
+}
+
+func TestServerIfMatch(t *testing.T) {
+	_, c := newTestServer(t, "a")
+	ctx := context.Background()
+
+	_, etag, err := c.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 他のクライアントが先に変更する
+	if _, _, err := c.Toggle(ctx, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	title := "古い内容への変更"
+	if _, _, err := c.Patch(ctx, 1, Patch{Title: &title}, etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := c.Delete(ctx, 1, etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	got, _, err := c.Get(ctx, 1)
+	if err != nil || got.Title != "a" || !got.Completed {
+		t.Fatalf("task = %+v, %v", got, err)
+	}
+}
+
+func TestServerListFilter(t *testing.T) {
+	_, c := newTestServer(t)
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+	for _, task := range []*models.Task{
+		{Title: "レポートを書く", Project: "work", Priority: "A", Tags: []string{"writing"}, DueAt: &due},
+		{Title: "牛乳を買う", Project: "home", Completed: true},
+		{Title: "Review PR", Project: "work", Priority: "B"},
+	} {
+		if _, _, err := c.Create(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	completed, notCompleted := true, false
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"レポートを書く", "牛乳を買う", "Review PR"}},
+		{"completed", Filter{Completed: &completed}, []string{"牛乳を買う"}},
+		{"not completed in work", Filter{Completed: &notCompleted, Project: "work"}, []string{"レポートを書く", "Review PR"}},
+		{"priority", Filter{Priority: "B"}, []string{"Review PR"}},
+		{"tag", Filter{Tag: "writing"}, []string{"レポートを書く"}},
+		{"query", Filter{Query: "review"}, []string{"Review PR"}},
+		{"status", Filter{Status: "done"}, []string{"牛乳を買う"}},
+		{"due before", Filter{DueBefore: due.Add(time.Hour)}, []string{"レポートを書く"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := c.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			if strings.Join(titles, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("titles = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	_, c := newTestServer(t, "a")
+	ctx := context.Background()
+
+	if _, _, err := c.Create(ctx, &models.Task{Title: " "}); err == nil {
+		t.Error("expected error for empty title")
+	}
+	priority := "high"
+	if _, _, err := c.Patch(ctx, 1, Patch{Priority: &priority}, ""); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, c.BaseURL+"/api/tasks/1", strings.NewReader(`{"id": 5}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown field: status = %d", resp.StatusCode)
+	}
+
+	c.Token = "wrong"
+	if _, err := c.List(ctx, Filter{}); err == nil || !strings.Contains(err.Error(), "認証") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	_, c := newTestServer(t, "a", "b", "c")
+	r := &Remote{Client: c, Workflow: models.DefaultWorkflow()}
+
+	tm, err := r.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.UpdateTask(0, "a2")
+	tm.ToggleTask(1)
+	tm.DeleteTask(2)
+	tm.AddTask("d")
+	if err := r.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := c.List(context.Background(), Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[0].Title != "a2" || !tasks[1].Completed || tasks[2].Title != "d" || tasks[2].ID != 4 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+
+	// 続けて変更しても、保存した後の内容と比べて送る
+	tm.UpdateTask(0, "a3")
+	if err := r.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+
+	// 他のクライアントの変更は上書きしない
+	other := &Remote{Client: c}
+	otherTM, err := other.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherTM.UpdateTask(0, "他のクライアント")
+	if err := other.Save(otherTM); err != nil {
+		t.Fatal(err)
+	}
+	tm.UpdateTask(0, "古い内容からの変更")
+	if err := r.Save(tm); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	got, _, _ := c.Get(context.Background(), 1)
+	if got.Title != "他のクライアント" {
+		t.Errorf("title = %q", got.Title)
+	}
+}
+
+func TestRemoteSendsContextsAnnotationsAndMeta(t *testing.T) {
+	_, c := newTestServer(t, "a", "b")
+	r := &Remote{Client: c, Workflow: models.DefaultWorkflow()}
+
+	tm, err := r.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := models.Annotation{Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Text: "メモ"}
+	tm.SetContexts(0, []string{"home"})
+	tm.SetAnnotations(0, []models.Annotation{note})
+	tm.ReplaceMeta(0, map[string]string{"uid": "abc"})
+	if err := r.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := c.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Contexts, []string{"home"}) || len(got.Annotations) != 1 || got.Annotations[0].Text != "メモ" || got.Meta["uid"] != "abc" {
+		t.Fatalf("task = %+v", got)
+	}
+
+	// メタ情報を消す変更も送る
+	tm.ReplaceMeta(0, nil)
+	if err := r.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := c.Get(context.Background(), 1); len(got.Meta) != 0 {
+		t.Fatalf("meta = %v", got.Meta)
+	}
+
+	// 送れない変更（並び順）は黙って捨てずにエラーにする
+	first := tm.GetTaskByIndex(0)
+	tm.DeleteTask(0)
+	tm.RevertTask(first.ID, first, 1)
+	if err := r.Save(tm); err == nil {
+		t.Fatal("expected an error for a reordered task")
+	}
+}
+
+func TestUnsent(t *testing.T) {
+	old := &models.Task{ID: 1, Title: "a", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	task := old.Clone()
+	task.Title = "b"
+	task.Contexts = []string{"office"}
+	if fields := Unsent(old, task); len(fields) != 0 {
+		t.Fatalf("Unsent = %v", fields)
+	}
+	task.CreatedAt = task.CreatedAt.Add(time.Hour)
+	if fields := Unsent(old, task); !reflect.DeepEqual(fields, []string{"created_at"}) {
+		t.Fatalf("Unsent = %v", fields)
+	}
+}
+
+func TestAccessUpdate(t *testing.T) {
+	store := apitest.NewStore("a")
+	access := NewAccess(store)
+
+	// fn がエラーを返した変更は保存しない
+	err := access.Update(func(tm *models.TaskManager) error {
+		tm.UpdateTask(0, "b")
+		_, err := Find(tm, 99, "")
+		return err
+	})
+	if !errors.Is(err, ErrNotFound) || store.Tasks(t)[0].Title != "a" {
+		t.Fatalf("err = %v, tasks = %+v", err, store.Tasks(t))
+	}
+
+	err = access.Update(func(tm *models.TaskManager) error {
+		_, err := Find(tm, 1, `"stale"`)
+		return err
+	})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("err = %v", err)
+	}
+
+	err = access.Update(func(tm *models.TaskManager) error {
+		index, err := Find(tm, 1, ETag(tm.GetTaskByIndex(0)))
+		if err != nil {
+			return err
+		}
+		tm.UpdateTask(index, "b")
+		return nil
+	})
+	if err != nil || store.Tasks(t)[0].Title != "b" {
+		t.Fatalf("err = %v, tasks = %+v", err, store.Tasks(t))
+	}
+}
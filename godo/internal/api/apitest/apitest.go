@@ -0,0 +1,71 @@
+// Package apitest は api.Store を使うサーバー（REST API・Web 画面）のテストで共有する Store を提供する
+package apitest
+
+import (
+	"encoding/json"
+	"godo/pkg/models"
+	"sync"
+	"testing"
+)
+
+// Store タスクを JSON として保持するテスト用の Store
+// 保存のたびに JSON にするので、ファイルに保存した場合と同じように読み込み直される
+type Store struct {
+	mu     sync.Mutex
+	data   []byte
+	events []models.Event
+}
+
+// NewStore titles のタスクを保存した Store を作成する
+func NewStore(titles ...string) *Store {
+	s := &Store{}
+	tm := models.NewTaskManager([]*models.Task{})
+	for _, title := range titles {
+		tm.AddTask(title)
+	}
+	s.Save(tm)
+	return s
+}
+
+// Load 保存したタスクを読み込む
+func (s *Store) Load() (*models.TaskManager, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := []*models.Task{}
+	if s.data != nil {
+		if err := json.Unmarshal(s.data, &tasks); err != nil {
+			return nil, err
+		}
+	}
+	return models.NewTaskManager(tasks), nil
+}
+
+// Save タスクを JSON にして保持し、変更履歴を記録する
+func (s *Store) Save(tm *models.TaskManager) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(tm.GetTasks())
+	if err != nil {
+		return err
+	}
+	s.data = data
+	s.events = append(s.events, tm.TakePendingEvents()...)
+	return nil
+}
+
+// Tasks 保存されているタスクを返す
+func (s *Store) Tasks(t testing.TB) []*models.Task {
+	t.Helper()
+	tm, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm.GetTasks()
+}
+
+// Events これまでに保存した変更履歴を返す
+func (s *Store) Events() []models.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.Event(nil), s.events...)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrPreconditionFailed If-Match の ETag が一致せず、他のクライアントが先にタスクを変更していた
+var ErrPreconditionFailed = errors.New("タスクは他のクライアントによって変更されています")
+
+// ErrNotFound タスクが見つからない
+var ErrNotFound = errors.New("タスクが見つかりません")
+
+// Client godo serve の REST API のクライアント
+type Client struct {
+	BaseURL string // 例: http://127.0.0.1:8080
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewClient クライアントを作成する
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// List 絞り込み条件に合うタスクの一覧を返す
+func (c *Client) List(ctx context.Context, filter Filter) ([]*models.Task, error) {
+	path := "/api/tasks"
+	if q := FilterValues(filter).Encode(); q != "" {
+		path += "?" + q
+	}
+	var tasks []*models.Task
+	if _, err := c.do(ctx, http.MethodGet, path, "", nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Get タスクとその ETag を返す
+func (c *Client) Get(ctx context.Context, id int) (*models.Task, string, error) {
+	return c.task(ctx, http.MethodGet, id, "", "", nil)
+}
+
+// Create タスクを作成する。ID はサーバーが割り当てる
+func (c *Client) Create(ctx context.Context, task *models.Task) (*models.Task, string, error) {
+	var created models.Task
+	etag, err := c.do(ctx, http.MethodPost, "/api/tasks", "", task, &created)
+	if err != nil {
+		return nil, "", err
+	}
+	return &created, etag, nil
+}
+
+// Patch タスクの一部の項目を変更する。etag を指定すると他のクライアントの変更と衝突したときに ErrPreconditionFailed を返す
+func (c *Client) Patch(ctx context.Context, id int, patch Patch, etag string) (*models.Task, string, error) {
+	return c.task(ctx, http.MethodPatch, id, "", etag, patch)
+}
+
+// Toggle タスクの完了/未完了を切り替える
+func (c *Client) Toggle(ctx context.Context, id int, etag string) (*models.Task, string, error) {
+	return c.task(ctx, http.MethodPost, id, "/toggle", etag, nil)
+}
+
+// Delete タスクを削除する
+func (c *Client) Delete(ctx context.Context, id int, etag string) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/tasks/%d", id), etag, nil, nil)
+	return err
+}
+
+func (c *Client) task(ctx context.Context, method string, id int, suffix, etag string, body any) (*models.Task, string, error) {
+	var task models.Task
+	newETag, err := c.do(ctx, method, fmt.Sprintf("/api/tasks/%d%s", id, suffix), etag, body, &task)
+	if err != nil {
+		return nil, "", err
+	}
+	return &task, newETag, nil
+}
+
+// do リクエストを送り、応答の JSON を out に読み込んで ETag を返す
+func (c *Client) do(ctx context.Context, method, path, etag string, body, out any) (string, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("サーバーに接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		switch resp.StatusCode {
+		case http.StatusPreconditionFailed:
+			return "", fmt.Errorf("%w: %s", ErrPreconditionFailed, e.Error)
+		case http.StatusNotFound:
+			return "", fmt.Errorf("%w: %s", ErrNotFound, e.Error)
+		}
+		if e.Error == "" {
+			e.Error = resp.Status
+		}
+		return "", fmt.Errorf("%s %s: %s", method, path, e.Error)
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return "", fmt.Errorf("サーバーの応答を解釈できません: %w", err)
+		}
+	}
+	return resp.Header.Get("ETag"), nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"slices"
+	"strings"
+)
+
+// Remote サーバーのタスクを TaskManager として扱うための Store
+// 読み込んだ時点のタスクを覚えておき、保存時には変更のあったタスクだけを
+// If-Match 付きの作成・変更・削除のリクエストとして送る
+type Remote struct {
+	Client   *Client
+	Workflow models.Workflow
+	loaded   map[int]*models.Task // 読み込んだ時点のタスク（ID → タスク）
+	order    []int                // 読み込んだ時点のタスクの並び順（ID）
+}
+
+// NewRemote サーバーに接続する Remote を作成する
+func NewRemote(baseURL, token string, workflow models.Workflow) *Remote {
+	return &Remote{Client: NewClient(baseURL, token), Workflow: workflow}
+}
+
+// Load サーバーからタスクを読み込む
+func (r *Remote) Load() (*models.TaskManager, error) {
+	tasks, err := r.Client.List(context.Background(), Filter{})
+	if err != nil {
+		return nil, err
+	}
+	r.loaded = map[int]*models.Task{}
+	r.order = nil
+	for _, task := range tasks {
+		r.loaded[task.ID] = task.Clone()
+		r.order = append(r.order, task.ID)
+	}
+	tm := models.NewTaskManager(tasks)
+	tm.SetWorkflow(r.Workflow)
+	return tm, nil
+}
+
+// Save 読み込んでからの変更をサーバーに送る
+// 他のクライアントが先に変更していたタスクは上書きせず、ErrPreconditionFailed を含むエラーを返す
+// API で送れない変更（並び順など）がある場合は、黙って捨てずにエラーを返す
+func (r *Remote) Save(tm *models.TaskManager) error {
+	ctx := context.Background()
+	tm.TakePendingEvents() // 変更履歴はサーバー側で記録する
+	var errs []error
+	if !r.sameOrder(tm.GetTasks()) {
+		errs = append(errs, errors.New("タスクの並び順の変更はサーバーに送れません"))
+	}
+	seen := map[int]bool{}
+	for _, task := range tm.GetTasks() {
+		old, ok := r.loaded[task.ID]
+		if !ok {
+			created, _, err := r.Client.Create(ctx, task)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("'%s' を作成できません: %w", task.Title, err))
+				continue
+			}
+			// サーバーが割り当てたIDに合わせる
+			tm.ChangeID(task.ID, created.ID)
+			task.ID = created.ID
+			r.remember(created)
+			seen[task.ID] = true
+			continue
+		}
+		seen[task.ID] = true
+		if fields := Unsent(old, task); len(fields) > 0 {
+			errs = append(errs, fmt.Errorf("ID %d: サーバーに送れない項目が変更されています: %s", task.ID, strings.Join(fields, ", ")))
+			continue
+		}
+		patch := Diff(old, task)
+		if patch.IsEmpty() {
+			continue
+		}
+		updated, _, err := r.Client.Patch(ctx, task.ID, patch, ETag(old))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ID %d: %w", task.ID, err))
+			continue
+		}
+		r.remember(updated)
+	}
+	for id, old := range r.loaded {
+		if seen[id] {
+			continue
+		}
+		if err := r.Client.Delete(ctx, id, ETag(old)); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, fmt.Errorf("ID %d: %w", id, err))
+			continue
+		}
+		delete(r.loaded, id)
+	}
+	return errors.Join(errs...)
+}
+
+// sameOrder 読み込んだ時点からあるタスクの並び順が変わっていないかを返す
+func (r *Remote) sameOrder(tasks []*models.Task) bool {
+	var current []int
+	for _, task := range tasks {
+		if _, ok := r.loaded[task.ID]; ok {
+			current = append(current, task.ID)
+		}
+	}
+	var loaded []int
+	for _, id := range r.order {
+		if slices.Contains(current, id) {
+			loaded = append(loaded, id)
+		}
+	}
+	return slices.Equal(current, loaded)
+}
+
+func (r *Remote) remember(task *models.Task) {
+	if r.loaded == nil {
+		r.loaded = map[int]*models.Task{}
+	}
+	if _, ok := r.loaded[task.ID]; !ok {
+		r.order = append(r.order, task.ID)
+	}
+	r.loaded[task.ID] = task
+}
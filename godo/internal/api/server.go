@@ -0,0 +1,434 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store サーバーがタスクを読み書きする先
+type Store = models.Store
+
+// StorageStore TaskStorage に保存する Store
+type StorageStore struct {
+	Storage  *storage.TaskStorage
+	Workflow models.Workflow
+}
+
+// NewStorageStore TaskStorage に保存する Store を作成する
+func NewStorageStore(ts *storage.TaskStorage, workflow models.Workflow) *StorageStore {
+	return &StorageStore{Storage: ts, Workflow: workflow}
+}
+
+// Load タスクを読み込む
+func (s *StorageStore) Load() (*models.TaskManager, error) {
+	tm, err := s.Storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	tm.SetWorkflow(s.Workflow)
+	return tm, nil
+}
+
+// Save タスクと変更履歴を保存する
+func (s *StorageStore) Save(tm *models.TaskManager) error {
+	return s.Storage.Save(tm)
+}
+
+// Lock 読み込みから保存までの間、他のプロセスが書き込まないようにロックを取る
+func (s *StorageStore) Lock() (unlock func(), err error) {
+	return s.Storage.Lock()
+}
+
+// Locker 読み込みから保存までの間、他のプロセスの書き込みを止められる Store
+type Locker interface {
+	Lock() (unlock func(), err error)
+}
+
+// StoreError タスクの読み込み・保存やロックの失敗（サーバーの内部エラーとして応答する）
+type StoreError struct {
+	Err error
+}
+
+func (e *StoreError) Error() string { return e.Err.Error() }
+func (e *StoreError) Unwrap() error { return e.Err }
+
+// Access REST API・Web 画面・gRPC のサーバーに共通の、リクエストごとのタスクの読み書き
+// リクエストごとにタスクを読み込み直すので、CLI や TUI での変更もすぐに反映される
+type Access struct {
+	store Store
+	mu    sync.Mutex // 読み込みから保存までの間に他のリクエストが割り込まないようにする
+}
+
+// NewAccess store のタスクを読み書きする Access を作成する
+func NewAccess(store Store) *Access {
+	return &Access{store: store}
+}
+
+// View タスクを読み込んで fn に渡す。保存はしない
+func (a *Access) View(fn func(tm *models.TaskManager) error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tm, err := a.store.Load()
+	if err != nil {
+		return &StoreError{Err: err}
+	}
+	return fn(tm)
+}
+
+// Tasks 現在のタスクを読み込んで返す
+// 変更の配信や Webhook に、変更されたタスクの現在の内容を添えるために使う
+func (a *Access) Tasks() ([]*models.Task, error) {
+	var tasks []*models.Task
+	err := a.View(func(tm *models.TaskManager) error {
+		tasks = tm.GetTasks()
+		return nil
+	})
+	return tasks, err
+}
+
+// Update ロックを取ってタスクを読み込み、fn がエラーを返さなければ保存する
+// mu は同じプロセスのリクエスト同士を、store が Locker ならファイルのロックで同じファイルを使う他のプロセスとの間を排他する
+func (a *Access) Update(fn func(tm *models.TaskManager) error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if locker, ok := a.store.(Locker); ok {
+		unlock, err := locker.Lock()
+		if err != nil {
+			return &StoreError{Err: err}
+		}
+		defer unlock()
+	}
+	tm, err := a.store.Load()
+	if err != nil {
+		return &StoreError{Err: err}
+	}
+	if err := fn(tm); err != nil {
+		return err
+	}
+	if err := a.store.Save(tm); err != nil {
+		return &StoreError{Err: fmt.Errorf("保存に失敗しました: %w", err)}
+	}
+	return nil
+}
+
+// Find ID のタスクのインデックスを返す。見つからなければ ErrNotFound を含むエラーを返す
+// etag が空でなければ現在のタスクの ETag と比べ、一致しなければ ErrPreconditionFailed を含むエラーを返す
+func Find(tm *models.TaskManager, id int, etag string) (int, error) {
+	index := tm.FindIndexByID(id)
+	if index < 0 {
+		return -1, fmt.Errorf("ID %d の%w", id, ErrNotFound)
+	}
+	if etag != "" && etag != ETag(tm.GetTaskByIndex(index)) {
+		return -1, fmt.Errorf("ID %d の%w", id, ErrPreconditionFailed)
+	}
+	return index, nil
+}
+
+// Server タスクの REST API のサーバー
+type Server struct {
+	tasks *Access
+	token string // 空なら認証しない
+	mux   *http.ServeMux
+}
+
+// NewServer tasks のタスクを読み書きするサーバーを作成する。token が空でなければ Authorization: Bearer <token> を必須にする
+func NewServer(tasks *Access, token string) *Server {
+	s := &Server{tasks: tasks, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /api/tasks", s.handleList)
+	s.mux.HandleFunc("POST /api/tasks", s.handleCreate)
+	s.mux.HandleFunc("GET /api/tasks/{id}", s.handleGet)
+	s.mux.HandleFunc("PATCH /api/tasks/{id}", s.handlePatch)
+	s.mux.HandleFunc("DELETE /api/tasks/{id}", s.handleDelete)
+	s.mux.HandleFunc("POST /api/tasks/{id}/toggle", s.handleToggle)
+	return s
+}
+
+// ServeHTTP 認証してからリクエストを処理する
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="godo"`)
+			writeError(w, http.StatusUnauthorized, "認証に失敗しました")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tasks := []*models.Task{}
+	err = s.tasks.View(func(tm *models.TaskManager) error {
+		for _, task := range tm.GetTasks() {
+			if filter.Match(tm.Workflow(), task) {
+				tasks = append(tasks, task)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var task models.Task
+	if err := decodeBody(r, &task); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var created *models.Task
+	err := s.tasks.Update(func(tm *models.TaskManager) (err error) {
+		created, err = CreateTask(tm, &task)
+		return err
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/tasks/%d", created.ID))
+	writeTask(w, http.StatusCreated, created)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var task *models.Task
+	err := s.tasks.View(func(tm *models.TaskManager) error {
+		index, err := Find(tm, id, "")
+		if err != nil {
+			return err
+		}
+		task = tm.GetTaskByIndex(index)
+		return nil
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeTask(w, http.StatusOK, task)
+}
+
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request) {
+	var patch Patch
+	if err := decodeBody(r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.modify(w, r, http.StatusOK, func(tm *models.TaskManager, index int) error {
+		return ApplyPatch(tm, index, patch)
+	})
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.modify(w, r, http.StatusNoContent, func(tm *models.TaskManager, index int) error {
+		tm.DeleteTask(index)
+		return nil
+	})
+}
+
+func (s *Server) handleToggle(w http.ResponseWriter, r *http.Request) {
+	s.modify(w, r, http.StatusOK, func(tm *models.TaskManager, index int) error {
+		tm.ToggleTask(index)
+		return nil
+	})
+}
+
+// modify パスの ID のタスクを If-Match を確認してから fn で変更し、保存して status で応答する
+// 204 以外は変更後のタスクを返す
+func (s *Server) modify(w http.ResponseWriter, r *http.Request, status int, fn func(tm *models.TaskManager, index int) error) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var task *models.Task
+	err := s.tasks.Update(func(tm *models.TaskManager) error {
+		index, err := Find(tm, id, "")
+		if err != nil {
+			return err
+		}
+		if err := checkMatch(w, r, tm.GetTaskByIndex(index)); err != nil {
+			return err
+		}
+		if err := fn(tm, index); err != nil {
+			return err
+		}
+		if status != http.StatusNoContent {
+			task = tm.GetTaskByIndex(index)
+		}
+		return nil
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if task == nil {
+		w.WriteHeader(status)
+		return
+	}
+	writeTask(w, status, task)
+}
+
+// pathID パスの ID を返す。不正な場合はエラーを応答して ok = false を返す
+func pathID(w http.ResponseWriter, r *http.Request) (id int, ok bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("タスクIDが不正です: %q", r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// writeStoreError Access のエラーを対応するステータスコードで応答する
+func writeStoreError(w http.ResponseWriter, err error) {
+	var storeErr *StoreError
+	switch {
+	case errors.As(err, &storeErr):
+		writeError(w, http.StatusInternalServerError, err.Error())
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrPreconditionFailed):
+		writeError(w, http.StatusPreconditionFailed, err.Error())
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// checkMatch If-Match が指定されていれば現在のタスクの ETag と比べる
+// 一致しなければ現在の ETag をヘッダーに付け、ErrPreconditionFailed を含むエラーを返す
+func checkMatch(w http.ResponseWriter, r *http.Request, task *models.Task) error {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return nil
+	}
+	etag := ETag(task)
+	for _, candidate := range strings.Split(match, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return nil
+		}
+	}
+	w.Header().Set("ETag", etag)
+	return fmt.Errorf("ID %d の%w", task.ID, ErrPreconditionFailed)
+}
+
+// CreateTask 受け取ったタスクを検証し、新しいIDで追加する
+func CreateTask(tm *models.TaskManager, task *models.Task) (*models.Task, error) {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return nil, errors.New("タイトルを指定してください")
+	}
+	if err := models.ValidatePriority(task.Priority); err != nil {
+		return nil, err
+	}
+	if task.Status != "" && tm.Workflow().Index(task.Status) < 0 {
+		return nil, fmt.Errorf("状態が不正です: %q (%s)", task.Status, tm.Workflow())
+	}
+	if task.Completed && task.CompletedAt == nil {
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+	return tm.ImportTask(task), nil
+}
+
+// ApplyPatch 変更内容を TaskManager の操作としてタスクに反映する
+func ApplyPatch(tm *models.TaskManager, index int, p Patch) error {
+	if p.Priority != nil {
+		if err := models.ValidatePriority(*p.Priority); err != nil {
+			return err
+		}
+	}
+	if p.Status != nil && *p.Status != "" && tm.Workflow().Index(*p.Status) < 0 {
+		return fmt.Errorf("状態が不正です: %q (%s)", *p.Status, tm.Workflow())
+	}
+	if p.Title != nil {
+		title := strings.TrimSpace(*p.Title)
+		if title == "" {
+			return errors.New("タイトルを空にはできません")
+		}
+		tm.UpdateTask(index, title)
+	}
+
+	if p.Status != nil && *p.Status != "" && tm.StatusOf(tm.GetTaskByIndex(index)) != *p.Status {
+		tm.SetStatus(index, *p.Status)
+	}
+	// 状態の変更で完了状態が変わっている場合があるので、変更後のタスクと比べる
+	if p.Completed != nil && tm.GetTaskByIndex(index).Completed != *p.Completed {
+		tm.ToggleTask(index)
+	}
+	if p.Priority != nil {
+		tm.SetPriority(index, *p.Priority)
+	}
+	if p.Project != nil {
+		tm.SetProject(index, *p.Project)
+	}
+	if p.Tags != nil {
+		tm.SetTags(index, *p.Tags)
+	}
+	if p.Contexts != nil {
+		tm.SetContexts(index, *p.Contexts)
+	}
+	if p.DueAt != nil {
+		tm.SetDue(index, p.DueAt)
+	} else if p.ClearDue {
+		tm.SetDue(index, nil)
+	}
+	if p.TimeEntries != nil {
+		tm.SetTimeEntries(index, *p.TimeEntries)
+	}
+	if p.Annotations != nil {
+		tm.SetAnnotations(index, *p.Annotations)
+	}
+	if p.Pomodoros != nil {
+		tm.SetPomodoros(index, *p.Pomodoros)
+	}
+	if p.Meta != nil {
+		tm.ReplaceMeta(index, *p.Meta)
+	}
+	return nil
+}
+
+// decodeBody リクエストの本文を JSON として読み込む
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("リクエストのJSONを解釈できません: %w", err)
+	}
+	return nil
+}
+
+func writeTask(w http.ResponseWriter, status int, task *models.Task) {
+	w.Header().Set("ETag", ETag(task))
+	writeJSON(w, status, task)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,259 @@
+package caldav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/format/ical"
+	"godo/pkg/models"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const remoteTodo = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:remote-1\r\nSUMMARY:%s\r\nSTATUS:NEEDS-ACTION\r\nLAST-MODIFIED:%s\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+func todo(summary string, modified time.Time) string {
+	return fmt.Sprintf(remoteTodo, summary, modified.UTC().Format("20060102T150405Z"))
+}
+
+func newTestClient(t *testing.T) (*fakeServer, *Client) {
+	fs, srv := newFakeServer(t)
+	return fs, NewClient(srv.URL+testCollection, "alice", "secret")
+}
+
+func TestClient(t *testing.T) {
+	fs, c := newTestClient(t)
+	ctx := context.Background()
+	href := c.Href("a.ics")
+
+	etag, err := c.Put(ctx, href, []byte(todo("a", time.Now())), "")
+	if err != nil || etag == "" {
+		t.Fatalf("Put = %q, %v", etag, err)
+	}
+	// 既にあるリソースを新規作成しようとすると失敗する
+	if _, err := c.Put(ctx, href, []byte("x"), ""); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	listed, err := c.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].Href != href || listed[0].ETag != etag {
+		t.Fatalf("List = %+v", listed)
+	}
+	fetched, err := c.Fetch(ctx, []string{href})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fetched) != 1 || !strings.Contains(string(fetched[0].Data), "SUMMARY:a") {
+		t.Fatalf("Fetch = %+v", fetched)
+	}
+
+	// 他のクライアントが変更した後は古い ETag では更新も削除もできない
+	fs.put(href, todo("b", time.Now()))
+	if _, err := c.Put(ctx, href, []byte("x"), etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := c.Delete(ctx, href, etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	c.Password = "wrong"
+	if _, err := c.List(ctx); err == nil {
+		t.Fatal("expected authentication error")
+	}
+}
+
+func TestSyncPushAndPull(t *testing.T) {
+	fs, c := newTestClient(t)
+	ctx := context.Background()
+	state := &State{}
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("ローカルのタスク")
+	fs.put(testCollection+"remote-1.ics", todo("サーバーのタスク", time.Now().Add(-time.Hour)))
+
+	report, err := Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pushed != 1 || report.Pulled != 1 || len(report.Errors) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(tm.GetTasks()) != 2 || tm.GetTaskByIndex(1).Title != "サーバーのタスク" {
+		t.Fatalf("tasks = %+v", tm.GetTasks())
+	}
+	local := testCollection + tm.GetTaskByIndex(0).Meta[ical.MetaUID] + ".ics"
+	data, ok := fs.get(local)
+	if !ok || !strings.Contains(data, "SUMMARY:ローカルのタスク") {
+		t.Fatalf("ローカルのタスクがサーバーに書き込まれていません: %q", data)
+	}
+	if len(state.Entries) != 2 {
+		t.Fatalf("state = %+v", state.Entries)
+	}
+
+	// 変更がなければ何もしない
+	report, err = Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pushed != 0 || report.Pulled != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	// サーバー側の変更を取り込む
+	fs.put(testCollection+"remote-1.ics", todo("サーバーで変更", time.Now()))
+	// ローカルの変更を書き込む
+	tm.UpdateTask(0, "ローカルで変更")
+	if _, err := Sync(ctx, c, tm, state); err != nil {
+		t.Fatal(err)
+	}
+	if tm.GetTaskByIndex(1).Title != "サーバーで変更" {
+		t.Errorf("サーバーの変更が反映されていません: %+v", tm.GetTaskByIndex(1))
+	}
+	if data, _ := fs.get(local); !strings.Contains(data, "SUMMARY:ローカルで変更") {
+		t.Errorf("ローカルの変更が書き込まれていません: %q", data)
+	}
+}
+
+func TestSyncDeletes(t *testing.T) {
+	fs, c := newTestClient(t)
+	ctx := context.Background()
+	state := &State{}
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	tm.AddTask("b")
+	if _, err := Sync(ctx, c, tm, state); err != nil {
+		t.Fatal(err)
+	}
+
+	// ローカルで削除したタスクはサーバーからも削除し、サーバーで削除されたタスクはローカルからも削除する
+	removed := testCollection + tm.GetTaskByIndex(1).Meta[ical.MetaUID] + ".ics"
+	tm.DeleteTask(0)
+	fs.remove(removed)
+	report, err := Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DeletedRemote != 1 || report.DeletedLocal != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(tm.GetTasks()) != 0 || len(fs.hrefs()) != 0 || len(state.Entries) != 0 {
+		t.Fatalf("tasks = %+v, hrefs = %v, state = %+v", tm.GetTasks(), fs.hrefs(), state.Entries)
+	}
+}
+
+func TestSyncUsesUUIDForNewResources(t *testing.T) {
+	fs, c := newTestClient(t)
+	ctx := context.Background()
+
+	// 別の端末で同じIDのタスクを作っても、サーバー上のリソースは重ならない
+	for _, title := range []string{"端末Aのタスク", "端末Bのタスク"} {
+		tm := models.NewTaskManager([]*models.Task{})
+		tm.AddTask(title)
+		report, err := Sync(ctx, c, tm, &State{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Pushed != 1 || len(report.Errors) != 0 {
+			t.Fatalf("report = %+v", report)
+		}
+		uid := tm.GetTaskByIndex(0).Meta[ical.MetaUID]
+		data, ok := fs.get(testCollection + uid + ".ics")
+		if uid == "" || !ok || !strings.Contains(data, "UID:"+uid) || !strings.Contains(data, "SUMMARY:"+title) {
+			t.Fatalf("uid = %q, data = %q", uid, data)
+		}
+	}
+	if hrefs := fs.hrefs(); len(hrefs) != 2 {
+		t.Fatalf("hrefs = %v", hrefs)
+	}
+}
+
+func TestSyncFetchesETagWhenPutOmitsIt(t *testing.T) {
+	fs, c := newTestClient(t)
+	fs.noETag = true
+	ctx := context.Background()
+	state := &State{}
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	if _, err := Sync(ctx, c, tm, state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Entries) != 1 || state.Entries[0].ETag == "" {
+		t.Fatalf("state = %+v", state.Entries)
+	}
+
+	// 取得した ETag で変更を書き込める
+	tm.UpdateTask(0, "b")
+	report, err := Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pushed != 1 || report.Conflicts != 0 || len(report.Errors) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestSyncConflictUsesLastModified(t *testing.T) {
+	fs, c := newTestClient(t)
+	ctx := context.Background()
+	state := &State{}
+	href := testCollection + "remote-1.ics"
+
+	tm := models.NewTaskManager([]*models.Task{})
+	fs.put(href, todo("元のタイトル", time.Now().Add(-time.Hour)))
+	if _, err := Sync(ctx, c, tm, state); err != nil {
+		t.Fatal(err)
+	}
+
+	// サーバー側の方が新しい
+	tm.UpdateTask(0, "ローカルで変更")
+	fs.put(href, todo("サーバーで変更", time.Now().Add(time.Hour)))
+	report, err := Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Conflicts != 1 || tm.GetTaskByIndex(0).Title != "サーバーで変更" {
+		t.Fatalf("report = %+v, task = %+v", report, tm.GetTaskByIndex(0))
+	}
+
+	// ローカル側の方が新しい
+	fs.put(href, todo("古いサーバーの変更", time.Now().Add(-30*time.Minute)))
+	time.Sleep(10 * time.Millisecond)
+	tm.UpdateTask(0, "新しいローカルの変更")
+	report, err = Sync(ctx, c, tm, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Conflicts != 1 || tm.GetTaskByIndex(0).Title != "新しいローカルの変更" {
+		t.Fatalf("report = %+v, task = %+v", report, tm.GetTaskByIndex(0))
+	}
+	if data, _ := fs.get(href); !strings.Contains(data, "SUMMARY:新しいローカルの変更") || !strings.Contains(data, "UID:remote-1") {
+		t.Errorf("ローカルの変更が書き込まれていません: %q", data)
+	}
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "caldav.json")
+	state, err := LoadState(path)
+	if err != nil || len(state.Entries) != 0 {
+		t.Fatalf("LoadState = %+v, %v", state, err)
+	}
+	state.URL = "https://example.com/dav/"
+	state.Entries = []*Entry{{TaskID: 1, Href: "/dav/godo-1.ics", ETag: `"1"`, Synced: time.Now().UTC()}}
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.URL != state.URL || len(loaded.Entries) != 1 || *loaded.Entries[0] != *state.Entries[0] {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+}
@@ -0,0 +1,281 @@
+// Package caldav は CalDAV サーバーのカレンダーコレクションとタスクを VTODO として同期する
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrPreconditionFailed ETag が一致せず、サーバー側のリソースが変更されていた
+var ErrPreconditionFailed = errors.New("サーバー側のタスクが変更されています")
+
+// Resource コレクション内の1件のリソース
+type Resource struct {
+	Href string // コレクションからの相対パスではなく、サーバーが返したパス
+	ETag string
+	Data []byte // calendar-data（PROPFIND では空）
+}
+
+// Client CalDAV のカレンダーコレクション1つを操作するクライアント
+type Client struct {
+	URL      string // コレクションのURL（末尾の "/" は省略可）
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewClient コレクションのURLを指定してクライアントを作成する
+func NewClient(collectionURL, username, password string) *Client {
+	return &Client{URL: collectionURL, Username: username, Password: password, HTTP: http.DefaultClient}
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop><d:getetag/><d:resourcetype/></d:prop>
+</d:propfind>`
+
+// List コレクション内のリソースの href と ETag を PROPFIND で取得する
+func (c *Client) List(ctx context.Context) ([]Resource, error) {
+	resp, err := c.do(ctx, "PROPFIND", c.collection(), strings.NewReader(propfindBody), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("PROPFIND", resp)
+	}
+	responses, err := parseMultistatus(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	self := c.collectionPath()
+	var resources []Resource
+	for _, r := range responses {
+		if r.collection || strings.TrimSuffix(r.href, "/") == strings.TrimSuffix(self, "/") {
+			continue
+		}
+		resources = append(resources, Resource{Href: r.href, ETag: r.etag})
+	}
+	return resources, nil
+}
+
+// Fetch 指定した href のリソースを calendar-multiget の REPORT でまとめて取得する
+func (c *Client) Fetch(ctx context.Context, hrefs []string) ([]Resource, error) {
+	if len(hrefs) == 0 {
+		return nil, nil
+	}
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+`)
+	for _, href := range hrefs {
+		body.WriteString("  <d:href>")
+		xml.EscapeText(&body, []byte(href))
+		body.WriteString("</d:href>\n")
+	}
+	body.WriteString("</c:calendar-multiget>")
+
+	resp, err := c.do(ctx, "REPORT", c.collection(), &body, map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("REPORT", resp)
+	}
+	responses, err := parseMultistatus(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, r := range responses {
+		if r.data == "" {
+			continue
+		}
+		resources = append(resources, Resource{Href: r.href, ETag: r.etag, Data: []byte(r.data)})
+	}
+	return resources, nil
+}
+
+// Put リソースを書き込み、新しい ETag を返す
+// etag が空の場合は新規作成として、既にリソースがあれば ErrPreconditionFailed を返す
+func (c *Client) Put(ctx context.Context, href string, data []byte, etag string) (string, error) {
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.resolve(href), bytes.NewReader(data), headers)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			return etag, nil
+		}
+		// 応答に ETag を含めないサーバーもあるので、書き込んだリソースから取得する
+		return c.etag(ctx, href)
+	case http.StatusPreconditionFailed:
+		return "", fmt.Errorf("%s: %w", href, ErrPreconditionFailed)
+	}
+	return "", statusError("PUT", resp)
+}
+
+// etag リソースの現在の ETag を返す
+func (c *Client) etag(ctx context.Context, href string) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.resolve(href), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError("GET", resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// Delete リソースを削除する。ETag が一致しない場合は ErrPreconditionFailed を返す
+func (c *Client) Delete(ctx context.Context, href, etag string) error {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	resp, err := c.do(ctx, http.MethodDelete, c.resolve(href), nil, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w", href, ErrPreconditionFailed)
+	}
+	return statusError("DELETE", resp)
+}
+
+// Href コレクション内の名前からリソースの href を作成する
+func (c *Client) Href(name string) string {
+	return c.collectionPath() + url.PathEscape(name)
+}
+
+func (c *Client) collection() string {
+	return strings.TrimSuffix(c.URL, "/") + "/"
+}
+
+func (c *Client) collectionPath() string {
+	u, err := url.Parse(c.collection())
+	if err != nil {
+		return "/"
+	}
+	return u.EscapedPath()
+}
+
+// resolve サーバーが返した href をURLに変換する
+func (c *Client) resolve(href string) string {
+	base, err := url.Parse(c.collection())
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストを作成できません: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CalDAVサーバーに接続できません: %w", err)
+	}
+	return resp, nil
+}
+
+func statusError(method string, resp *http.Response) error {
+	return fmt.Errorf("CalDAVサーバーが %s にエラーを返しました: %s", method, resp.Status)
+}
+
+// multistatus の応答を読むための型
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag         string `xml:"DAV: getetag"`
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+type response struct {
+	href       string
+	etag       string
+	data       string
+	collection bool
+}
+
+func parseMultistatus(r io.Reader) ([]response, error) {
+	var ms multistatus
+	if err := xml.NewDecoder(r).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("CalDAVサーバーの応答を解釈できません: %w", err)
+	}
+	var responses []response
+	for _, r := range ms.Responses {
+		res := response{href: strings.TrimSpace(r.Href)}
+		for _, ps := range r.Propstat {
+			if ps.Status != "" && !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			if ps.Prop.ETag != "" {
+				res.etag = ps.Prop.ETag
+			}
+			if ps.Prop.CalendarData != "" {
+				res.data = ps.Prop.CalendarData
+			}
+			if ps.Prop.ResourceType.Collection != nil {
+				res.collection = true
+			}
+		}
+		responses = append(responses, res)
+	}
+	return responses, nil
+}
@@ -0,0 +1,177 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testCollection = "/calendars/team/todo/"
+
+// fakeServer テスト用の最小限の CalDAV サーバー
+// 1つのカレンダーコレクションに対する PROPFIND / REPORT / GET / PUT / DELETE に応答する
+type fakeServer struct {
+	mu        sync.Mutex
+	resources map[string]*fakeResource // href → リソース
+	seq       int
+	username  string
+	password  string
+	noETag    bool // PUT の応答に ETag を含めない
+}
+
+type fakeResource struct {
+	data string
+	etag string
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+	fs := &fakeServer{resources: map[string]*fakeResource{}, username: "alice", password: "secret"}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+	return fs, srv
+}
+
+// put サーバー側（他のクライアント）での変更を再現する
+func (fs *fakeServer) put(href, data string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.seq++
+	fs.resources[href] = &fakeResource{data: data, etag: fmt.Sprintf(`"%d"`, fs.seq)}
+}
+
+func (fs *fakeServer) remove(href string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	delete(fs.resources, href)
+}
+
+func (fs *fakeServer) get(href string) (string, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	r, ok := fs.resources[href]
+	if !ok {
+		return "", false
+	}
+	return r.data, true
+}
+
+func (fs *fakeServer) hrefs() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var hrefs []string
+	for href := range fs.resources {
+		hrefs = append(hrefs, href)
+	}
+	sort.Strings(hrefs)
+	return hrefs
+}
+
+func (fs *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != fs.username || pass != fs.password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	switch r.Method {
+	case "PROPFIND":
+		if r.URL.Path != testCollection || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+		sb.WriteString(`<d:response><d:href>` + testCollection + `</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+		for href, res := range fs.resources {
+			fmt.Fprintf(&sb, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag><d:resourcetype/></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, escape(res.etag))
+		}
+		sb.WriteString(`</d:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, sb.String())
+
+	case "REPORT":
+		var query struct {
+			XMLName xml.Name `xml:"urn:ietf:params:xml:ns:caldav calendar-multiget"`
+			Hrefs   []string `xml:"DAV: href"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&query); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`)
+		for _, href := range query.Hrefs {
+			res, ok := fs.resources[href]
+			if !ok {
+				fmt.Fprintf(&sb, `<d:response><d:href>%s</d:href><d:status>HTTP/1.1 404 Not Found</d:status></d:response>`, href)
+				continue
+			}
+			fmt.Fprintf(&sb, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag><c:calendar-data>%s</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, escape(res.etag), escape(res.data))
+		}
+		sb.WriteString(`</d:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, sb.String())
+
+	case http.MethodGet:
+		res, ok := fs.resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", res.etag)
+		io.WriteString(w, res.data)
+
+	case http.MethodPut:
+		res, exists := fs.resources[r.URL.Path]
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || res.etag != match) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		fs.seq++
+		etag := fmt.Sprintf(`"%d"`, fs.seq)
+		fs.resources[r.URL.Path] = &fakeResource{data: string(data), etag: etag}
+		if !fs.noETag {
+			w.Header().Set("ETag", etag)
+		}
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+
+	case http.MethodDelete:
+		res, exists := fs.resources[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && res.etag != match {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(fs.resources, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package caldav
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Entry 同期済みのタスクとサーバー上のリソースの対応
+type Entry struct {
+	TaskID int       `json:"task_id"`
+	Href   string    `json:"href"`
+	ETag   string    `json:"etag"`
+	Synced time.Time `json:"synced"` // 同期した時点のタスクの UpdatedAt
+}
+
+// State 前回の同期の状態
+// 前回から変更されたのがタスクとサーバーのどちらかを判断するために使う
+type State struct {
+	URL     string   `json:"url"`
+	Entries []*Entry `json:"entries"`
+}
+
+// LoadState 同期の状態を読み込む（ファイルがなければ空の状態を返す）
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("同期の状態を読み込めません: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("同期の状態が壊れています: %w", err)
+	}
+	return &state, nil
+}
+
+// Save 同期の状態を保存する
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("同期の状態を保存できません: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("同期の状態を保存できません: %w", err)
+	}
+	return nil
+}
+
+func (s *State) byTaskID(id int) *Entry {
+	for _, e := range s.Entries {
+		if e.TaskID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *State) byHref(href string) *Entry {
+	for _, e := range s.Entries {
+		if e.Href == href {
+			return e
+		}
+	}
+	return nil
+}
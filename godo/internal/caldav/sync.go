@@ -0,0 +1,220 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/format"
+	"godo/internal/format/ical"
+	"godo/pkg/models"
+)
+
+// Report 同期で行った変更の件数
+type Report struct {
+	Pulled        int     // サーバーから取り込んだタスク（追加・更新）
+	Pushed        int     // サーバーに書き込んだタスク（追加・更新）
+	DeletedLocal  int     // サーバーで削除されたため削除したタスク
+	DeletedRemote int     // 削除したタスクに対応してサーバーから削除したリソース
+	Conflicts     int     // 両方で変更されていたタスク（更新日時の新しい方を採用）
+	Errors        []error // 同期できなかったタスクのエラー（次回の同期で再試行する）
+}
+
+// Sync タスクとサーバーのコレクションを双方向に同期し、state を更新する
+//
+// 前回の同期以降に変更された側の内容を反映し、両方で変更されていた場合は
+// タスクの UpdatedAt と VTODO の LAST-MODIFIED の新しい方を採用する。
+// 個々のタスクの書き込みに失敗しても同期は続け、エラーは Report.Errors に記録する。
+func Sync(ctx context.Context, c *Client, tm *models.TaskManager, state *State) (*Report, error) {
+	if state.URL != c.URL {
+		// 別のサーバーとの同期状態は使えない
+		state.URL = c.URL
+		state.Entries = nil
+	}
+
+	listed, err := c.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remoteETags := map[string]string{}
+	var fetch []string
+	for _, r := range listed {
+		remoteETags[r.Href] = r.ETag
+		if e := state.byHref(r.Href); e == nil || e.ETag != r.ETag {
+			fetch = append(fetch, r.Href)
+		}
+	}
+	fetched, err := c.Fetch(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+	remote := map[string]Resource{}
+	for _, r := range fetched {
+		remote[r.Href] = r
+	}
+
+	s := &syncer{ctx: ctx, client: c, tm: tm, remote: remote, report: &Report{}}
+	var entries []*Entry
+	for _, e := range state.Entries {
+		if s.syncEntry(e, remoteETags) {
+			entries = append(entries, e)
+		}
+	}
+
+	// サーバーで新しく作られたリソースを取り込む
+	for _, r := range listed {
+		if state.byHref(r.Href) != nil {
+			continue
+		}
+		task, err := s.parse(r.Href)
+		if err != nil {
+			s.fail(err)
+			continue
+		}
+		task = tm.ImportTask(task)
+		entries = append(entries, &Entry{TaskID: task.ID, Href: r.Href, ETag: s.remote[r.Href].ETag, Synced: task.UpdatedAt})
+		s.report.Pulled++
+	}
+
+	// まだサーバーにないタスクを書き込む
+	known := map[int]bool{}
+	for _, e := range entries {
+		known[e.TaskID] = true
+	}
+	for i, task := range tm.GetTasks() {
+		if known[task.ID] {
+			continue
+		}
+		// タスクIDは他の端末のタスクと重なるので、UUID を UID とリソース名に使う
+		uid := format.EnsureID(tm, i, ical.MetaUID)
+		e := &Entry{TaskID: task.ID, Href: c.Href(uid + ".ics")}
+		if s.push(tm.GetTaskByIndex(i), e, "") {
+			entries = append(entries, e)
+		}
+	}
+
+	state.Entries = entries
+	return s.report, nil
+}
+
+type syncer struct {
+	ctx    context.Context
+	client *Client
+	tm     *models.TaskManager
+	remote map[string]Resource
+	report *Report
+}
+
+// syncEntry 前回同期したタスク1件を同期する。対応を残す場合はtrueを返す
+func (s *syncer) syncEntry(e *Entry, remoteETags map[string]string) bool {
+	index := s.tm.FindIndexByID(e.TaskID)
+	remoteETag, onServer := remoteETags[e.Href]
+	remoteChanged := onServer && remoteETag != e.ETag
+
+	switch {
+	case index < 0 && !onServer:
+		return false
+
+	case index < 0:
+		// タスクが削除された。サーバー側で変更されていなければサーバーからも削除する
+		if !remoteChanged {
+			err := s.client.Delete(s.ctx, e.Href, e.ETag)
+			if err == nil {
+				s.report.DeletedRemote++
+				return false
+			}
+			if !errors.Is(err, ErrPreconditionFailed) {
+				s.fail(err)
+				return true
+			}
+		}
+		// サーバー側の変更を優先して取り込み直す
+		task, err := s.parse(e.Href)
+		if err != nil {
+			s.fail(err)
+			return true
+		}
+		task = s.tm.ImportTask(task)
+		e.TaskID, e.ETag, e.Synced = task.ID, s.remote[e.Href].ETag, task.UpdatedAt
+		s.report.Pulled++
+		return true
+	}
+
+	task := s.tm.GetTaskByIndex(index)
+	localChanged := task.UpdatedAt.After(e.Synced)
+	if !onServer {
+		// サーバーで削除された。タスク側で変更されていれば書き込み直す
+		if localChanged {
+			s.push(task, e, "")
+			return true
+		}
+		s.tm.DeleteTask(index)
+		s.report.DeletedLocal++
+		return false
+	}
+
+	switch {
+	case remoteChanged && localChanged:
+		s.report.Conflicts++
+		remoteTask, err := s.parse(e.Href)
+		if err != nil {
+			s.fail(err)
+			return true
+		}
+		if remoteTask.UpdatedAt.After(task.UpdatedAt) {
+			s.pull(index, e, remoteTask)
+		} else {
+			s.push(task, e, remoteETag)
+		}
+	case remoteChanged:
+		remoteTask, err := s.parse(e.Href)
+		if err != nil {
+			s.fail(err)
+			return true
+		}
+		s.pull(index, e, remoteTask)
+	case localChanged:
+		s.push(task, e, e.ETag)
+	}
+	return true
+}
+
+// pull サーバーの内容をタスクに反映する
+func (s *syncer) pull(index int, e *Entry, remoteTask *models.Task) {
+	s.tm.ApplyTask(index, remoteTask)
+	e.ETag = s.remote[e.Href].ETag
+	e.Synced = s.tm.GetTaskByIndex(index).UpdatedAt
+	s.report.Pulled++
+}
+
+// push タスクをサーバーに書き込む。etag が空の場合は新規作成する
+func (s *syncer) push(task *models.Task, e *Entry, etag string) bool {
+	newETag, err := s.client.Put(s.ctx, e.Href, ical.FormatTodo(task), etag)
+	if err != nil {
+		s.fail(err)
+		return false
+	}
+	e.ETag, e.Synced = newETag, task.UpdatedAt
+	s.report.Pushed++
+	return true
+}
+
+// parse 取得したリソースの VTODO をタスクに変換する
+func (s *syncer) parse(href string) (*models.Task, error) {
+	r, ok := s.remote[href]
+	if !ok {
+		return nil, fmt.Errorf("%s: サーバーからタスクを取得できませんでした", href)
+	}
+	result, err := ical.Read(bytes.NewReader(r.Data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", href, err)
+	}
+	if len(result.Tasks) == 0 {
+		return nil, fmt.Errorf("%s: VTODO が含まれていません", href)
+	}
+	return result.Tasks[0], nil
+}
+
+func (s *syncer) fail(err error) {
+	s.report.Errors = append(s.report.Errors, err)
+}
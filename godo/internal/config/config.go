@@ -0,0 +1,256 @@
+// Package config は godo の設定ファイル（~/.config/godo/config.toml）を読み込む
+//
+// 設定ファイルは TOML で、セクション・文字列・真偽値・コメントを使って書く。
+//
+//	[display]
+//	date_format = "01/02 15:04"
+//	theme = "light"
+//
+// 設定の値は環境変数 GODO_<セクション>_<キー>（例: GODO_DISPLAY_THEME）で上書きできる。
+// 誤りのある設定はファイル名と行番号（環境変数の場合はその名前）付きのエラーにする。
+package config
+
+import (
+	"errors"
+	"fmt"
+	"godo/internal/ui"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileName 設定ファイルの名前
+const FileName = "config.toml"
+
+// DefaultPath 設定ファイルのパスを返す
+// 環境変数 GODO_CONFIG があればそのパス、なければ ~/.config/godo/config.toml（OS の設定ディレクトリ）
+func DefaultPath() string {
+	if path := os.Getenv("GODO_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "godo", FileName)
+}
+
+// Error 設定の誤り
+type Error struct {
+	Source string // 設定ファイルのパスか環境変数の名前
+	Line   int    // 設定ファイルの行番号（環境変数の場合は0）
+	Text   string // 誤りのある行
+	Key    string // 設定の名前（"section.key"、わからない場合は空）
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.Source, e.Err)
+	}
+	msg := fmt.Sprintf("%s:%d: %v", e.Source, e.Line, e.Err)
+	if text := strings.TrimSpace(e.Text); text != "" {
+		msg += "\n    " + text
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Load path の設定ファイルと環境変数から設定を読み込む
+// 設定ファイルがなければデフォルトの設定を使う。誤りのある設定はデフォルトのままにして、
+// 読み込めた設定と、すべての誤りをまとめたエラーを返す
+func Load(path string) (*Config, error) {
+	c := Default()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return c, err
+	}
+	errs := c.apply(path, string(data))
+	errs = append(errs, c.applyEnv()...)
+	errs = append(errs, c.check(path, string(data))...)
+	return c, join(errs)
+}
+
+// Validate 設定ファイルの内容を検証する（環境変数は使わない）
+func Validate(path, data string) error {
+	c := Default()
+	errs := c.apply(path, data)
+	errs = append(errs, c.check(path, data)...)
+	return join(errs)
+}
+
+// apply 設定ファイルの内容を反映する
+func (c *Config) apply(path, data string) []*Error {
+	doc, lineErrs := parse(data)
+	var errs []*Error
+	for _, le := range lineErrs {
+		errs = append(errs, &Error{Source: path, Line: le.line, Text: doc.lines[le.line-1], Err: le.err})
+	}
+	for _, e := range doc.entries {
+		fail := func(err error) {
+			errs = append(errs, &Error{Source: path, Line: e.line, Text: doc.lines[e.line-1], Key: e.name(), Err: err})
+		}
+		s := lookup(e.name())
+		if s == nil {
+			fail(unknownError(e.name()))
+			continue
+		}
+		if e.kind != s.kind {
+			fail(fmt.Errorf("%s は%sで指定してください（例: %s = %s）", s.name, s.kind, s.key(), s.literal(s.def)))
+			continue
+		}
+		if err := s.set(c, e.value); err != nil {
+			fail(fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errs
+}
+
+// applyEnv 環境変数の設定を反映する
+func (c *Config) applyEnv() []*Error {
+	var errs []*Error
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env())
+		if !ok {
+			continue
+		}
+		if err := s.set(c, value); err != nil {
+			errs = append(errs, &Error{Source: "環境変数 " + s.env(), Key: s.name, Err: err})
+		}
+	}
+	return errs
+}
+
+// check 複数の設定にまたがる誤りを検証する
+// 誤りの元になった設定が設定ファイルにあればその行を示す
+func (c *Config) check(path, data string) []*Error {
+	err := c.Keys.Validate()
+	if err == nil {
+		return nil
+	}
+	name := ""
+	var keyErr *ui.KeyError
+	if errors.As(err, &keyErr) {
+		name = "keys." + keyErr.Action
+	}
+	doc, _ := parse(data)
+	if e, ok := doc.lookup(name); ok {
+		return []*Error{{Source: path, Line: e.line, Text: doc.lines[e.line-1], Key: name, Err: err}}
+	}
+	if s := lookup(name); s != nil {
+		if _, ok := os.LookupEnv(s.env()); ok {
+			return []*Error{{Source: "環境変数 " + s.env(), Key: name, Err: err}}
+		}
+	}
+	return []*Error{{Source: path, Key: name, Err: err}}
+}
+
+// unknownError 知らない設定の名前のエラー
+func unknownError(name string) error {
+	if !strings.Contains(name, ".") {
+		return fmt.Errorf("%s はセクションの外にあります（[セクション] の下に書いてください）", name)
+	}
+	return fmt.Errorf("不明な設定です: %s（設定できるのは %s）", name, strings.Join(Names(), ", "))
+}
+
+// join エラーを設定ファイルの行の順にまとめる（なければ nil）
+func join(errs []*Error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line == 0 || errs[j].Line == 0 {
+			return errs[j].Line == 0 && errs[i].Line != 0
+		}
+		return errs[i].Line < errs[j].Line
+	})
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return errors.Join(list...)
+}
+
+// Get 名前（"section.key"）の設定の値を文字列で返す
+func (c *Config) Get(name string) (string, error) {
+	s := lookup(name)
+	if s == nil {
+		return "", unknownError(name)
+	}
+	return s.get(c), nil
+}
+
+// Set path の設定ファイルの name の値を value に書き換える
+// 値が不正な場合は書き換えずにエラーを返す。設定ファイルや親のディレクトリがなければ作成する
+func Set(path, name, value string) error {
+	s := lookup(name)
+	if s == nil {
+		return unknownError(name)
+	}
+	if err := s.set(Default(), value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	doc, _ := parse(string(data))
+	updated := doc.set(s.section(), s.key(), s.literal(value))
+
+	// 他の設定と組み合わせて誤りになる場合（キーの重複など）も書き換えない
+	// 元からある誤りは godo config edit で直せるように、新しく増える誤りだけを調べる
+	existing := map[string]bool{}
+	for _, e := range unwrapErrors(Validate(path, string(data))) {
+		existing[e.Err.Error()] = true
+	}
+	for _, e := range unwrapErrors(Validate(path, updated)) {
+		if !existing[e.Err.Error()] {
+			return e
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(updated), 0644)
+}
+
+// unwrapErrors Load や Validate が返したエラーを1つずつに分ける
+func unwrapErrors(err error) []*Error {
+	var errs []*Error
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, unwrapErrors(err)...)
+		}
+		return errs
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// Template 設定ファイルのひな形（すべての設定をデフォルト値でコメントアウトしたもの）を返す
+func Template() string {
+	var b strings.Builder
+	b.WriteString("# godo の設定ファイル\n")
+	b.WriteString("# 行頭の # を外して値を変更してください。環境変数 GODO_<セクション>_<キー>（例: GODO_DISPLAY_THEME）でも上書きできます\n")
+	section := ""
+	for _, s := range settings {
+		if s.section() != section {
+			section = s.section()
+			fmt.Fprintf(&b, "\n[%s]\n", section)
+		}
+		fmt.Fprintf(&b, "# %s\n# %s = %s\n", s.help, s.key(), s.literal(s.def))
+	}
+	return b.String()
+}
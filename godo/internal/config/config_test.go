@@ -0,0 +1,235 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"godo/internal/storage"
+	"godo/pkg/tasks"
+)
+
+// writeConfig 一時ディレクトリに設定ファイルを置き、そのパスを返す
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	path := filepath.Join(home, ".config", "godo", FileName)
+	if content != "" {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestLoad_defaultsWithoutFile(t *testing.T) {
+	path := writeConfig(t, "")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := os.UserHomeDir()
+	if c.Storage.Dir != filepath.Join(home, ".godo") || c.Storage.Mode != storage.SnapshotMode {
+		t.Errorf("storage = %+v", c.Storage)
+	}
+	if c.List.Sort != tasks.SortByID || c.Display.DateFormat != "2006-01-02 15:04" || c.Display.Theme != "default" {
+		t.Errorf("list = %+v, display = %+v", c.List, c.Display)
+	}
+	if !c.Hooks.Enabled || c.Hooks.Timeout != 5*time.Second || c.HooksDir() != filepath.Join(home, ".godo", "hooks") {
+		t.Errorf("hooks = %+v", c.Hooks)
+	}
+	if c.Keys.Toggle != "enter" || c.Archive.Auto != 0 {
+		t.Errorf("keys = %+v, archive = %+v", c.Keys, c.Archive)
+	}
+}
+
+func TestLoad_file(t *testing.T) {
+	path := writeConfig(t, `# godo の設定
+[storage]
+dir = "~/tasks"   # 同期するフォルダ
+mode = 'journal'
+
+[list]
+sort = "due"
+
+[display]
+date_format = "01/02 15:04"
+theme = "mono"
+
+[keys]
+quit = "ctrl+c"
+
+[archive]
+auto = "30d"
+
+[hooks]
+enabled = false
+timeout = "2s"
+`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := os.UserHomeDir()
+	if c.Storage.Dir != filepath.Join(home, "tasks") || c.Storage.Mode != storage.JournalMode {
+		t.Errorf("storage = %+v", c.Storage)
+	}
+	if c.List.Sort != tasks.SortByDue || c.Display.DateFormat != "01/02 15:04" || c.Display.Theme != "mono" {
+		t.Errorf("list = %+v, display = %+v", c.List, c.Display)
+	}
+	if c.Keys.Quit != "ctrl+c" || c.Archive.Auto != 30*24*time.Hour {
+		t.Errorf("keys = %+v, archive = %+v", c.Keys, c.Archive)
+	}
+	if c.Hooks.Enabled || c.Hooks.Timeout != 2*time.Second || c.HooksDir() != filepath.Join(home, "tasks", "hooks") {
+		t.Errorf("hooks = %+v", c.Hooks)
+	}
+	if value, _ := c.Get("storage.dir"); value != "~/tasks" {
+		t.Errorf("Get(storage.dir) = %q", value)
+	}
+}
+
+func TestLoad_errorsPointToLines(t *testing.T) {
+	path := writeConfig(t, `[display]
+theme = "dark"
+date_format = 12
+
+[list]
+sort = priority
+order = "due"
+
+[keys]
+add = "x"
+quit = "x"
+`)
+	c, err := Load(path)
+	if err == nil {
+		t.Fatal("誤りのある設定がエラーになりません")
+	}
+	want := []string{
+		path + `:2: display.theme: テーマが不正です: "dark"`,
+		"    theme = \"dark\"",
+		path + ":3: display.date_format は文字列で指定してください",
+		path + `:6: list.sort: 値の形式が不正です: priority（文字列は "..." で囲んでください）`,
+		path + ":7: 不明な設定です: list.order",
+		path + `:11: keys.quit: キー "x" は add にも割り当てられています`,
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("エラーに %q がありません:\n%v", w, err)
+		}
+	}
+	// 誤りのある設定はデフォルトのまま
+	if c.Display.Theme != "default" || c.List.Sort != tasks.SortByID {
+		t.Errorf("display = %+v, list = %+v", c.Display, c.List)
+	}
+	// 行の順に並べる
+	if strings.Index(err.Error(), ":2:") > strings.Index(err.Error(), ":11:") {
+		t.Errorf("行の順になっていません:\n%v", err)
+	}
+}
+
+func TestLoad_syntaxErrors(t *testing.T) {
+	for content, want := range map[string]string{
+		"[display\n":                                       ":1: セクションの見出しが ] で閉じられていません",
+		"[[display]]\n":                                    ":1: テーブルの配列",
+		"[display]\ntheme\n":                               ":2: 行の形式が不正です",
+		"[display]\ntheme = \"mono\n":                      ":2: display.theme: 文字列が \" で閉じられていません",
+		"[display]\ntheme = \"mono\" extra\n":              ":2: display.theme: 値の後に余計な文字があります",
+		"[display]\ntheme = \"mono\"\ntheme = \"light\"\n": ":3: display.theme は 2 行目にもあります",
+		"theme = \"mono\"\n":                               ":1: theme はセクションの外にあります",
+	} {
+		path := writeConfig(t, content)
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), path+want) {
+			t.Errorf("%q: err = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestLoad_envOverrides(t *testing.T) {
+	path := writeConfig(t, "[display]\ntheme = \"mono\"\n")
+	t.Setenv("GODO_DISPLAY_THEME", "light")
+	t.Setenv("GODO_HOOKS_ENABLED", "false")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Display.Theme != "light" || c.Hooks.Enabled {
+		t.Errorf("display = %+v, hooks = %+v", c.Display, c.Hooks)
+	}
+
+	t.Setenv("GODO_ARCHIVE_AUTO", "someday")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "環境変数 GODO_ARCHIVE_AUTO: 期間の形式が不正です") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	path := writeConfig(t, "# 自分の設定\n[display]\ntheme = \"mono\" # 白黒\n\n[hooks]\nenabled = true\n")
+
+	for _, s := range [][2]string{
+		{"display.theme", "light"},
+		{"display.date_format", "01/02"},
+		{"list.sort", "priority"},
+		{"hooks.enabled", "false"},
+	} {
+		if err := Set(path, s[0], s[1]); err != nil {
+			t.Fatalf("Set(%s) = %v", s[0], err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	want := "# 自分の設定\n[display]\ntheme = \"light\" # 白黒\ndate_format = \"01/02\"\n\n[hooks]\nenabled = false\n\n[list]\nsort = \"priority\"\n"
+	if string(data) != want {
+		t.Errorf("設定ファイル:\n%s\nwant:\n%s", data, want)
+	}
+
+	// 不正な値や他の設定と重複するキーは書き込まない
+	for _, s := range [][2]string{
+		{"display.theme", "dark"},
+		{"display.colour", "red"},
+		{"keys.add", "enter"},
+		{"storage.dir", "relative/path"},
+	} {
+		if err := Set(path, s[0], s[1]); err == nil {
+			t.Errorf("Set(%s, %s) succeeded", s[0], s[1])
+		}
+	}
+	if after, _ := os.ReadFile(path); string(after) != want {
+		t.Errorf("不正な値で設定ファイルが変わりました:\n%s", after)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	path := writeConfig(t, "")
+	if err := Validate(path, Template()); err != nil {
+		t.Fatalf("ひな形が不正です: %v", err)
+	}
+	// コメントを外してもデフォルトの設定のまま
+	var lines []string
+	for _, line := range strings.Split(Template(), "\n") {
+		if strings.HasPrefix(line, "# ") && strings.Contains(line, " = ") {
+			line = strings.TrimPrefix(line, "# ")
+		}
+		lines = append(lines, line)
+	}
+	if err := Validate(path, strings.Join(lines, "\n")); err != nil {
+		t.Fatalf("コメントを外したひな形が不正です: %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": 0, "0": 0, "7d": 7 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		if got, err := ParseAge(value); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{"x", "-1d", "3y"} {
+		if _, err := ParseAge(value); err == nil {
+			t.Errorf("ParseAge(%q) succeeded", value)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// kind 設定ファイルの値の種類
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "真偽値"
+	case kindInt:
+		return "整数"
+	}
+	return "文字列"
+}
+
+// entry 設定ファイルの key = value の1行
+type entry struct {
+	section string
+	key     string
+	value   string // 文字列は引用符を外してエスケープを戻した値
+	kind    kind
+	comment string // 行末のコメント（# から）
+	line    int    // 1から数えた行番号
+}
+
+// name "section.key" の形式の名前
+func (e entry) name() string {
+	if e.section == "" {
+		return e.key
+	}
+	return e.section + "." + e.key
+}
+
+// document 読み込んだ設定ファイル
+// 書き換えるときにコメントや空行を残すため、元の行も持つ
+type document struct {
+	lines    []string
+	newline  string
+	entries  []entry
+	sections map[string]int // セクション名 → 見出しの行番号
+}
+
+// lineError 設定ファイルの行の誤り
+type lineError struct {
+	line int
+	err  error
+}
+
+var (
+	bareKey   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	integer   = regexp.MustCompile(`^[+-]?[0-9][0-9_]*$`)
+	errSyntax = errors.New("行の形式が不正です（[セクション] か キー = 値 を書いてください）")
+)
+
+// parse 設定ファイルを読み込む
+// 対応するのは TOML のうちセクション・文字列・真偽値・整数・コメントで、誤りのある行は行番号付きで返す
+func parse(data string) (*document, []lineError) {
+	doc := &document{newline: "\n", sections: map[string]int{}}
+	if strings.Contains(data, "\r\n") {
+		doc.newline = "\r\n"
+	}
+	data = strings.TrimPrefix(data, "\ufeff")
+	if data != "" {
+		doc.lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(data, "\r\n", "\n"), "\n"), "\n")
+	}
+
+	var errs []lineError
+	seen := map[string]int{}
+	section := ""
+	for i, raw := range doc.lines {
+		n := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, err := parseSection(line)
+			if err != nil {
+				errs = append(errs, lineError{n, err})
+				continue
+			}
+			if first, ok := doc.sections[name]; ok {
+				errs = append(errs, lineError{n, fmt.Errorf("セクション [%s] は %d 行目にもあります", name, first)})
+			} else {
+				doc.sections[name] = n
+			}
+			section = name
+			continue
+		}
+
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			errs = append(errs, lineError{n, errSyntax})
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !bareKey.MatchString(key) {
+			errs = append(errs, lineError{n, fmt.Errorf("キーの形式が不正です: %q（英数字・_・- だけを使えます）", key)})
+			continue
+		}
+		e := entry{section: section, key: key, line: n}
+		if err := parseValue(strings.TrimSpace(rest), &e); err != nil {
+			errs = append(errs, lineError{n, fmt.Errorf("%s: %w", e.name(), err)})
+			continue
+		}
+		if first, ok := seen[e.name()]; ok {
+			errs = append(errs, lineError{n, fmt.Errorf("%s は %d 行目にもあります", e.name(), first)})
+			continue
+		}
+		seen[e.name()] = n
+		doc.entries = append(doc.entries, e)
+	}
+	return doc, errs
+}
+
+// parseSection "[name]" の見出しを解釈する
+func parseSection(line string) (string, error) {
+	if strings.HasPrefix(line, "[[") {
+		return "", errors.New("テーブルの配列（[[...]]）には対応していません")
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", errors.New("セクションの見出しが ] で閉じられていません")
+	}
+	if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("セクションの見出しの後に余計な文字があります: %q", rest)
+	}
+	name := strings.TrimSpace(line[1:end])
+	if !bareKey.MatchString(name) {
+		return "", fmt.Errorf("セクション名の形式が不正です: %q", name)
+	}
+	return name, nil
+}
+
+// parseValue 値と行末のコメントを解釈する
+func parseValue(text string, e *entry) error {
+	var rest string
+	switch {
+	case text == "" || strings.HasPrefix(text, "#"):
+		return errors.New("値がありません")
+	case text[0] == '"':
+		value, n, err := parseBasicString(text)
+		if err != nil {
+			return err
+		}
+		e.value, e.kind, rest = value, kindString, text[n:]
+	case text[0] == '\'':
+		end := strings.IndexByte(text[1:], '\'')
+		if end < 0 {
+			return errors.New("文字列が ' で閉じられていません")
+		}
+		e.value, e.kind, rest = text[1:end+1], kindString, text[end+2:]
+	default:
+		word, comment, hasComment := strings.Cut(text, "#")
+		word = strings.TrimSpace(word)
+		switch {
+		case word == "true" || word == "false":
+			e.kind = kindBool
+		case integer.MatchString(word):
+			e.kind = kindInt
+		default:
+			return fmt.Errorf("値の形式が不正です: %s（文字列は \"...\" で囲んでください）", word)
+		}
+		e.value = word
+		if hasComment {
+			e.comment = "#" + comment
+		}
+		return nil
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("値の後に余計な文字があります: %q", rest)
+	}
+	e.comment = rest
+	return nil
+}
+
+// parseBasicString "..." の文字列を解釈し、値と閉じる引用符の次の位置を返す
+func parseBasicString(text string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(text) {
+				break
+			}
+			i++
+			switch text[i] {
+			case '"', '\\':
+				b.WriteByte(text[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'u':
+				if i+4 >= len(text) {
+					return "", 0, errors.New("\\u の後には16進数4桁が必要です")
+				}
+				r, err := strconv.ParseUint(text[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("\\u%s は不正なエスケープです", text[i+1:i+5])
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("\\%c は不正なエスケープです", text[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("文字列が \" で閉じられていません")
+}
+
+// quote 文字列を設定ファイルに書く形式にする
+func quote(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// lookup 名前（"section.key"）の行を返す
+func (d *document) lookup(name string) (entry, bool) {
+	for _, e := range d.entries {
+		if e.name() == name {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// set 名前の値を書き換えた設定ファイルの内容を返す
+// 既にある行は値だけを書き換え、なければセクションの最後（セクションもなければファイルの最後）に追加する
+func (d *document) set(section, key, literal string) string {
+	lines := append([]string{}, d.lines...)
+	line := key + " = " + literal
+	name := section + "." + key
+
+	if e, ok := d.lookup(name); ok {
+		raw := lines[e.line-1]
+		indent := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if e.comment != "" {
+			line += " " + e.comment
+		}
+		lines[e.line-1] = indent + line
+	} else if header, ok := d.sections[section]; ok {
+		// セクションの最後の設定の次の行に追加する
+		at := header
+		for _, e := range d.entries {
+			if e.section == section && e.line > at {
+				at = e.line
+			}
+		}
+		lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	} else {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", line)
+	}
+	return strings.Join(lines, d.newline) + d.newline
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"godo/internal/storage"
+	"godo/internal/ui"
+	"godo/pkg/tasks"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config godo の設定
+type Config struct {
+	Storage StorageConfig
+	List    ListConfig
+	Display DisplayConfig
+	Keys    ui.KeyMap
+	Archive ArchiveConfig
+	Hooks   HooksConfig
+
+	values map[string]string // 設定の名前ごとの値（Get で返す）
+}
+
+// StorageConfig タスクの保存先
+type StorageConfig struct {
+	Dir  string       // タスクを保存するディレクトリ
+	Mode storage.Mode // 保存形式
+}
+
+// ListConfig godo list の設定
+type ListConfig struct {
+	Sort tasks.SortOrder // --sort を省略したときの並び順
+}
+
+// DisplayConfig TUI の表示
+type DisplayConfig struct {
+	DateFormat string // 日時の表示形式（Go の time.Format のレイアウト）
+	Theme      string // 配色の名前（ui.Themes）
+}
+
+// ArchiveConfig アーカイブの方針
+type ArchiveConfig struct {
+	Auto time.Duration // 完了してからこの期間が経過したタスクを保存時にアーカイブする（0で無効）
+}
+
+// HooksConfig フックの設定
+type HooksConfig struct {
+	Enabled bool
+	Dir     string        // フックを置くディレクトリ（省略すると保存先の hooks）
+	Timeout time.Duration // 1回の実行を打ち切るまでの時間
+}
+
+// Default デフォルトの設定を返す
+func Default() *Config {
+	c := &Config{Storage: StorageConfig{Dir: storage.DefaultDir()}, Keys: ui.DefaultKeyMap(), values: map[string]string{}}
+	for _, s := range settings {
+		// ホームディレクトリがわからない場合の storage.dir は storage.DefaultDir のままにする
+		s.set(c, s.def)
+	}
+	return c
+}
+
+// HooksDir フックを置くディレクトリを返す
+func (c *Config) HooksDir() string {
+	if c.Hooks.Dir != "" {
+		return c.Hooks.Dir
+	}
+	return filepath.Join(c.Storage.Dir, "hooks")
+}
+
+// setting 設定ファイルに書ける設定
+type setting struct {
+	name  string // "section.key"
+	kind  kind
+	def   string // デフォルト値
+	help  string // ひな形に書く説明
+	parse func(c *Config, value string) error
+}
+
+// section セクション名
+func (s *setting) section() string {
+	section, _, _ := strings.Cut(s.name, ".")
+	return section
+}
+
+// key セクション内のキー
+func (s *setting) key() string {
+	_, key, _ := strings.Cut(s.name, ".")
+	return key
+}
+
+// env 値を上書きする環境変数の名前
+func (s *setting) env() string {
+	return "GODO_" + strings.ToUpper(strings.ReplaceAll(s.name, ".", "_"))
+}
+
+// literal 値を設定ファイルに書く形式にする
+func (s *setting) literal(value string) string {
+	if s.kind == kindBool {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b)
+		}
+	}
+	return quote(value)
+}
+
+// set 値を検証して c に設定する（不正な値の場合は c を変更しない）
+func (s *setting) set(c *Config, value string) error {
+	parsed := *c
+	if err := s.parse(&parsed, value); err != nil {
+		return err
+	}
+	*c = parsed
+	c.values[s.name] = value
+	return nil
+}
+
+// get c の値を返す
+func (s *setting) get(c *Config) string {
+	return c.values[s.name]
+}
+
+// lookup 名前の設定を返す（なければ nil）
+func lookup(name string) *setting {
+	for _, s := range settings {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Names 設定できる名前を返す
+func Names() []string {
+	names := make([]string, len(settings))
+	for i, s := range settings {
+		names[i] = s.name
+	}
+	return names
+}
+
+// settings 設定できる項目（ひな形や一覧はこの順に並べる）
+var settings = slices.Concat([]*setting{
+	{
+		name: "storage.dir", def: "~/.godo",
+		help: "タスク・アーカイブ・変更履歴を保存するディレクトリ",
+		parse: func(c *Config, value string) (err error) {
+			c.Storage.Dir, err = expandPath(value)
+			return err
+		},
+	},
+	{
+		name: "storage.mode", def: string(storage.SnapshotMode),
+		help: "保存形式: snapshot または journal",
+		parse: func(c *Config, value string) (err error) {
+			c.Storage.Mode, err = storage.ParseMode(value)
+			return err
+		},
+	},
+	{
+		name: "list.sort", def: string(tasks.SortByID),
+		help: "godo list の並び順: " + strings.Join(sortOrderNames(), ", "),
+		parse: func(c *Config, value string) (err error) {
+			c.List.Sort, err = tasks.ParseSortOrder(value)
+			return err
+		},
+	},
+	{
+		name: "display.date_format", def: ui.DefaultDateFormat,
+		help: "TUI の日時の表示形式（Go のレイアウト。2006=年 01=月 02=日 15=時 04=分 05=秒）",
+		parse: func(c *Config, value string) error {
+			if err := validateDateFormat(value); err != nil {
+				return err
+			}
+			c.Display.DateFormat = value
+			return nil
+		},
+	},
+	{
+		name: "display.theme", def: "default",
+		help: "TUI の配色: " + strings.Join(ui.ThemeNames(), ", "),
+		parse: func(c *Config, value string) error {
+			if _, err := ui.LookupTheme(value); err != nil {
+				return err
+			}
+			c.Display.Theme = value
+			return nil
+		},
+	},
+}, keySettings(), []*setting{
+	{
+		name: "archive.auto", def: "0",
+		help: "完了してから指定期間が経過したタスクを保存時にアーカイブする（例: 30d、0で無効）",
+		parse: func(c *Config, value string) (err error) {
+			c.Archive.Auto, err = ParseAge(value)
+			return err
+		},
+	},
+	{
+		name: "hooks.enabled", kind: kindBool, def: "true",
+		help: "タスクを保存する前にフックを実行する",
+		parse: func(c *Config, value string) (err error) {
+			c.Hooks.Enabled, err = strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("真偽値の形式が不正です: %q (true または false)", value)
+			}
+			return nil
+		},
+	},
+	{
+		name: "hooks.dir", def: "",
+		help: "フックを置くディレクトリ（空なら storage.dir の hooks）",
+		parse: func(c *Config, value string) (err error) {
+			if value == "" {
+				c.Hooks.Dir = ""
+				return nil
+			}
+			c.Hooks.Dir, err = expandPath(value)
+			return err
+		},
+	},
+	{
+		name: "hooks.timeout", def: "5s",
+		help: "フックの1回の実行を打ち切るまでの時間（例: 5s）",
+		parse: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("時間の形式が不正です: %q (例: 5s, 1m)", value)
+			}
+			c.Hooks.Timeout = d
+			return nil
+		},
+	},
+})
+
+// keySettings TUI のキー割り当ての設定（keys.<操作>）
+func keySettings() []*setting {
+	defaults := ui.DefaultKeyMap()
+	var list []*setting
+	for i, action := range defaults.KeyActions() {
+		list = append(list, &setting{
+			name: "keys." + action.Name, def: *action.Key,
+			help: "TUI で" + keyHelp[action.Name] + "キー",
+			parse: func(c *Config, value string) error {
+				if strings.TrimSpace(value) == "" {
+					return errors.New("キーが空です")
+				}
+				*c.Keys.KeyActions()[i].Key = value
+				return nil
+			},
+		})
+	}
+	return list
+}
+
+// keyHelp ひな形に書くキー割り当ての説明
+var keyHelp = map[string]string{
+	"toggle":   "タスクの完了/未完了を切り替える",
+	"add":      "タスクを追加する",
+	"edit":     "タスクを編集する",
+	"delete":   "タスクを削除する",
+	"timer":    "タイマーを開始/停止する",
+	"pomodoro": "ポモドーロを開始/停止する",
+	"board":    "ボード表示を切り替える",
+	"agenda":   "アジェンダ表示を切り替える",
+	"archive":  "完了済みのタスクをアーカイブする",
+	"history":  "変更履歴の表示を切り替える",
+	"up":       "上のタスクを選択する",
+	"down":     "下のタスクを選択する",
+	"quit":     "終了する",
+}
+
+// sortOrderNames 並び順の名前の一覧
+func sortOrderNames() []string {
+	names := make([]string, len(tasks.SortOrders))
+	for i, order := range tasks.SortOrders {
+		names[i] = string(order)
+	}
+	return names
+}
+
+// expandPath 先頭の ~ をホームディレクトリにし、絶対パスか検証する
+func expandPath(value string) (string, error) {
+	path := value
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("ホームディレクトリがわかりません: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("絶対パスか ~/ から始まるパスを指定してください: %q", value)
+	}
+	return filepath.Clean(path), nil
+}
+
+// validateDateFormat 日時の表示形式に日付か時刻の要素があるか検証する
+func validateDateFormat(layout string) error {
+	sample := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if strings.TrimSpace(layout) == "" || sample.Format(layout) == layout {
+		return fmt.Errorf("日時の表示形式が不正です: %q (Go のレイアウトで指定してください。例: \"2006-01-02 15:04\", \"01/02 15:04\")", layout)
+	}
+	return nil
+}
+
+// ParseAge "7d" や "2w"、"36h" のような期間の指定を解釈する
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("期間の形式が不正です: %q (例: 7d, 2w, 36h)", value)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("期間の形式が不正です: %q (例: 7d, 2w, 36h)", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,100 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 変更の配信を受け取るクライアント
+// 接続が切れると最後に受け取ったイベントIDを Last-Event-ID に指定して接続し直す
+type Client struct {
+	URL   string // 例: http://127.0.0.1:8080/api/events
+	Token string
+	HTTP  *http.Client
+	Retry time.Duration // 接続し直すまでの待ち時間
+
+	LastID int64 // 最後に受け取ったイベントID（0なら接続した時点より後の変更から受け取る）
+}
+
+// NewClient クライアントを作成する
+func NewClient(url, token string) *Client {
+	return &Client{URL: url, Token: token, HTTP: &http.Client{}, Retry: time.Second}
+}
+
+// Subscribe ctx が終わるまで変更を受け取り、1件ごとに fn を呼ぶ
+// 認証に失敗した場合はエラーを返して終了し、それ以外のエラーでは接続し直す
+func (c *Client) Subscribe(ctx context.Context, fn func(Change)) error {
+	for {
+		err := c.stream(ctx, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errUnauthorized) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Retry):
+		}
+	}
+}
+
+var errUnauthorized = errors.New("変更の配信の認証に失敗しました")
+
+// stream 1回の接続で変更を受け取る
+func (c *Client) stream(ctx context.Context, fn func(Change)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.LastID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(c.LastID, 10))
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return errUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("変更の配信に接続できません: %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			// id と event は data の JSON にも含まれるので data だけを読む
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if data.Len() == 0 {
+			continue
+		}
+		var change Change
+		if err := json.Unmarshal([]byte(data.String()), &change); err == nil {
+			c.LastID = change.ID
+			fn(change)
+		}
+		data.Reset()
+	}
+	return scanner.Err()
+}
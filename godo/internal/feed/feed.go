@@ -0,0 +1,152 @@
+// Package feed はタスクの変更を Server-Sent Events で配信する
+//
+// 変更は TaskManager の操作ごとに記録される変更履歴（~/.godo/history.jsonl）から作る。
+// 履歴ファイルの行番号をイベントIDとして使うので、接続が切れたクライアントは
+// Last-Event-ID を送ると、切れている間の変更から受け取り直せる。
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"os"
+	"sync"
+)
+
+// 配信する変更の種類
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+)
+
+// Change 配信する変更1件
+type Change struct {
+	ID     int64        `json:"id"`   // イベントID（履歴ファイルの行番号）
+	Type   string       `json:"type"` // created / updated / deleted
+	TaskID int          `json:"task_id"`
+	Event  models.Event `json:"event"`          // 元になった変更履歴
+	Task   *models.Task `json:"task,omitempty"` // 変更後のタスク（削除の場合は削除前のタスク）
+}
+
+// Kind 変更履歴の種類を配信する変更の種類に変換する
+// アーカイブは一覧から消えるので削除、アーカイブからの復元は作成として扱う
+func Kind(e models.Event) string {
+	switch e.Type {
+	case models.EventCreated, models.EventRestored:
+		return Created
+	case models.EventDeleted, models.EventArchived:
+		return Deleted
+	}
+	return Updated
+}
+
+// History 変更履歴ファイルから変更を読み込む
+// 前回読み込んだ位置を覚えておき、追記された分だけを読む
+type History struct {
+	Path string
+
+	mu     sync.Mutex
+	line   int64 // offset までに読んだ行数
+	offset int64
+}
+
+// NewHistory 変更履歴ファイルを読む History を作成する
+func NewHistory(path string) *History {
+	return &History{Path: path}
+}
+
+// Since イベントIDが after より後の変更履歴と、そのイベントIDを返す
+// last は読み込んだ最後の行のイベントID（変更がなければ after 以上の現在の末尾）
+func (h *History) Since(after int64) (events []models.Event, ids []int64, last int64, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	file, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		return nil, nil, after, nil
+	}
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("履歴ファイルを開けませんでした: %w", err)
+	}
+	defer file.Close()
+
+	// 覚えている位置より前から読む場合と、ファイルが短くなった場合は先頭から読み直す
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if after < h.line || info.Size() < h.offset {
+		h.line, h.offset = 0, 0
+	}
+	if _, err := file.Seek(h.offset, io.SeekStart); err != nil {
+		return nil, nil, 0, err
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	line, offset := h.line, h.offset
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(data) == 0 || data[len(data)-1] != '\n' {
+			// 書き込み途中の行は次回に読む
+			break
+		}
+		line++
+		offset += int64(len(data))
+		if line > after {
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				var e models.Event
+				if jsonErr := json.Unmarshal(data, &e); jsonErr == nil {
+					events = append(events, e)
+					ids = append(ids, line)
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	h.line, h.offset = line, offset
+	return events, ids, max(line, after), nil
+}
+
+// Latest 現在の最後のイベントIDを返す
+func (h *History) Latest() (int64, error) {
+	h.mu.Lock()
+	after := h.line
+	h.mu.Unlock()
+	_, _, last, err := h.Since(after)
+	return last, err
+}
+
+// Changes イベントIDが after より後の変更を返す
+// current を指定すると、変更があったときに現在のタスクを読み込み、作成・変更されたタスクの内容を添える
+func (h *History) Changes(after int64, current func() ([]*models.Task, error)) ([]Change, int64, error) {
+	events, ids, last, err := h.Since(after)
+	if err != nil {
+		return nil, after, err
+	}
+	byID := map[int]*models.Task{}
+	if current != nil && len(events) > 0 {
+		tasks, err := current()
+		if err != nil {
+			return nil, after, err
+		}
+		for _, task := range tasks {
+			byID[task.ID] = task
+		}
+	}
+	changes := make([]Change, 0, len(events))
+	for i, e := range events {
+		c := Change{ID: ids[i], Type: Kind(e), TaskID: e.TaskID, Event: e, Task: e.Task}
+		c.Event.Task = nil
+		if task, ok := byID[e.TaskID]; ok && c.Type != Deleted {
+			c.Task = task
+		}
+		changes = append(changes, c)
+	}
+	return changes, last, nil
+}
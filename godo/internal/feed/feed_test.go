@@ -0,0 +1,222 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"godo/internal/api"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appendEvents 変更履歴ファイルに追記する（storage.AppendHistory と同じ形式）
+func appendEvents(t *testing.T, path string, events ...models.Event) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestKind(t *testing.T) {
+	tests := map[models.EventType]string{
+		models.EventCreated:         Created,
+		models.EventRestored:        Created,
+		models.EventRenamed:         Updated,
+		models.EventToggled:         Updated,
+		models.EventPriorityChanged: Updated,
+		models.EventDeleted:         Deleted,
+		models.EventArchived:        Deleted,
+	}
+	for typ, want := range tests {
+		if got := Kind(models.Event{Type: typ}); got != want {
+			t.Errorf("Kind(%s) = %s, want %s", typ, got, want)
+		}
+	}
+}
+
+func TestHistorySince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h := NewHistory(path)
+
+	if events, _, last, err := h.Since(0); err != nil || len(events) != 0 || last != 0 {
+		t.Fatalf("missing file: %v, %d, %v", events, last, err)
+	}
+
+	appendEvents(t, path, models.Event{TaskID: 1, Type: models.EventCreated}, models.Event{TaskID: 1, Type: models.EventRenamed})
+	events, ids, last, err := h.Since(0)
+	if err != nil || len(events) != 2 || ids[1] != 2 || last != 2 {
+		t.Fatalf("Since(0) = %v, %v, %d, %v", events, ids, last, err)
+	}
+
+	// 書き込み途中の行はまだ読まない
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"task_id":2,"type":"cre`)
+	if events, _, last, _ := h.Since(2); len(events) != 0 || last != 2 {
+		t.Fatalf("partial line: %v, %d", events, last)
+	}
+	f.WriteString(`ated"}` + "\n")
+	f.Close()
+	events, ids, last, _ = h.Since(2)
+	if len(events) != 1 || events[0].TaskID != 2 || ids[0] != 3 || last != 3 {
+		t.Fatalf("Since(2) = %v, %v, %d", events, ids, last)
+	}
+
+	// 前の位置からも読み直せる
+	if events, _, _, _ := h.Since(1); len(events) != 2 {
+		t.Fatalf("Since(1) = %v", events)
+	}
+	if latest, _ := h.Latest(); latest != 3 {
+		t.Fatalf("Latest = %d", latest)
+	}
+}
+
+func TestHandlerStreamsAndResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	appendEvents(t, path, models.Event{TaskID: 1, Type: models.EventCreated, New: "接続前のタスク"})
+
+	current := func() ([]*models.Task, error) {
+		return []*models.Task{{ID: 2, Title: "現在のタイトル"}}, nil
+	}
+	handler := NewHandler(NewHistory(path), current, "secret")
+	handler.Interval = 10 * time.Millisecond
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, "secret")
+	client.Retry = 10 * time.Millisecond
+	received := make(chan Change, 10)
+	go client.Subscribe(ctx, func(c Change) { received <- c })
+
+	next := func() Change {
+		t.Helper()
+		select {
+		case c := <-received:
+			return c
+		case <-ctx.Done():
+			t.Fatal("変更を受け取れませんでした")
+		}
+		return Change{}
+	}
+
+	// 接続した時点より後の変更だけを受け取る
+	time.Sleep(50 * time.Millisecond)
+	appendEvents(t, path,
+		models.Event{TaskID: 2, Type: models.EventCreated, New: "a"},
+		models.Event{TaskID: 2, Type: models.EventRenamed, Old: "a", New: "現在のタイトル"},
+		models.Event{TaskID: 1, Type: models.EventDeleted, Task: &models.Task{ID: 1, Title: "接続前のタスク"}},
+	)
+	created, updated, deleted := next(), next(), next()
+	if created.ID != 2 || created.Type != Created || created.Task == nil || created.Task.Title != "現在のタイトル" {
+		t.Errorf("created = %+v", created)
+	}
+	if updated.ID != 3 || updated.Type != Updated || updated.Event.New != "現在のタイトル" {
+		t.Errorf("updated = %+v", updated)
+	}
+	if deleted.ID != 4 || deleted.Type != Deleted || deleted.Task == nil || deleted.Task.Title != "接続前のタスク" {
+		t.Errorf("deleted = %+v", deleted)
+	}
+	if client.LastID != 4 {
+		t.Errorf("LastID = %d", client.LastID)
+	}
+
+	// Last-Event-ID を指定すると、その後の変更から受け取り直せる
+	resumed := NewClient(srv.URL, "secret")
+	resumed.LastID = 2
+	again := make(chan Change, 10)
+	go resumed.Subscribe(ctx, func(c Change) { again <- c })
+	for _, want := range []int64{3, 4} {
+		select {
+		case c := <-again:
+			if c.ID != want {
+				t.Errorf("resumed ID = %d, want %d", c.ID, want)
+			}
+		case <-ctx.Done():
+			t.Fatal("変更を受け取り直せませんでした")
+		}
+	}
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	srv := httptest.NewServer(NewHandler(NewHistory(path), nil, "secret"))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "wrong").Subscribe(context.Background(), func(Change) {}); err == nil {
+		t.Fatal("expected authentication error")
+	}
+
+	// EventSource 用に token パラメータでも認証できる
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?token=secret", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream; charset=utf-8" {
+		t.Fatalf("status = %d, content-type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestHandlerStreamsPatchedContextsAndMeta(t *testing.T) {
+	ts := storage.NewTaskStorageAt(t.TempDir())
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	if err := ts.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	tasks := api.NewAccess(api.NewStorageStore(ts, models.DefaultWorkflow()))
+	apiSrv := httptest.NewServer(api.NewServer(tasks, ""))
+	defer apiSrv.Close()
+
+	handler := NewHandler(NewHistory(ts.GetHistoryPath()), tasks.Tasks, "")
+	handler.Interval = 10 * time.Millisecond
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, "")
+	client.Retry = 10 * time.Millisecond
+	received := make(chan Change, 10)
+	go client.Subscribe(ctx, func(c Change) { received <- c })
+	time.Sleep(50 * time.Millisecond)
+
+	// API でしか変更できない項目の変更も変更履歴を通じて配信される
+	contexts := []string{"office"}
+	meta := map[string]string{"uid": "u1"}
+	if _, _, err := api.NewClient(apiSrv.URL, "").Patch(ctx, 1, api.Patch{Contexts: &contexts, Meta: &meta}, ""); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]bool{}
+	for len(fields) < 2 {
+		select {
+		case c := <-received:
+			if c.Type != Updated || c.Task == nil || c.Task.Contexts[0] != "office" {
+				t.Fatalf("change = %+v", c)
+			}
+			fields[c.Event.Field] = true
+		case <-ctx.Done():
+			t.Fatalf("変更を受け取れませんでした: %v", fields)
+		}
+	}
+	if !fields["contexts"] || !fields["meta"] {
+		t.Errorf("fields = %v", fields)
+	}
+}
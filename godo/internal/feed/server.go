@@ -0,0 +1,128 @@
+package feed
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"godo/pkg/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler 変更を Server-Sent Events で配信する http.Handler
+//
+// Last-Event-ID ヘッダー（または last_event_id パラメータ）を指定すると、そのイベントIDより後の変更から配信する。
+// 指定しなければ接続した時点より後の変更だけを配信する。
+type Handler struct {
+	History   *History
+	Current   func() ([]*models.Task, error) // 変更されたタスクの現在の内容を読み込む（nilなら添えない）
+	Token     string                         // 空でなければ Authorization: Bearer <token>（または token パラメータ）を必須にする
+	Interval  time.Duration                  // 履歴ファイルを確認する間隔
+	KeepAlive time.Duration                  // 変更がないときに接続を保つためのコメントを送る間隔
+}
+
+// NewHandler 変更履歴ファイルから変更を配信する Handler を作成する
+func NewHandler(history *History, current func() ([]*models.Task, error), token string) *Handler {
+	return &Handler{
+		History:   history,
+		Current:   current,
+		Token:     token,
+		Interval:  300 * time.Millisecond,
+		KeepAlive: 15 * time.Second,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="godo"`)
+		http.Error(w, "認証に失敗しました", http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "ストリーミングに対応していません", http.StatusInternalServerError)
+		return
+	}
+
+	last, err := h.start(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", time.Second.Milliseconds())
+	flusher.Flush()
+
+	poll := time.NewTicker(h.Interval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(h.KeepAlive)
+	defer keepAlive.Stop()
+	for {
+		changes, next, err := h.History.Changes(last, h.Current)
+		if err != nil {
+			fmt.Fprintf(w, ": %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+		}
+		for _, c := range changes {
+			if err := writeChange(w, c); err != nil {
+				return
+			}
+		}
+		if len(changes) > 0 {
+			flusher.Flush()
+		}
+		last = next
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
+
+// start 配信を始めるイベントIDを決める
+func (h *Handler) start(r *http.Request) (int64, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("last_event_id")
+	}
+	if id == "" {
+		return h.History.Latest()
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("イベントIDが不正です: %q", id)
+	}
+	return n, nil
+}
+
+// authorized EventSource はヘッダーを付けられないので、token パラメータでの認証も受け付ける
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) == 1
+}
+
+// writeChange 変更1件を SSE のイベントとして書き込む
+func writeChange(w http.ResponseWriter, c Change) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", c.ID, c.Type, data)
+	return err
+}
@@ -0,0 +1,70 @@
+// Package filelock は複数のプロセスで同じファイルを読み書きするための排他ロックを提供する
+// OS のファイルロックを使うので、ロックを取ったプロセスが異常終了してもロックは残らない
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked TryLock で他のプロセスがロックを取っていた場合のエラー
+var ErrLocked = errors.New("他のプロセスがロックしています")
+
+// Lock 取得したロック
+type Lock struct {
+	file *os.File
+}
+
+// Acquire path のロックファイルに排他ロックを取る。他のプロセスが取っている間は待つ
+// 同じプロセスの中でも、同じファイルのロックを二重に取ると待ち続けるので注意する
+func Acquire(path string) (*Lock, error) {
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f, true); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("ロックの取得に失敗しました: %w", err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// TryLock path のロックファイルに排他ロックを取る。他のプロセスが取っている場合は ErrLocked を返す
+func TryLock(path string) (*Lock, error) {
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f, false); err != nil {
+		f.Close()
+		if errors.Is(err, errWouldBlock) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("ロックの取得に失敗しました: %w", err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release ロックを外す
+func (l *Lock) Release() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("ロックの解除に失敗しました: %w", err)
+	}
+	return l.file.Close()
+}
+
+// open ロックファイルを開く（なければディレクトリごと作る）
+// ロックファイルは消さずに残す。消すと、別のプロセスが新しく作ったファイルをロックしてしまう
+func open(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("ロックファイルのディレクトリを作成できません: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ロックファイルを開けません: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,295 @@
+// Package csv は見出し行付きの CSV / TSV をタスクとして読み込む
+//
+// 列とタスクの項目の対応は "title=Summary,due=Deadline" の形式で指定し、
+// 日付・真偽値・優先度は列の値から変換する。
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"strings"
+	"time"
+)
+
+// Fields 列を対応づけられるタスクの項目
+var Fields = []string{"title", "completed", "status", "priority", "project", "tags", "contexts", "due", "completed_at", "created_at", "note"}
+
+// Options CSV の読み込みの設定
+type Options struct {
+	Comma   rune              // 区切り文字（0なら ','）
+	Mapping map[string]string // タスクの項目 → 列の見出し
+}
+
+// ParseMapping "title=Summary,due=Deadline" の形式の対応を解釈する
+func ParseMapping(spec string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(part, "=")
+		field, column = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("列の対応は 項目=列名 の形式で指定してください: %q", part)
+		}
+		if !isField(field) {
+			return nil, fmt.Errorf("対応できない項目です: %q (対応: %s)", field, strings.Join(Fields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+func isField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Read CSV / TSV を読み込む
+// 対応を指定していない項目は、項目名と同じ見出し（大文字小文字は区別しない）の列を使う。
+// どの項目にも対応しない列はメタ情報として保存する。値を変換できない行は読み込まずに報告する。
+func Read(r io.Reader, opts Options) (*format.Result, error) {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &format.Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("見出し行を読み込めません: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	columns, err := resolveColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &format.Result{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				result.AddIssue(parseErr.Line, "", "行を解釈できません: "+parseErr.Err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("CSVの読み込みに失敗しました: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		task, problems := convertRow(header, record, columns)
+		if len(problems) > 0 {
+			result.AddIssue(line, strings.Join(record, string(reader.Comma)), strings.Join(problems, "、"))
+			continue
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	return result, nil
+}
+
+// resolveColumns 項目ごとに使う列の位置を決める
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	find := func(name string) int {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	columns := map[string]int{}
+	for field, column := range mapping {
+		i := find(column)
+		if i < 0 {
+			return nil, fmt.Errorf("列 %q が見出し行にありません (見出し: %s)", column, strings.Join(header, ", "))
+		}
+		columns[field] = i
+	}
+	for _, field := range Fields {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		if i := find(field); i >= 0 {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("タイトルの列がありません。--map title=列名 で指定してください (見出し: %s)", strings.Join(header, ", "))
+	}
+	return columns, nil
+}
+
+// convertRow 1行をタスクに変換する。変換できなかった値は問題として返す
+func convertRow(header, record []string, columns map[string]int) (*models.Task, []string) {
+	task := &models.Task{}
+	var problems []string
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	date := func(field string) *time.Time {
+		v := value(field)
+		if v == "" {
+			return nil
+		}
+		t, err := ParseDate(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field, err))
+			return nil
+		}
+		return &t
+	}
+
+	task.Title = value("title")
+	if task.Title == "" {
+		problems = append(problems, "タイトルが空です")
+	}
+	if v := value("completed"); v != "" {
+		b, err := ParseBool(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("completed: %v", err))
+		}
+		task.Completed = b
+	}
+	task.Status = value("status")
+	if v := value("priority"); v != "" {
+		p, err := ParsePriority(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("priority: %v", err))
+		}
+		task.Priority = p
+	}
+	task.Project = value("project")
+	task.Tags = splitList(value("tags"))
+	task.Contexts = splitList(value("contexts"))
+	task.DueAt = date("due")
+	if t := date("completed_at"); t != nil {
+		task.CompletedAt = t
+		task.Completed = true
+	}
+	if t := date("created_at"); t != nil {
+		task.CreatedAt = *t
+	}
+	if v := value("note"); v != "" {
+		task.Annotations = []models.Annotation{{Text: v}}
+	}
+
+	// どの項目にも対応しない列はメタ情報として残す
+	used := map[int]bool{}
+	for _, i := range columns {
+		used[i] = true
+	}
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if used[i] || h == "" || i >= len(record) || strings.TrimSpace(record[i]) == "" {
+			continue
+		}
+		if task.Meta == nil {
+			task.Meta = map[string]string{}
+		}
+		task.Meta[strings.ToLower(h)] = strings.TrimSpace(record[i])
+	}
+	return task, problems
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006/01/02",
+	"2006/1/2",
+	"2006/01/02 15:04",
+	"2006/1/2 15:04",
+	"2006年1月2日",
+}
+
+// ParseDate 表計算ソフトでよく使われる形式の日付・日時を解釈する
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local(), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日付として解釈できません: %q", value)
+}
+
+// ParseBool 真偽値を表す値を解釈する（true/false・yes/no・1/0・x・完了 など）
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "1", "x", "✓", "done", "完了", "済", "はい":
+		return true, nil
+	case "false", "no", "n", "0", "", "-", "未完了", "未", "いいえ":
+		return false, nil
+	}
+	return false, fmt.Errorf("真偽値として解釈できません: %q", value)
+}
+
+// ParsePriority 優先度を表す値を "A"〜"Z" に変換する
+// high / medium / low（高 / 中 / 低）と 1〜9 の数字にも対応する
+func ParsePriority(value string) (string, error) {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	switch v {
+	case "HIGH", "高":
+		return "A", nil
+	case "MEDIUM", "MED", "中":
+		return "B", nil
+	case "LOW", "低":
+		return "C", nil
+	}
+	if len(v) == 1 && v[0] >= '1' && v[0] <= '9' {
+		return string(rune('A' + v[0] - '1')), nil
+	}
+	if err := models.ValidatePriority(v); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// splitList "a, b; c" のような区切られた値を分割する
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package csv
+
+import (
+	"godo/internal/format"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, name string, opts Options) *format.Result {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	result, err := Read(f, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReadWithMapping(t *testing.T) {
+	mapping, err := ParseMapping("title=Summary, due=Deadline, priority=Pri, completed=Done, tags=Labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := readFile(t, "pm.csv", Options{Mapping: mapping})
+
+	if len(result.Tasks) != 3 {
+		t.Fatalf("タスク数 = %d, want 3: %+v", len(result.Tasks), result.Tasks)
+	}
+	first := result.Tasks[0]
+	if first.Title != "要件をまとめる" || first.Priority != "A" || first.Completed ||
+		!reflect.DeepEqual(first.Tags, []string{"設計", "要件"}) {
+		t.Errorf("1行目 = %+v", first)
+	}
+	if first.DueAt == nil || !first.DueAt.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("DueAt = %v", first.DueAt)
+	}
+	// 対応しない列はメタ情報に残す
+	if first.Meta["id"] != "1" || first.Meta["owner"] != "佐藤" {
+		t.Errorf("Meta = %v", first.Meta)
+	}
+	if second := result.Tasks[1]; second.Priority != "B" || !second.Completed {
+		t.Errorf("2行目 = %+v", second)
+	}
+	if third := result.Tasks[2]; third.Title != "改行を含む\nタイトル" {
+		t.Errorf("引用符の中の改行 = %q", third.Title)
+	}
+
+	// 行ごとのエラー（見出し行を1行目とする行番号）
+	if len(result.Issues) != 2 {
+		t.Fatalf("Issues = %v", result.Issues)
+	}
+	if result.Issues[0].Line != 4 || !strings.Contains(result.Issues[0].Reason, "タイトルが空です") {
+		t.Errorf("Issues[0] = %v", result.Issues[0])
+	}
+	reason := result.Issues[1].Reason
+	if result.Issues[1].Line != 5 || !strings.Contains(reason, "due") || !strings.Contains(reason, "completed") {
+		t.Errorf("Issues[1] = %v", result.Issues[1])
+	}
+}
+
+func TestReadTSVWithDefaultColumns(t *testing.T) {
+	result := readFile(t, "tasks.tsv", Options{Comma: '\t'})
+	if len(result.Tasks) != 1 || len(result.Issues) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	task := result.Tasks[0]
+	if task.Title != "資料作成" || task.Priority != "B" || task.Project != "営業" ||
+		task.DueAt == nil || task.DueAt.Format("2006-01-02 15:04") != "2025-02-01 15:00" {
+		t.Errorf("task = %+v", task)
+	}
+}
+
+func TestReadMissingColumn(t *testing.T) {
+	if _, err := Read(strings.NewReader("Name,Due\na,2025-01-01\n"), Options{}); err == nil {
+		t.Error("タイトルの列がない場合はエラーにする")
+	}
+	if _, err := Read(strings.NewReader("Name\na\n"), Options{Mapping: map[string]string{"title": "Summary"}}); err == nil {
+		t.Error("存在しない列を指定した場合はエラーにする")
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	if _, err := ParseMapping("title"); err == nil {
+		t.Error("= のない指定はエラーにする")
+	}
+	if _, err := ParseMapping("owner=Owner"); err == nil {
+		t.Error("対応できない項目はエラーにする")
+	}
+}
+
+func TestCoercion(t *testing.T) {
+	for _, v := range []string{"yes", "TRUE", "1", "x", "完了"} {
+		if b, err := ParseBool(v); err != nil || !b {
+			t.Errorf("ParseBool(%q) = %v, %v", v, b, err)
+		}
+	}
+	if _, err := ParseBool("maybe"); err == nil {
+		t.Error("ParseBool(maybe) should fail")
+	}
+	for v, want := range map[string]string{"high": "A", "中": "B", "low": "C", "3": "C", "d": "D"} {
+		if p, err := ParsePriority(v); err != nil || p != want {
+			t.Errorf("ParsePriority(%q) = %q, %v", v, p, err)
+		}
+	}
+	for _, v := range []string{"2025-01-02", "2025/1/2", "2025年1月2日", "2025-01-02T00:00:00+09:00"} {
+		if _, err := ParseDate(v); err != nil {
+			t.Errorf("ParseDate(%q): %v", v, err)
+		}
+	}
+}
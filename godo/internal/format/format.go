@@ -0,0 +1,58 @@
+// Package format は他のツールのファイル形式とタスクを相互に変換する際の共通の型を提供する
+package format
+
+import (
+	"crypto/rand"
+	"fmt"
+	"godo/pkg/models"
+)
+
+// Issue 読み込み時にタスクへ変換できなかった、または一部を変換できなかった箇所
+type Issue struct {
+	Line   int    // 行番号（1始まり、不明な場合は0）
+	Text   string // 元のテキスト
+	Reason string // 変換できなかった理由
+}
+
+// String 報告用の文字列に変換する
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%d行目: %s (%s)", i.Line, i.Reason, i.Text)
+	}
+	return fmt.Sprintf("%s (%s)", i.Reason, i.Text)
+}
+
+// Result 読み込んだタスクと変換できなかった箇所の報告
+type Result struct {
+	Tasks  []*models.Task
+	Issues []Issue
+}
+
+// AddIssue 変換できなかった箇所を追加する
+func (r *Result) AddIssue(line int, text, reason string) {
+	r.Issues = append(r.Issues, Issue{Line: line, Text: text, Reason: reason})
+}
+
+// NewUUID ランダムな UUID（バージョン4）を返す
+func NewUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// EnsureID tm の index のタスクの Meta[key] に UUID を記録して返す（記録済みならその値を返す）
+// 他のツールとタスクを対応づける ID に使う。タスクIDはインストールごとに重複するので使わない
+func EnsureID(tm *models.TaskManager, index int, key string) string {
+	task := tm.GetTaskByIndex(index)
+	if task == nil {
+		return ""
+	}
+	if id := task.Meta[key]; id != "" {
+		return id
+	}
+	id := NewUUID()
+	tm.SetMeta(index, key, id)
+	return id
+}
@@ -0,0 +1,420 @@
+// Package ical は iCalendar（RFC 5545）の VTODO とタスクを相互に変換する
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	prodID        = "-//godo//godo//JA"
+	dateLayout    = "20060102"
+	utcLayout     = "20060102T150405Z"
+	localLayout   = "20060102T150405"
+	maxLineOctets = 75
+)
+
+// メタ情報のキー
+const (
+	MetaUID   = "uid"   // 他のアプリで作られた VTODO の UID
+	MetaRRule = "rrule" // 繰り返しの規則（RRULE の値）
+)
+
+// UID タスクの UID を返す
+// 読み込んだタスクは元の UID を、それ以外はタスクIDから作った UID を使う
+func UID(task *models.Task) string {
+	if uid := task.Meta[MetaUID]; uid != "" {
+		return uid
+	}
+	return fmt.Sprintf("godo-%d@godo", task.ID)
+}
+
+// Write タスクを VTODO を並べた VCALENDAR として書き出す
+func Write(w io.Writer, tasks []*models.Task) error {
+	return write(w, tasks, time.Now())
+}
+
+// FormatTodo タスク1件を VTODO を1つ含む VCALENDAR に変換する
+func FormatTodo(task *models.Task) []byte {
+	var sb strings.Builder
+	write(&sb, []*models.Task{task}, time.Now())
+	return []byte(sb.String())
+}
+
+func write(w io.Writer, tasks []*models.Task, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	for _, task := range tasks {
+		writeTodo(bw, task, now)
+	}
+	writeLine(bw, "END:VCALENDAR")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("iCalendarの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+func writeTodo(w *bufio.Writer, task *models.Task, now time.Time) {
+	writeLine(w, "BEGIN:VTODO")
+	writeLine(w, "UID:"+escapeText(UID(task)))
+	writeLine(w, "DTSTAMP:"+now.UTC().Format(utcLayout))
+	writeLine(w, "SUMMARY:"+escapeText(task.Title))
+	writeLine(w, "STATUS:"+todoStatus(task))
+	if task.Status != "" && !task.Completed {
+		writeLine(w, "X-GODO-STATUS:"+escapeText(task.Status))
+	}
+	if task.DueAt != nil {
+		writeLine(w, "DUE"+formatTime(*task.DueAt))
+	}
+	if task.Priority != "" {
+		writeLine(w, "PRIORITY:"+strconv.Itoa(priorityNumber(task.Priority)))
+		// J〜Z は PRIORITY ではすべて 9 になるため、元の優先度を残して読み込み直したときに戻す
+		if priorityNumber(task.Priority) == 9 && task.Priority != "I" {
+			writeLine(w, "X-GODO-PRIORITY:"+task.Priority)
+		}
+	}
+	var categories []string
+	if task.Project != "" {
+		categories = append(categories, task.Project)
+	}
+	categories = append(categories, task.Tags...)
+	if len(categories) > 0 {
+		escaped := make([]string, len(categories))
+		for i, c := range categories {
+			escaped[i] = escapeText(c)
+		}
+		writeLine(w, "CATEGORIES:"+strings.Join(escaped, ","))
+	}
+	if rrule := task.Meta[MetaRRule]; rrule != "" {
+		writeLine(w, "RRULE:"+rrule)
+	}
+	if task.Completed && task.CompletedAt != nil {
+		writeLine(w, "COMPLETED:"+task.CompletedAt.UTC().Format(utcLayout))
+	}
+	if !task.CreatedAt.IsZero() {
+		writeLine(w, "CREATED:"+task.CreatedAt.UTC().Format(utcLayout))
+	}
+	if !task.UpdatedAt.IsZero() {
+		writeLine(w, "LAST-MODIFIED:"+task.UpdatedAt.UTC().Format(utcLayout))
+	}
+	writeLine(w, "END:VTODO")
+}
+
+func todoStatus(task *models.Task) string {
+	switch {
+	case task.Completed:
+		return "COMPLETED"
+	case task.Status != "" && task.Status != "todo":
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// formatTime 時刻を ":値" の形式に変換する。0時ちょうどの場合は日付として扱う
+func formatTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return ";VALUE=DATE:" + t.Format(dateLayout)
+	}
+	return ":" + t.UTC().Format(utcLayout)
+}
+
+// priorityNumber 優先度 "A"〜"Z" を iCalendar の 1（最高）〜9（最低）に変換する
+// J〜Z はすべて 9 になる（writeTodo は元の優先度を X-GODO-PRIORITY に書き出す）
+func priorityNumber(priority string) int {
+	n := int(priority[0]-'A') + 1
+	if n > 9 {
+		n = 9
+	}
+	return n
+}
+
+// writeLine 75オクテットを超える行を折り返して書き出す
+func writeLine(w *bufio.Writer, line string) {
+	first := true
+	for len(line) > 0 {
+		limit := maxLineOctets
+		if !first {
+			limit-- // 継続行の先頭の空白の分
+			w.WriteString(" ")
+		}
+		cut := len(line)
+		if cut > limit {
+			cut = limit
+			// UTF-8 の文字の途中で区切らない
+			for cut > 0 && line[cut]&0xC0 == 0x80 {
+				cut--
+			}
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n")
+		line = line[cut:]
+		first = false
+	}
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func unescapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitText "," で区切られた TEXT の値を分割する（"\," は区切りとみなさない）
+func splitText(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == ',' {
+			parts = append(parts, unescapeText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeText(s[start:]))
+}
+
+// property 内容行1行分のプロパティ
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+	line   int
+}
+
+// Read .ics ファイルから VTODO を読み込む
+// VTODO 以外のコンポーネント（VEVENT など）は無視する
+func Read(r io.Reader) (*format.Result, error) {
+	props, err := readProperties(r)
+	if err != nil {
+		return nil, err
+	}
+	result := &format.Result{}
+	var todo []property
+	inTodo := false
+	start, nested := 0, 0
+	for _, p := range props {
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO"):
+			inTodo, todo, start, nested = true, nil, p.line, 0
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO"):
+			if inTodo {
+				parseTodo(todo, start, result)
+			}
+			inTodo = false
+		case !inTodo:
+		case p.name == "BEGIN":
+			// VALARM などの入れ子のコンポーネントのプロパティは使わない
+			nested++
+		case p.name == "END":
+			nested--
+		case nested == 0:
+			todo = append(todo, p)
+		}
+	}
+	if inTodo {
+		result.AddIssue(start, "BEGIN:VTODO", "END:VTODO がありません")
+	}
+	return result, nil
+}
+
+// readProperties 折り返しを戻しながら内容行を読み込む
+func readProperties(r io.Reader) ([]property, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var props []property
+	var current strings.Builder
+	currentLine := 0
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		if p, ok := parseProperty(current.String()); ok {
+			p.line = currentLine
+			props = append(props, p)
+		}
+		current.Reset()
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			current.WriteString(text[1:])
+			continue
+		}
+		flush()
+		current.WriteString(text)
+		currentLine = line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("iCalendarの読み込みに失敗しました: %w", err)
+	}
+	flush()
+	return props, nil
+}
+
+// parseProperty "NAME;PARAM=VALUE:value" の形式の内容行を解釈する
+func parseProperty(line string) (property, bool) {
+	p := property{params: map[string]string{}}
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if inQuote {
+				continue
+			}
+			head := strings.Split(line[:i], ";")
+			p.name = strings.ToUpper(head[0])
+			for _, param := range head[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			p.value = line[i+1:]
+			return p, p.name != ""
+		}
+	}
+	return p, false
+}
+
+// parseTodo VTODO のプロパティをタスクに変換する
+func parseTodo(props []property, start int, result *format.Result) {
+	task := &models.Task{}
+	var categories []string
+	var status, godoStatus, godoPriority string
+	for _, p := range props {
+		switch p.name {
+		case "UID":
+			setMeta(task, MetaUID, unescapeText(p.value))
+		case "SUMMARY":
+			task.Title = strings.TrimSpace(unescapeText(p.value))
+		case "STATUS":
+			status = strings.ToUpper(p.value)
+		case "X-GODO-STATUS":
+			godoStatus = unescapeText(p.value)
+		case "X-GODO-PRIORITY":
+			godoPriority = strings.TrimSpace(p.value)
+			if models.ValidatePriority(godoPriority) != nil {
+				result.AddIssue(p.line, p.value, "優先度が不正です")
+				godoPriority = ""
+			}
+		case "DUE":
+			if t, err := parseTime(p); err == nil {
+				task.DueAt = &t
+			} else {
+				result.AddIssue(p.line, p.value, "期限の日時が不正です")
+			}
+		case "PRIORITY":
+			n, err := strconv.Atoi(strings.TrimSpace(p.value))
+			if err != nil || n < 0 || n > 9 {
+				result.AddIssue(p.line, p.value, "優先度が不正です")
+			} else if n > 0 {
+				task.Priority = string(rune('A' + n - 1))
+			}
+		case "CATEGORIES":
+			for _, c := range splitText(p.value) {
+				if c = strings.TrimSpace(c); c != "" {
+					categories = append(categories, c)
+				}
+			}
+		case "RRULE":
+			setMeta(task, MetaRRule, p.value)
+		case "COMPLETED", "CREATED", "LAST-MODIFIED":
+			t, err := parseTime(p)
+			if err != nil {
+				result.AddIssue(p.line, p.value, p.name+" の日時が不正です")
+				continue
+			}
+			switch p.name {
+			case "COMPLETED":
+				task.CompletedAt = &t
+			case "CREATED":
+				task.CreatedAt = t
+			default:
+				task.UpdatedAt = t
+			}
+		}
+	}
+
+	if task.Title == "" {
+		result.AddIssue(start, "BEGIN:VTODO", "SUMMARY がありません")
+		return
+	}
+	// 他のアプリで PRIORITY を変えた場合は X-GODO-PRIORITY より PRIORITY を優先する
+	if godoPriority != "" && task.Priority != "" && priorityNumber(godoPriority) == priorityNumber(task.Priority) {
+		task.Priority = godoPriority
+	}
+	if len(categories) > 0 {
+		task.Project = categories[0]
+		task.Tags = categories[1:]
+	}
+	switch status {
+	case "COMPLETED", "CANCELLED":
+		task.Completed = true
+	case "IN-PROCESS":
+		task.Status = "doing"
+	}
+	if task.CompletedAt != nil {
+		task.Completed = true
+	}
+	if godoStatus != "" && !task.Completed {
+		task.Status = godoStatus
+	}
+	result.Tasks = append(result.Tasks, task)
+}
+
+// parseTime DATE / DATE-TIME（UTC・TZID 付き・ローカル時刻）の値を解釈する
+func parseTime(p property) (time.Time, error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		return time.ParseInLocation(dateLayout, value, time.Local)
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t.Local(), err
+	}
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	return t.Local(), err
+}
+
+func setMeta(task *models.Task, key, value string) {
+	if task.Meta == nil {
+		task.Meta = map[string]string{}
+	}
+	task.Meta[key] = value
+}
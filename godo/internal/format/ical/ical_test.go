@@ -0,0 +1,198 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func readFile(t *testing.T, name string) *format.Result {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	result, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReadThunderbird(t *testing.T) {
+	result := readFile(t, "thunderbird.ics")
+	if len(result.Tasks) != 2 {
+		t.Fatalf("タスク数 = %d, want 2", len(result.Tasks))
+	}
+
+	task := result.Tasks[0]
+	if task.Title != "請求書を送る, 経理へ連絡" {
+		t.Errorf("Title = %q", task.Title)
+	}
+	if task.Priority != "A" || task.Status != "doing" || task.Completed {
+		t.Errorf("Priority = %q, Status = %q, Completed = %v", task.Priority, task.Status, task.Completed)
+	}
+	if task.Project != "仕事" || !reflect.DeepEqual(task.Tags, []string{"経理"}) {
+		t.Errorf("Project = %q, Tags = %v", task.Project, task.Tags)
+	}
+	if task.DueAt == nil || !task.DueAt.Equal(time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("DueAt = %v", task.DueAt)
+	}
+	if task.Meta[MetaRRule] != "FREQ=MONTHLY;BYMONTHDAY=10" || task.Meta[MetaUID] != "3b1e6c1a-0f4e-4d8e-9d1a-7c6b2f0e9a11" {
+		t.Errorf("Meta = %v", task.Meta)
+	}
+	if !task.CreatedAt.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !task.UpdatedAt.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("CreatedAt = %v, UpdatedAt = %v", task.CreatedAt, task.UpdatedAt)
+	}
+
+	// 折り返された行をつなげて読み込む
+	done := result.Tasks[1]
+	if done.Title != "とても長いタイトルのタスクです。カレンダーアプリによっては75オクテットを超える行が折り返されて書き出されます" {
+		t.Errorf("Title = %q", done.Title)
+	}
+	if !done.Completed || done.CompletedAt == nil {
+		t.Errorf("完了タスクとして読み込まれていません: %+v", done)
+	}
+	if done.DueAt == nil || done.DueAt.Format("2006-01-02 15:04") != "2025-01-04 00:00" {
+		t.Errorf("DueAt = %v", done.DueAt)
+	}
+
+	// SUMMARY のない VTODO と不正な日時は報告する
+	if len(result.Issues) != 2 {
+		t.Fatalf("Issues = %v", result.Issues)
+	}
+}
+
+func TestReadReminders(t *testing.T) {
+	result := readFile(t, "reminders.ics")
+	if len(result.Tasks) != 2 {
+		t.Fatalf("タスク数 = %d, want 2", len(result.Tasks))
+	}
+	if task := result.Tasks[0]; task.Priority != "I" || task.Completed || task.DueAt == nil {
+		t.Errorf("1件目 = %+v", task)
+	}
+	if task := result.Tasks[1]; !task.Completed || task.Priority != "" {
+		t.Errorf("中止した VTODO = %+v", task)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Line != 12 {
+		t.Errorf("Issues = %v", result.Issues)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)
+	task := &models.Task{
+		ID:        7,
+		Title:     "資料; 確認, 共有",
+		Priority:  "B",
+		Project:   "仕事",
+		Tags:      []string{"会議"},
+		Status:    "review",
+		DueAt:     &due,
+		Meta:      map[string]string{MetaRRule: "FREQ=WEEKLY"},
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, []*models.Task{task}, created); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//godo//godo//JA",
+		"BEGIN:VTODO",
+		"UID:godo-7@godo",
+		"DTSTAMP:20250101T090000Z",
+		`SUMMARY:資料\; 確認\, 共有`,
+		"STATUS:IN-PROCESS",
+		"X-GODO-STATUS:review",
+		"DUE;VALUE=DATE:20250110",
+		"PRIORITY:2",
+		"CATEGORIES:仕事,会議",
+		"RRULE:FREQ=WEEKLY",
+		"CREATED:20250101T090000Z",
+		"LAST-MODIFIED:20250101T100000Z",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	task := &models.Task{ID: 1, Title: strings.Repeat("あ", 60)}
+	var buf bytes.Buffer
+	if err := Write(&buf, []*models.Task{task}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if len(scanner.Text()) > maxLineOctets {
+			t.Errorf("75オクテットを超える行があります: %q", scanner.Text())
+		}
+	}
+	result, err := Read(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != 1 || result.Tasks[0].Title != task.Title {
+		t.Errorf("折り返した行を読み戻せません: %+v", result.Tasks)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	result := readFile(t, "thunderbird.ics")
+	var buf bytes.Buffer
+	if err := Write(&buf, result.Tasks); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Issues) != 0 || !reflect.DeepEqual(again.Tasks, result.Tasks) {
+		t.Errorf("往復変換で内容が変わりました\ngot:  %+v\nwant: %+v", again.Tasks, result.Tasks)
+	}
+}
+
+func TestRoundTripKeepsLowPriorities(t *testing.T) {
+	var buf bytes.Buffer
+	tasks := []*models.Task{{Title: "a", Priority: "I"}, {Title: "b", Priority: "J"}, {Title: "c", Priority: "Z"}}
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "PRIORITY:9") != 3 || strings.Count(buf.String(), "X-GODO-PRIORITY:") != 2 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	result, err := Read(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"I", "J", "Z"} {
+		if got := result.Tasks[i].Priority; got != want {
+			t.Errorf("task %d: Priority = %q, want %q", i, got, want)
+		}
+	}
+
+	// 他のアプリで PRIORITY を変えた場合はそちらを使う
+	changed := strings.Replace(buf.String(), "PRIORITY:9\r\nX-GODO-PRIORITY:Z", "PRIORITY:1\r\nX-GODO-PRIORITY:Z", 1)
+	result, err = Read(strings.NewReader(changed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Tasks[2].Priority; got != "A" {
+		t.Errorf("Priority = %q, want A", got)
+	}
+}
@@ -0,0 +1,341 @@
+// Package markdown は GitHub 形式のタスクリスト（"- [ ] item" / "- [x] item"）とタスクを相互に変換する
+//
+// 見出し（## 以下）の文字列をプロジェクト名として扱い、各項目の行末に
+// "<!-- godo:ID -->" という表示されないコメントでタスクIDを埋め込む。
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	itemPattern    = regexp.MustCompile(`^(\s*)([-*+]) \[([ xX])\]\s+(.*)$`)
+	idPattern      = regexp.MustCompile(`\s*<!--\s*godo:(\d+)\s*-->\s*$`)
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	syncedPattern  = regexp.MustCompile(`^<!--\s*godo:synced\s+(\S+)(\s+ids=([\d,]*))?\s*-->$`)
+)
+
+// Item チェックリストの1項目
+type Item struct {
+	ID      int    // 埋め込まれたタスクID（未登録の項目は0）
+	Checked bool   // "[x]" かどうか
+	Title   string // 項目の文字列
+	Project string // 項目が属する見出し
+
+	line    int // 元の行番号（0始まり、新しく追加した項目は-1）
+	indent  string
+	marker  string
+	deleted bool
+}
+
+// Document チェックリストを含む Markdown ファイル
+// チェックリスト以外の行は書き出し時にそのまま残す
+type Document struct {
+	Items  []*Item
+	Synced time.Time // 最後に同期した日時（未同期の場合はゼロ値）
+	// SyncedIDs 最後の同期でファイルに書き出したタスクのID（記録のない古いファイルではnil）
+	SyncedIDs []int
+
+	src  []string
+	crlf bool
+}
+
+// Parse Markdown を読み込み、チェックリストの項目を取り出す
+// コードブロックの中の行は項目として扱わない
+func Parse(r io.Reader) (*Document, []format.Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Markdownの読み込みに失敗しました: %w", err)
+	}
+	doc := &Document{crlf: bytes.Contains(data, []byte("\r\n"))}
+	var issues []format.Issue
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	project := ""
+	fenced := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		index := len(doc.src)
+		doc.src = append(doc.src, line)
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if m := syncedPattern.FindStringSubmatch(trimmed); m != nil {
+			if t, err := time.Parse(time.RFC3339Nano, m[1]); err == nil {
+				doc.Synced = t
+			} else {
+				issues = append(issues, format.Issue{Line: index + 1, Text: line, Reason: "同期日時が不正です"})
+			}
+			if m[2] != "" {
+				doc.SyncedIDs = []int{}
+				for _, field := range strings.Split(m[3], ",") {
+					if id, err := strconv.Atoi(field); err == nil {
+						doc.SyncedIDs = append(doc.SyncedIDs, id)
+					}
+				}
+			}
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			// "#" は文書のタイトルとみなし、"##" 以下をプロジェクトとして扱う
+			project = ""
+			if len(m[1]) > 1 {
+				project = m[2]
+			}
+			continue
+		}
+		m := itemPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		item := &Item{Checked: m[3] != " ", Project: project, line: index, indent: m[1], marker: m[2]}
+		title := m[4]
+		if id := idPattern.FindStringSubmatch(title); id != nil {
+			item.ID, _ = strconv.Atoi(id[1])
+			title = title[:len(title)-len(id[0])]
+		}
+		item.Title = strings.TrimSpace(title)
+		if item.Title == "" {
+			issues = append(issues, format.Issue{Line: index + 1, Text: line, Reason: "項目の文字列がありません"})
+			continue
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("Markdownの読み込みに失敗しました: %w", err)
+	}
+	return doc, issues, nil
+}
+
+// Read Markdown のチェックリストをタスクとして読み込む
+func Read(r io.Reader) (*format.Result, error) {
+	doc, issues, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	result := &format.Result{Issues: issues}
+	for _, item := range doc.Items {
+		result.Tasks = append(result.Tasks, &models.Task{
+			ID:        item.ID,
+			Title:     item.Title,
+			Completed: item.Checked,
+			Project:   item.Project,
+		})
+	}
+	return result, nil
+}
+
+// Write タスクをプロジェクトごとの見出しに分けたチェックリストとして書き出す
+func Write(w io.Writer, tasks []*models.Task) error {
+	doc := &Document{}
+	for _, task := range tasks {
+		doc.AddTask(task)
+	}
+	return doc.Render(w)
+}
+
+// AddTask タスクを新しい項目として追加する
+// 書き出し時に同じプロジェクトの項目の後ろ、または同じ名前の見出しの下に挿入する
+func (d *Document) AddTask(task *models.Task) *Item {
+	item := &Item{line: -1}
+	item.setTask(task)
+	d.Items = append(d.Items, item)
+	return item
+}
+
+func (i *Item) setTask(task *models.Task) {
+	i.ID = task.ID
+	i.Title = task.Title
+	i.Checked = task.Completed
+	i.Project = task.Project
+}
+
+// format 項目を Markdown の1行に変換する
+func (i *Item) format() string {
+	marker := i.marker
+	if marker == "" {
+		marker = "-"
+	}
+	check := " "
+	if i.Checked {
+		check = "x"
+	}
+	line := fmt.Sprintf("%s%s [%s] %s", i.indent, marker, check, i.Title)
+	if i.ID > 0 {
+		line += fmt.Sprintf(" <!-- godo:%d -->", i.ID)
+	}
+	return line
+}
+
+// Render チェックリスト以外の行を保ったまま Markdown を書き出す
+func (d *Document) Render(w io.Writer) error {
+	itemAt := map[int]*Item{}
+	for _, item := range d.Items {
+		if item.line >= 0 {
+			itemAt[item.line] = item
+		}
+	}
+	firstHeading := d.firstProjectHeading()
+
+	// 新しい項目の挿入位置を決める
+	after := map[int][]*Item{}
+	var before []*Item
+	var tailProjects []string
+	tail := map[string][]*Item{}
+	for _, item := range d.Items {
+		if item.line >= 0 || item.deleted {
+			continue
+		}
+		if anchor := d.anchor(item.Project); anchor >= 0 {
+			after[anchor] = append(after[anchor], item)
+		} else if item.Project == "" && firstHeading >= 0 {
+			before = append(before, item)
+		} else {
+			if _, ok := tail[item.Project]; !ok {
+				tailProjects = append(tailProjects, item.Project)
+			}
+			tail[item.Project] = append(tail[item.Project], item)
+		}
+	}
+
+	var out []string
+	for i, line := range d.src {
+		if i == firstHeading && len(before) > 0 {
+			for _, item := range before {
+				out = append(out, item.format())
+			}
+			out = append(out, "")
+		}
+		if item, ok := itemAt[i]; ok {
+			if !item.deleted {
+				out = append(out, item.format())
+			}
+		} else if !syncedPattern.MatchString(strings.TrimSpace(line)) {
+			out = append(out, line)
+		}
+		for _, item := range after[i] {
+			out = append(out, item.format())
+		}
+	}
+
+	// 見出しのない項目を先に、続けてプロジェクトごとの見出しを末尾に追加する
+	if items, ok := tail[""]; ok {
+		out = appendSection(out, "", items)
+	}
+	for _, project := range tailProjects {
+		if project != "" {
+			out = appendSection(out, project, tail[project])
+		}
+	}
+	if !d.Synced.IsZero() {
+		out = trimTrailingBlank(out)
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		ids := make([]string, len(d.SyncedIDs))
+		for i, id := range d.SyncedIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		out = append(out, fmt.Sprintf("<!-- godo:synced %s ids=%s -->", d.Synced.Format(time.RFC3339Nano), strings.Join(ids, ",")))
+	}
+
+	newline := "\n"
+	if d.crlf {
+		newline = "\r\n"
+	}
+	bw := bufio.NewWriter(w)
+	for _, line := range out {
+		bw.WriteString(line)
+		bw.WriteString(newline)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("Markdownの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// WriteFile path に Markdown を書き出す
+// 同期した場合はファイルの更新日時を同期日時に揃え、次の同期で書き出した後の編集だけを変更とみなすようにする
+func (d *Document) WriteFile(path string) error {
+	var buf bytes.Buffer
+	if err := d.Render(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ファイルを書き込めません: %w", err)
+	}
+	if !d.Synced.IsZero() {
+		if err := os.Chtimes(path, d.Synced, d.Synced); err != nil {
+			return fmt.Errorf("ファイルの更新日時を設定できません: %w", err)
+		}
+	}
+	return nil
+}
+
+// anchor 指定したプロジェクトの新しい項目を挿入する行を返す
+// 同じプロジェクトの最後の項目、なければ同じ名前の見出しの行で、どちらもなければ-1
+func (d *Document) anchor(project string) int {
+	last := -1
+	for _, item := range d.Items {
+		if item.line >= 0 && !item.deleted && item.Project == project && item.line > last {
+			last = item.line
+		}
+	}
+	if last >= 0 || project == "" {
+		return last
+	}
+	for i, line := range d.src {
+		if m := headingPattern.FindStringSubmatch(line); m != nil && len(m[1]) > 1 && m[2] == project {
+			return i
+		}
+	}
+	return -1
+}
+
+// firstProjectHeading 最初の "##" 以下の見出しの行を返す（なければ-1）
+func (d *Document) firstProjectHeading() int {
+	for i, line := range d.src {
+		if m := headingPattern.FindStringSubmatch(line); m != nil && len(m[1]) > 1 {
+			return i
+		}
+	}
+	return -1
+}
+
+func appendSection(out []string, project string, items []*Item) []string {
+	out = trimTrailingBlank(out)
+	if len(out) > 0 {
+		out = append(out, "")
+	}
+	if project != "" {
+		out = append(out, "## "+project, "")
+	}
+	for _, item := range items {
+		out = append(out, item.format())
+	}
+	return out
+}
+
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
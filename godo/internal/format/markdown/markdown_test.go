@@ -0,0 +1,110 @@
+package markdown
+
+import (
+	"bytes"
+	"godo/pkg/models"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	input := strings.Join([]string{
+		"# メモ",
+		"",
+		"- [ ] 牛乳を買う",
+		"",
+		"## 仕事",
+		"",
+		"- [x] 報告書を書く <!-- godo:3 -->",
+		"  * [ ] 下書き",
+		"- 普通のリスト",
+		"- [ ] ",
+		"",
+		"```",
+		"- [ ] コード中の項目",
+		"```",
+	}, "\n")
+
+	result, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != 3 {
+		t.Fatalf("タスク数 = %d, want 3: %+v", len(result.Tasks), result.Tasks)
+	}
+	if task := result.Tasks[0]; task.Title != "牛乳を買う" || task.Project != "" || task.Completed {
+		t.Errorf("1件目 = %+v", task)
+	}
+	if task := result.Tasks[1]; task.Title != "報告書を書く" || task.Project != "仕事" || !task.Completed || task.ID != 3 {
+		t.Errorf("2件目 = %+v", task)
+	}
+	if task := result.Tasks[2]; task.Title != "下書き" || task.Project != "仕事" {
+		t.Errorf("3件目 = %+v", task)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Line != 10 {
+		t.Errorf("Issues = %v", result.Issues)
+	}
+}
+
+func TestWriteGroupsByProject(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, Title: "設計", Project: "仕事"},
+		{ID: 2, Title: "牛乳を買う"},
+		{ID: 3, Title: "実装", Project: "仕事", Completed: true},
+		{ID: 4, Title: "走る", Project: "健康"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"- [ ] 牛乳を買う <!-- godo:2 -->",
+		"",
+		"## 仕事",
+		"",
+		"- [ ] 設計 <!-- godo:1 -->",
+		"- [x] 実装 <!-- godo:3 -->",
+		"",
+		"## 健康",
+		"",
+		"- [ ] 走る <!-- godo:4 -->",
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// 書き出した内容を読み込むと同じタスクになる
+	result, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != len(tasks) {
+		t.Fatalf("タスク数 = %d", len(result.Tasks))
+	}
+	for _, got := range result.Tasks {
+		for _, task := range tasks {
+			if got.ID == task.ID && (got.Title != task.Title || got.Project != task.Project || got.Completed != task.Completed) {
+				t.Errorf("got %+v, want %+v", got, task)
+			}
+		}
+	}
+}
+
+func TestRenderKeepsOtherLines(t *testing.T) {
+	input := "# README\r\n\r\n説明文\r\n\r\n## TODO\r\n\r\n- [ ] 項目 <!-- godo:1 -->\r\n\r\n## ライセンス\r\n\r\nMIT\r\n"
+	doc, _, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.AddTask(&models.Task{ID: 2, Title: "追加", Project: "TODO"})
+	doc.AddTask(&models.Task{ID: 3, Title: "新規", Project: "新しい見出し"})
+
+	var buf bytes.Buffer
+	if err := doc.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "# README\r\n\r\n説明文\r\n\r\n## TODO\r\n\r\n- [ ] 項目 <!-- godo:1 -->\r\n- [ ] 追加 <!-- godo:2 -->\r\n\r\n## ライセンス\r\n\r\nMIT\r\n\r\n## 新しい見出し\r\n\r\n- [ ] 新規 <!-- godo:3 -->\r\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
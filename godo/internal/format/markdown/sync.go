@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"godo/pkg/models"
+	"slices"
+	"time"
+)
+
+// SyncReport 同期で行った変更の件数
+type SyncReport struct {
+	StoreAdded   int // ファイルの新しい項目から追加したタスク
+	StoreUpdated int // ファイルの変更を反映したタスク
+	StoreDeleted int // ファイルから消された項目に対応して削除したタスク
+	FileAdded    int // ファイルに追加した項目
+	FileUpdated  int // タスクの変更を反映した項目
+	FileRemoved  int // 削除されたタスクに対応して取り除いた項目
+}
+
+// Changed 同期で何か変更されたかを返す
+func (r SyncReport) Changed() bool {
+	return r != SyncReport{}
+}
+
+// Sync チェックリストとタスクを双方向に同期する
+//
+// 項目とタスクは埋め込まれたIDで対応づける。前回の同期以降に両方で変更された場合は、
+// ファイルの更新日時とタスクの更新日時の新しい方を優先する。
+// 前回の同期でファイルに書き出したタスクがファイルにない場合は、ファイルから消されたものとして削除する。
+// 前回の同期の後に追加・インポート・復元したタスクは、ファイルにないのでファイルに追記する。
+func Sync(doc *Document, tm *models.TaskManager, modTime time.Time) SyncReport {
+	var report SyncReport
+	synced := doc.Synced
+	fileChanged := synced.IsZero() || modTime.After(synced)
+	seen := map[int]bool{}
+	written := map[int]bool{}
+	for _, id := range doc.SyncedIDs {
+		written[id] = true
+	}
+
+	for _, item := range doc.Items {
+		if item.line < 0 || item.deleted {
+			continue
+		}
+		index := -1
+		if item.ID > 0 && !seen[item.ID] {
+			index = tm.FindIndexByID(item.ID)
+		}
+		if index < 0 {
+			if item.ID > 0 && !seen[item.ID] && !synced.IsZero() {
+				// 前回の同期後にタスクが削除された
+				item.deleted = true
+				report.FileRemoved++
+				continue
+			}
+			// IDのない項目（または複製された項目）は新しいタスクとして追加する
+			task := &models.Task{Title: item.Title, Completed: item.Checked, Project: item.Project}
+			if item.Checked {
+				now := time.Now()
+				task.CompletedAt = &now
+			}
+			item.ID = tm.ImportTask(task).ID
+			seen[item.ID] = true
+			report.StoreAdded++
+			continue
+		}
+		seen[item.ID] = true
+
+		task := tm.GetTaskByIndex(index)
+		if item.Title == task.Title && item.Checked == task.Completed && item.Project == task.Project {
+			continue
+		}
+		storeChanged := task.UpdatedAt.After(synced)
+		if fileChanged && (!storeChanged || modTime.After(task.UpdatedAt)) {
+			if item.Title != task.Title {
+				tm.UpdateTask(index, item.Title)
+			}
+			if item.Checked != task.Completed {
+				tm.ToggleTask(index)
+			}
+			if item.Project != task.Project {
+				tm.SetProject(index, item.Project)
+			}
+			report.StoreUpdated++
+			continue
+		}
+		if item.Project != task.Project {
+			// 別の見出しの下に移す
+			item.deleted = true
+			doc.AddTask(task)
+		} else {
+			item.setTask(task)
+		}
+		report.FileUpdated++
+	}
+
+	var deleted []int
+	for _, task := range tm.GetTasks() {
+		if seen[task.ID] {
+			continue
+		}
+		if fileChanged && written[task.ID] {
+			deleted = append(deleted, task.ID)
+			continue
+		}
+		doc.AddTask(task)
+		report.FileAdded++
+	}
+	for _, id := range deleted {
+		if tm.DeleteTask(tm.FindIndexByID(id)) {
+			report.StoreDeleted++
+		}
+	}
+
+	doc.SyncedIDs = []int{}
+	for _, item := range doc.Items {
+		if !item.deleted && item.ID > 0 {
+			doc.SyncedIDs = append(doc.SyncedIDs, item.ID)
+		}
+	}
+	slices.Sort(doc.SyncedIDs)
+	doc.Synced = time.Now()
+	return report
+}
@@ -0,0 +1,179 @@
+package markdown
+
+import (
+	"bytes"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syncString 文字列のMarkdownとタスクを同期し、書き出した結果を返す
+func syncString(t *testing.T, input string, tm *models.TaskManager, modTime time.Time) (string, SyncReport) {
+	t.Helper()
+	doc, _, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Sync(doc, tm, modTime)
+	var buf bytes.Buffer
+	if err := doc.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String(), report
+}
+
+// withoutSynced 同期日時の行を取り除く
+func withoutSynced(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(line, "<!-- godo:synced") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+func TestSyncFirstTime(t *testing.T) {
+	tm := models.NewTaskManager([]*models.Task{{ID: 1, Title: "既存のタスク", CreatedAt: time.Now()}})
+	input := "## 仕事\n\n- [ ] ファイルの項目\n"
+
+	out, report := syncString(t, input, tm, time.Now())
+	if report.StoreAdded != 1 || report.FileAdded != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(tm.GetTasks()) != 2 {
+		t.Fatalf("タスク数 = %d", len(tm.GetTasks()))
+	}
+	added := tm.GetTaskByIndex(1)
+	if added.Title != "ファイルの項目" || added.Project != "仕事" {
+		t.Errorf("追加されたタスク = %+v", added)
+	}
+	want := "- [ ] 既存のタスク <!-- godo:1 -->\n\n## 仕事\n\n- [ ] ファイルの項目 <!-- godo:2 -->\n"
+	if withoutSynced(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if !strings.Contains(out, "<!-- godo:synced ") {
+		t.Error("同期日時が書き出されていません")
+	}
+}
+
+func TestSyncFileChangesWin(t *testing.T) {
+	synced := time.Now().Add(-time.Hour)
+	before := synced.Add(-time.Hour)
+	tm := models.NewTaskManager([]*models.Task{
+		{ID: 1, Title: "a", CreatedAt: before, UpdatedAt: before},
+		{ID: 2, Title: "b", CreatedAt: before, UpdatedAt: before},
+		{ID: 3, Title: "c", CreatedAt: before, UpdatedAt: before},
+	})
+	input := "- [x] A <!-- godo:1 -->\n- [ ] b <!-- godo:2 -->\n\n<!-- godo:synced " + synced.Format(time.RFC3339Nano) + " ids=1,2,3 -->\n"
+
+	out, report := syncString(t, input, tm, time.Now())
+	if report.StoreUpdated != 1 || report.StoreDeleted != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	task := tm.GetTaskByIndex(tm.FindIndexByID(1))
+	if task.Title != "A" || !task.Completed || task.CompletedAt == nil {
+		t.Errorf("ファイルの変更が反映されていません: %+v", task)
+	}
+	// ファイルから消されたタスクは削除する
+	if tm.FindIndexByID(3) >= 0 {
+		t.Error("ファイルから消されたタスクが残っています")
+	}
+	if withoutSynced(out) != "- [x] A <!-- godo:1 -->\n- [ ] b <!-- godo:2 -->\n" {
+		t.Errorf("got:\n%s", out)
+	}
+}
+
+func TestSyncStoreChangesWin(t *testing.T) {
+	synced := time.Now().Add(-time.Hour)
+	before := synced.Add(-time.Hour)
+	tm := models.NewTaskManager([]*models.Task{
+		{ID: 1, Title: "a", CreatedAt: before, UpdatedAt: before},
+		{ID: 2, Title: "b", CreatedAt: before, UpdatedAt: before},
+	})
+	tm.UpdateTask(0, "変更後")
+	tm.SetProject(1, "仕事")
+	tm.AddTask("新しいタスク")
+	input := "- [ ] a <!-- godo:1 -->\n- [ ] b <!-- godo:2 -->\n- [ ] 削除済み <!-- godo:9 -->\n\n<!-- godo:synced " + synced.Format(time.RFC3339Nano) + " -->\n"
+
+	// ファイルは前回の同期から変更されていない
+	out, report := syncString(t, input, tm, synced.Add(-time.Minute))
+	if report.FileUpdated != 2 || report.FileAdded != 1 || report.FileRemoved != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	want := "- [ ] 変更後 <!-- godo:1 -->\n- [ ] 新しいタスク <!-- godo:3 -->\n\n## 仕事\n\n- [ ] b <!-- godo:2 -->\n"
+	if withoutSynced(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if len(tm.GetTasks()) != 3 {
+		t.Errorf("タスク数 = %d", len(tm.GetTasks()))
+	}
+}
+
+func TestSyncIsStable(t *testing.T) {
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	out, _ := syncString(t, "- [ ] b\n", tm, time.Now())
+
+	// 変更がなければ2回目の同期では何も変わらない
+	again, report := syncString(t, out, tm, time.Now())
+	if report.Changed() {
+		t.Errorf("report = %+v", report)
+	}
+	if withoutSynced(again) != withoutSynced(out) {
+		t.Errorf("got:\n%s\nwant:\n%s", again, out)
+	}
+	if len(tm.GetTasks()) != 2 {
+		t.Errorf("タスク数 = %d", len(tm.GetTasks()))
+	}
+}
+
+func TestSyncKeepsTasksAddedAfterLastSync(t *testing.T) {
+	synced := time.Now().Add(-time.Hour)
+	before := synced.Add(-time.Hour)
+	tm := models.NewTaskManager([]*models.Task{
+		{ID: 1, Title: "a", CreatedAt: before, UpdatedAt: before},
+	})
+	// 前回の同期の後にインポートした、作成日時の古いタスク
+	tm.ImportTask(&models.Task{Title: "インポート", CreatedAt: before.Add(-24 * time.Hour)})
+	input := "- [ ] a（編集） <!-- godo:1 -->\n\n<!-- godo:synced " + synced.Format(time.RFC3339Nano) + " ids=1 -->\n"
+
+	out, report := syncString(t, input, tm, time.Now())
+	if report.StoreDeleted != 0 || report.FileAdded != 1 || report.StoreUpdated != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if withoutSynced(out) != "- [ ] a（編集） <!-- godo:1 -->\n- [ ] インポート <!-- godo:2 -->\n" {
+		t.Errorf("got:\n%s", out)
+	}
+	if !strings.Contains(out, " ids=1,2 -->") {
+		t.Errorf("書き出したIDが記録されていません:\n%s", out)
+	}
+
+	// 記録したIDのタスクがファイルから消された場合だけ削除する
+	edited := strings.Replace(out, "- [ ] インポート <!-- godo:2 -->\n", "", 1)
+	if _, report := syncString(t, edited, tm, time.Now().Add(time.Hour)); report.StoreDeleted != 1 || tm.FindIndexByID(2) >= 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestWriteFileSetsModTimeToSynced(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.md")
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	doc, _, _ := Parse(strings.NewReader(""))
+	Sync(doc, tm, time.Time{})
+	if err := doc.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 書き出した直後のファイルは変更されていないとみなす
+	if info.ModTime().After(doc.Synced) {
+		t.Errorf("更新日時 %v が同期日時 %v より後です", info.ModTime(), doc.Synced)
+	}
+}
@@ -0,0 +1,380 @@
+// Package org は Emacs の Org-mode の見出し（"* TODO 項目"）とタスクを相互に変換する
+package org
+
+import (
+	"bufio"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 書き出し・読み込みで使うプロパティ
+const (
+	PropertyID       = "GODO_ID" // 古いバージョンが書き出していたタスクID（インストールごとに重なるので読み込まない）
+	PropertyStatus   = "GODO_STATUS"
+	PropertyCategory = "CATEGORY"
+	PropertyCreated  = "CREATED"
+)
+
+// メタ情報のキー
+const (
+	MetaScheduled = "scheduled"   // SCHEDULED の日時（"2006-01-02" または "2006-01-02 15:04"）
+	MetaKeyword   = "org_keyword" // TODO / DONE 以外のキーワード（NEXT・WAITING など）
+	MetaUID       = "uid"         // 読み込み直したときに同じタスクを見分ける UUID（:UID: プロパティ）
+)
+
+// escape 見出しや本文の文字列が Org の記法として読まれないように付けるゼロ幅スペース
+// Org-mode のマニュアルで勧められているエスケープの方法で、読み込み時に取り除く
+const escape = "\u200b"
+
+var (
+	headlinePattern  = regexp.MustCompile(`^(\*+)\s+(.*?)\s*$`)
+	keywordPattern   = regexp.MustCompile(`^([A-Z]+)(?:\s+|$)`)
+	priorityPattern  = regexp.MustCompile(`^\[#([A-Z])\]\s*`)
+	tagsPattern      = regexp.MustCompile(`\s+(:[^\s:]+(?::[^\s:]+)*:)$`)
+	planningPattern  = regexp.MustCompile(`(CLOSED|DEADLINE|SCHEDULED):\s*([<\[][^\]>]*[\]>])`)
+	propertyPattern  = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	drawerPattern    = regexp.MustCompile(`^:[A-Za-z_-]+:$`)
+	timestampPattern = regexp.MustCompile(`^[<\[](\d{4}-\d{2}-\d{2})(?:\s+[^\s\d\]>]+)?(?:\s+(\d{1,2}:\d{2}))?[^\]>]*[\]>]$`)
+	notePattern      = regexp.MustCompile(`^-\s+(\[\d{4}-\d{2}-\d{2}[^\]]*\])\s+(.*)$`)
+)
+
+// todoKeywords タスクとして扱うキーワードと、完了を表すかどうか
+var todoKeywords = map[string]bool{
+	"TODO": false, "NEXT": false, "STARTED": false, "WAITING": false, "WAIT": false, "HOLD": false, "SOMEDAY": false,
+	"DONE": true, "CANCELLED": true, "CANCELED": true,
+}
+
+// Read Org ファイルの TODO キーワード付きの見出しをタスクとして読み込む
+// キーワードのない見出しは、その下の見出しのプロジェクト（CATEGORY がない場合）として扱う
+func Read(r io.Reader) (*format.Result, error) {
+	result := &format.Result{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var sections []string // 見出しの深さごとのキーワードのない見出し
+	var current *models.Task
+	currentLine := 0
+	inDrawer, inProperties := false, false
+	noteIndent, inList := -1, false // 続きの行を追加するメモの字下げ（メモがなければ -1）と、リストの項目かどうか
+	line := 0
+
+	finish := func() {
+		if current != nil {
+			result.Tasks = append(result.Tasks, current)
+		}
+		if inDrawer {
+			result.AddIssue(currentLine, "", ":END: のないドロワーがあります")
+		}
+		current, inDrawer, inProperties = nil, false, false
+		noteIndent = -1
+	}
+
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if m := headlinePattern.FindStringSubmatch(text); m != nil {
+			finish()
+			level := len(m[1])
+			task, section := parseHeadline(m[2])
+			for len(sections) < level {
+				sections = append(sections, "")
+			}
+			sections = sections[:level]
+			if task == nil {
+				sections[level-1] = section
+				continue
+			}
+			sections[level-1] = ""
+			if task.Title == "" {
+				result.AddIssue(line, text, "見出しの文字列がありません")
+				continue
+			}
+			for i := level - 2; i >= 0; i-- {
+				if sections[i] != "" {
+					task.Project = sections[i]
+					break
+				}
+			}
+			current, currentLine = task, line
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case inDrawer:
+			if strings.EqualFold(trimmed, ":END:") {
+				inDrawer, inProperties = false, false
+			} else if inProperties {
+				if m := propertyPattern.FindStringSubmatch(trimmed); m != nil {
+					applyProperty(current, strings.ToUpper(m[1]), strings.TrimSpace(m[2]), line, result)
+				}
+			}
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inDrawer, inProperties = true, true
+		case drawerPattern.MatchString(trimmed):
+			// LOGBOOK などのその他のドロワーは読み飛ばす
+			inDrawer = true
+		case !strings.HasPrefix(trimmed, escape) && planningPattern.MatchString(trimmed):
+			for _, m := range planningPattern.FindAllStringSubmatch(trimmed, -1) {
+				applyPlanning(current, m[1], m[2], line, result)
+			}
+		case trimmed == "":
+			// 空行でメモの段落やリストの項目が終わる
+			noteIndent = -1
+		default:
+			// リストの項目より深く字下げした行と、段落の続きの行は1つのメモにまとめる
+			indent := len(text) - len(strings.TrimLeft(text, " \t"))
+			isItem := strings.HasPrefix(trimmed, "- ")
+			if noteIndent >= 0 && !isItem && (!inList || indent > noteIndent) {
+				last := &current.Annotations[len(current.Annotations)-1]
+				last.Text += "\n" + strings.TrimPrefix(trimmed, escape)
+				continue
+			}
+			current.Annotations = append(current.Annotations, parseNote(trimmed))
+			noteIndent, inList = indent, isItem
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Orgファイルの読み込みに失敗しました: %w", err)
+	}
+	finish()
+	return result, nil
+}
+
+// parseHeadline 見出しの文字列を解釈する
+// TODO キーワードがある場合はタスクを、ない場合は見出しの文字列を返す
+func parseHeadline(text string) (*models.Task, string) {
+	m := keywordPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil, stripTags(text)
+	}
+	completed, ok := todoKeywords[m[1]]
+	if !ok {
+		return nil, stripTags(text)
+	}
+
+	task := &models.Task{Completed: completed}
+	if m[1] != "TODO" && m[1] != "DONE" {
+		setMeta(task, MetaKeyword, m[1])
+	}
+	rest := text[len(m[0]):]
+	if p := priorityPattern.FindStringSubmatch(rest); p != nil {
+		task.Priority = p[1]
+		rest = rest[len(p[0]):]
+	}
+	if t := tagsPattern.FindStringSubmatch(rest); t != nil {
+		task.Tags = strings.Split(strings.Trim(t[1], ":"), ":")
+		rest = rest[:len(rest)-len(t[0])]
+	}
+	task.Title = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(rest), escape), escape)
+	return task, ""
+}
+
+func stripTags(text string) string {
+	if t := tagsPattern.FindStringSubmatch(text); t != nil {
+		text = text[:len(text)-len(t[0])]
+	}
+	return strings.TrimSpace(text)
+}
+
+// applyPlanning CLOSED / DEADLINE / SCHEDULED をタスクに反映する
+func applyPlanning(task *models.Task, keyword, value string, line int, result *format.Result) {
+	t, hasTime, err := parseTimestamp(value)
+	if err != nil {
+		result.AddIssue(line, value, keyword+" の日時が不正です")
+		return
+	}
+	switch keyword {
+	case "CLOSED":
+		task.CompletedAt = &t
+	case "DEADLINE":
+		task.DueAt = &t
+	case "SCHEDULED":
+		setMeta(task, MetaScheduled, formatMetaTime(t, hasTime))
+	}
+}
+
+// applyProperty プロパティドロワーの値をタスクに反映する
+func applyProperty(task *models.Task, name, value string, line int, result *format.Result) {
+	switch name {
+	case PropertyID:
+		// 他のインストールのタスクIDと重なるので使わない（同じタスクは MetaUID で見分ける）
+	case PropertyStatus:
+		task.Status = value
+	case PropertyCategory:
+		task.Project = value
+	case PropertyCreated:
+		t, _, err := parseTimestamp(value)
+		if err != nil {
+			result.AddIssue(line, value, "作成日時が不正です")
+			return
+		}
+		task.CreatedAt = t
+	default:
+		setMeta(task, strings.ToLower(name), value)
+	}
+}
+
+// parseNote 本文の行をメモに変換する。"- [日時] 文字列" の形式の場合は日時を読み取る
+func parseNote(text string) models.Annotation {
+	if m := notePattern.FindStringSubmatch(text); m != nil {
+		if t, _, err := parseTimestamp(m[1]); err == nil {
+			return models.Annotation{Time: t, Text: strings.TrimPrefix(m[2], escape)}
+		}
+	}
+	return models.Annotation{Text: strings.TrimPrefix(strings.TrimPrefix(text, "- "), escape)}
+}
+
+// escapeTitle 見出しの文字列の先頭の "[#A]" や末尾の ":tag:" が優先度やタグとして読まれないようにする
+func escapeTitle(title string) string {
+	if priorityPattern.MatchString(title) {
+		title = escape + title
+	}
+	if tagsPattern.MatchString(" " + title) {
+		title += escape
+	}
+	return title
+}
+
+// escapeNote メモの行が日時・リストの項目・ドロワー・予定として読まれたり、字下げが失われたりしないようにする
+func escapeNote(text string) string {
+	if text == "" || strings.ContainsAny(text[:1], "-[: \t") || planningPattern.MatchString(text) {
+		return escape + text
+	}
+	return text
+}
+
+// parseTimestamp "<2025-01-10 Fri>" や "[2025-01-10 Fri 09:00]" の形式の日時を解釈する
+func parseTimestamp(value string) (time.Time, bool, error) {
+	m := timestampPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return time.Time{}, false, fmt.Errorf("日時の形式が不正です: %q", value)
+	}
+	if m[2] == "" {
+		t, err := time.ParseInLocation("2006-01-02", m[1], time.Local)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", m[1]+" "+m[2], time.Local)
+	return t, true, err
+}
+
+// formatTimestamp 日時を Org の日時に変換する。0時ちょうどの場合は日付だけにする
+func formatTimestamp(t time.Time, active bool) string {
+	open, close := "[", "]"
+	if active {
+		open, close = "<", ">"
+	}
+	t = t.Local()
+	s := t.Format("2006-01-02") + " " + t.Weekday().String()[:3]
+	if t.Hour() != 0 || t.Minute() != 0 {
+		s += " " + t.Format("15:04")
+	}
+	return open + s + close
+}
+
+func formatMetaTime(t time.Time, hasTime bool) string {
+	if hasTime {
+		return t.Format("2006-01-02 15:04")
+	}
+	return t.Format("2006-01-02")
+}
+
+// Write タスクを Org の見出しとして書き出す
+func Write(w io.Writer, tasks []*models.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, task := range tasks {
+		writeTask(bw, task)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("Orgファイルの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+func writeTask(w *bufio.Writer, task *models.Task) {
+	keyword := "TODO"
+	if task.Completed {
+		keyword = "DONE"
+	}
+	if k := task.Meta[MetaKeyword]; k != "" && todoKeywords[k] == task.Completed {
+		keyword = k
+	}
+	headline := "* " + keyword
+	if task.Priority != "" {
+		headline += " [#" + task.Priority + "]"
+	}
+	headline += " " + escapeTitle(task.Title)
+	if len(task.Tags) > 0 {
+		headline += " :" + strings.Join(task.Tags, ":") + ":"
+	}
+	fmt.Fprintln(w, headline)
+
+	var planning []string
+	if task.Completed && task.CompletedAt != nil {
+		planning = append(planning, "CLOSED: "+formatTimestamp(*task.CompletedAt, false))
+	}
+	if task.DueAt != nil {
+		planning = append(planning, "DEADLINE: "+formatTimestamp(*task.DueAt, true))
+	}
+	if scheduled := task.Meta[MetaScheduled]; scheduled != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04", scheduled, time.Local); err == nil {
+			planning = append(planning, "SCHEDULED: "+formatTimestamp(t, true))
+		} else if t, err := time.ParseInLocation("2006-01-02", scheduled, time.Local); err == nil {
+			planning = append(planning, "SCHEDULED: "+formatTimestamp(t, true))
+		}
+	}
+	if len(planning) > 0 {
+		fmt.Fprintln(w, "  "+strings.Join(planning, " "))
+	}
+
+	fmt.Fprintln(w, "  :PROPERTIES:")
+	if task.Project != "" {
+		fmt.Fprintf(w, "  :%s: %s\n", PropertyCategory, task.Project)
+	}
+	if task.Status != "" && !task.Completed {
+		fmt.Fprintf(w, "  :%s: %s\n", PropertyStatus, task.Status)
+	}
+	if !task.CreatedAt.IsZero() {
+		fmt.Fprintf(w, "  :%s: %s\n", PropertyCreated, formatTimestamp(task.CreatedAt, false))
+	}
+	keys := make([]string, 0, len(task.Meta))
+	for key := range task.Meta {
+		if key != MetaScheduled && key != MetaKeyword {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  :%s: %s\n", strings.ToUpper(key), task.Meta[key])
+	}
+	fmt.Fprintln(w, "  :END:")
+
+	// 複数行のメモは、2行目以降をリストの項目より深く字下げして1つの項目にする
+	for _, a := range task.Annotations {
+		lines := strings.Split(a.Text, "\n")
+		if a.Time.IsZero() {
+			fmt.Fprintln(w, "  - "+escapeNote(lines[0]))
+		} else {
+			fmt.Fprintf(w, "  - %s %s\n", formatTimestamp(a.Time, false), escapeNote(lines[0]))
+		}
+		for _, line := range lines[1:] {
+			fmt.Fprintln(w, "    "+escapeNote(line))
+		}
+	}
+}
+
+func setMeta(task *models.Task, key, value string) {
+	if task.Meta == nil {
+		task.Meta = map[string]string{}
+	}
+	task.Meta[key] = value
+}
@@ -0,0 +1,171 @@
+package org
+
+import (
+	"bytes"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, name string) *format.Result {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	result, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRead(t *testing.T) {
+	result := readFile(t, "tasks.org")
+	var titles []string
+	for _, task := range result.Tasks {
+		titles = append(titles, task.Title)
+	}
+	want := []string{"報告書を書く", "請求書を送る", "牛乳を買う", "古い予定", "期限が壊れている"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Fatalf("titles = %v, want %v", titles, want)
+	}
+
+	report := result.Tasks[0]
+	// 古いバージョンが書き出した GODO_ID は使わない
+	if report.ID != 0 || report.Priority != "A" || report.Project != "仕事" || !reflect.DeepEqual(report.Tags, []string{"office", "writing"}) {
+		t.Errorf("report = %+v", report)
+	}
+	if report.DueAt == nil || report.DueAt.Format("2006-01-02 15:04") != "2025-01-10 00:00" {
+		t.Errorf("DueAt = %v", report.DueAt)
+	}
+	if report.CreatedAt.Format("2006-01-02 15:04") != "2025-01-01 09:00" {
+		t.Errorf("CreatedAt = %v", report.CreatedAt)
+	}
+	if report.Meta[MetaScheduled] != "2025-01-08 09:30" || report.Meta["effort"] != "2:00" {
+		t.Errorf("Meta = %v", report.Meta)
+	}
+	if len(report.Annotations) != 2 || report.Annotations[0].Text != "数字はボブに確認する" || report.Annotations[0].Time.IsZero() ||
+		report.Annotations[1].Text != "テンプレートを使う" {
+		t.Errorf("Annotations = %+v", report.Annotations)
+	}
+
+	invoice := result.Tasks[1]
+	if !invoice.Completed || invoice.CompletedAt == nil || invoice.CompletedAt.Format("2006-01-02 15:04") != "2025-01-05 12:00" {
+		t.Errorf("invoice = %+v", invoice)
+	}
+
+	// CATEGORY は見出しより優先し、TODO / DONE 以外のキーワードは残す
+	milk := result.Tasks[2]
+	if milk.Project != "買い物" || milk.Completed || milk.Meta[MetaKeyword] != "NEXT" {
+		t.Errorf("milk = %+v", milk)
+	}
+	if cancelled := result.Tasks[3]; !cancelled.Completed || cancelled.Project != "個人" {
+		t.Errorf("cancelled = %+v", cancelled)
+	}
+	if len(result.Issues) != 2 || result.Issues[0].Line != 25 || result.Issues[1].Line != 26 {
+		t.Errorf("Issues = %v", result.Issues)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)
+	closed := time.Date(2025, 1, 5, 12, 0, 0, 0, time.Local)
+	due := time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)
+	tasks := []*models.Task{
+		{ID: 3, Title: "報告書を書く", Priority: "B", Project: "仕事", Tags: []string{"office"}, Status: "doing", DueAt: &due, CreatedAt: created,
+			Meta: map[string]string{MetaScheduled: "2025-01-08"}, Annotations: []models.Annotation{{Time: created, Text: "メモ"}}},
+		{ID: 4, Title: "請求書を送る", Completed: true, CompletedAt: &closed, CreatedAt: created},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"* TODO [#B] 報告書を書く :office:",
+		"  DEADLINE: <2025-01-10 Fri> SCHEDULED: <2025-01-08 Wed>",
+		"  :PROPERTIES:",
+		"  :CATEGORY: 仕事",
+		"  :GODO_STATUS: doing",
+		"  :CREATED: [2025-01-01 Wed 09:00]",
+		"  :END:",
+		"  - [2025-01-01 Wed 09:00] メモ",
+		"* DONE 請求書を送る",
+		"  CLOSED: [2025-01-05 Sun 12:00]",
+		"  :PROPERTIES:",
+		"  :CREATED: [2025-01-01 Wed 09:00]",
+		"  :END:",
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	result := readFile(t, "tasks.org")
+	var buf bytes.Buffer
+	if err := Write(&buf, result.Tasks); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Issues) != 0 || !reflect.DeepEqual(again.Tasks, result.Tasks) {
+		t.Errorf("往復変換で内容が変わりました\ngot:  %+v\nwant: %+v\n%s", again.Tasks, result.Tasks, buf.String())
+	}
+}
+
+func TestRoundTripEscapes(t *testing.T) {
+	noted := time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)
+	tasks := []*models.Task{
+		{Title: "[#A] で始まる見出し"},
+		{Title: "末尾がタグに見える :x:"},
+		{Title: "タグもある :x:", Tags: []string{"tag"}, Meta: map[string]string{MetaUID: "0b8f6c1e-1d2a-4c3b-9e4f-5a6b7c8d9e0f"}},
+		{Title: "複数行のメモ", Annotations: []models.Annotation{
+			{Time: noted, Text: "1行目\n2行目\n\n- リストに見える行\n  字下げした行\nDEADLINE: <2025-01-10 Fri>"},
+			{Text: "[2025-01-01] で始まるメモ"},
+			{Text: "段落1\n段落2"},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 0 || !reflect.DeepEqual(result.Tasks, tasks) {
+		t.Errorf("往復変換で内容が変わりました\ngot:  %+v\nwant: %+v\n%s", result.Tasks, tasks, buf.String())
+	}
+}
+
+func TestReadJoinsNoteLines(t *testing.T) {
+	input := strings.Join([]string{
+		"* TODO タスク",
+		"  - 項目の1行目",
+		"    項目の続き",
+		"  段落の1行目",
+		"  段落の続き",
+		"",
+		"  別の段落",
+	}, "\n")
+	result, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notes []string
+	for _, a := range result.Tasks[0].Annotations {
+		notes = append(notes, a.Text)
+	}
+	want := []string{"項目の1行目\n項目の続き", "段落の1行目\n段落の続き", "別の段落"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+}
@@ -0,0 +1,274 @@
+// Package taskwarrior は Taskwarrior の JSON 形式（task export / task import）とタスクを相互に変換する
+package taskwarrior
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timeLayout = "20060102T150405Z"
+
+// MetaUUID Taskwarrior のタスクの UUID を保存するメタ情報のキー
+const MetaUUID = "uuid"
+
+// MetaPrefix Taskwarrior から読み込んだ項目（wait や UDA など）を保存するメタ情報のキーの接頭辞
+// 書き出し時はこの接頭辞のキーだけを項目に戻し、他の形式から読み込んだメタ情報は書き出さない
+const MetaPrefix = "tw."
+
+// 独自に変換する項目と、読み込み時に捨てる Taskwarrior の内部的な項目
+// これ以外の文字列の項目（UDA など）は MetaPrefix を付けて Meta に保存し、書き出し時に戻す
+var knownFields = map[string]bool{
+	"id": true, "uuid": true, "description": true, "status": true, "entry": true,
+	"modified": true, "end": true, "due": true, "priority": true, "project": true,
+	"tags": true, "annotations": true, "urgency": true, "mask": true, "imask": true,
+}
+
+// Task Taskwarrior の JSON のタスク1件
+type Task struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry,omitempty"`
+	Modified    string       `json:"modified,omitempty"`
+	End         string       `json:"end,omitempty"`
+	Due         string       `json:"due,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Annotation Taskwarrior の注釈
+type Annotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// Read Taskwarrior の JSON を読み込む
+// "task export" の JSON 配列と、1行に1件ずつの形式の両方に対応する
+// 削除済み（deleted）のタスクと繰り返しのひな形（recurring）は読み込まずに報告する
+func Read(r io.Reader) (*format.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Taskwarriorの読み込みに失敗しました: %w", err)
+	}
+	result := &format.Result{}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var raws []json.RawMessage
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, fmt.Errorf("TaskwarriorのJSONを解釈できません: %w", err)
+		}
+		for i, raw := range raws {
+			readTask(raw, 0, fmt.Sprintf("%d件目", i+1), result)
+		}
+		return result, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		readTask([]byte(text), line, text, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Taskwarriorの読み込みに失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// readTask JSON のタスク1件を変換して result に追加する
+func readTask(raw []byte, line int, text string, result *format.Result) {
+	var tw Task
+	if err := json.Unmarshal(raw, &tw); err != nil {
+		result.AddIssue(line, text, "JSONを解釈できません: "+err.Error())
+		return
+	}
+	var fields map[string]any
+	json.Unmarshal(raw, &fields)
+
+	switch tw.Status {
+	case "deleted":
+		result.AddIssue(line, tw.Description, "削除済みのため読み込みません")
+		return
+	case "recurring":
+		result.AddIssue(line, tw.Description, "繰り返しのひな形のため読み込みません")
+		return
+	}
+	if strings.TrimSpace(tw.Description) == "" {
+		result.AddIssue(line, text, "description がありません")
+		return
+	}
+
+	task := &models.Task{
+		Title:     strings.TrimSpace(tw.Description),
+		Completed: tw.Status == "completed",
+		Priority:  fromPriority(tw.Priority),
+		Project:   tw.Project,
+		Tags:      tw.Tags,
+	}
+	if tw.UUID != "" {
+		setMeta(task, MetaUUID, tw.UUID)
+	}
+	parse := func(name, value string) *time.Time {
+		if value == "" {
+			return nil
+		}
+		t, err := time.Parse(timeLayout, value)
+		if err != nil {
+			result.AddIssue(line, value, name+" の日時が不正です")
+			return nil
+		}
+		t = t.Local()
+		return &t
+	}
+	if t := parse("entry", tw.Entry); t != nil {
+		task.CreatedAt = *t
+	}
+	if t := parse("modified", tw.Modified); t != nil {
+		task.UpdatedAt = *t
+	}
+	task.DueAt = parse("due", tw.Due)
+	if end := parse("end", tw.End); end != nil && task.Completed {
+		task.CompletedAt = end
+	}
+	for _, a := range tw.Annotations {
+		annotation := models.Annotation{Text: a.Description}
+		if t := parse("annotations.entry", a.Entry); t != nil {
+			annotation.Time = *t
+		}
+		task.Annotations = append(task.Annotations, annotation)
+	}
+
+	// wait や UDA などの項目は文字列にして残す（waiting のタスクは wait の日時まで非表示のタスク）
+	for key, value := range fields {
+		if knownFields[key] {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			setMeta(task, MetaPrefix+key, v)
+		case float64:
+			setMeta(task, MetaPrefix+key, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	result.Tasks = append(result.Tasks, task)
+}
+
+// Write タスクを Taskwarrior の JSON 配列（task import で読み込める形式）として書き出す
+func Write(w io.Writer, tasks []*models.Task) error {
+	out := make([]map[string]any, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, toMap(Convert(task), task.Meta))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("Taskwarriorの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Convert タスクを Taskwarrior の形式に変換する
+func Convert(task *models.Task) Task {
+	tw := Task{
+		UUID:        UUID(task),
+		Description: task.Title,
+		Status:      "pending",
+		Priority:    toPriority(task.Priority),
+		Project:     task.Project,
+		Tags:        task.Tags,
+	}
+	if task.Completed {
+		tw.Status = "completed"
+		tw.End = task.CompletedTime().UTC().Format(timeLayout)
+	} else if wait, err := time.Parse(timeLayout, task.Meta[MetaPrefix+"wait"]); err == nil && wait.After(time.Now()) {
+		tw.Status = "waiting"
+	}
+	if !task.CreatedAt.IsZero() {
+		tw.Entry = task.CreatedAt.UTC().Format(timeLayout)
+	}
+	if !task.UpdatedAt.IsZero() {
+		tw.Modified = task.UpdatedAt.UTC().Format(timeLayout)
+	}
+	if task.DueAt != nil {
+		tw.Due = task.DueAt.UTC().Format(timeLayout)
+	}
+	for _, a := range task.Annotations {
+		tw.Annotations = append(tw.Annotations, Annotation{Entry: a.Time.UTC().Format(timeLayout), Description: a.Text})
+	}
+	return tw
+}
+
+// toMap 変換したタスクに Taskwarrior から読み込んだ Meta の値を項目として加える
+func toMap(tw Task, meta map[string]string) map[string]any {
+	data, _ := json.Marshal(tw)
+	var m map[string]any
+	json.Unmarshal(data, &m)
+
+	for key, value := range meta {
+		name, ok := strings.CutPrefix(key, MetaPrefix)
+		if ok && name != "" && !knownFields[name] {
+			m[name] = value
+		}
+	}
+	return m
+}
+
+// UUID タスクの UUID を返す
+// Meta に記録した UUID（読み込んだタスクは元の UUID）を使い、記録がなければ新しい UUID を返す
+// 書き出すたびに同じ UUID にするには、format.EnsureID で MetaUUID に記録しておく
+func UUID(task *models.Task) string {
+	if uuid := task.Meta[MetaUUID]; uuid != "" {
+		return uuid
+	}
+	return format.NewUUID()
+}
+
+// fromPriority Taskwarrior の H / M / L を A / B / C に変換する
+func fromPriority(priority string) string {
+	switch priority {
+	case "H":
+		return "A"
+	case "M":
+		return "B"
+	case "L":
+		return "C"
+	}
+	return ""
+}
+
+// toPriority 優先度を Taskwarrior の H / M / L に変換する（C 以下は L）
+func toPriority(priority string) string {
+	switch priority {
+	case "":
+		return ""
+	case "A":
+		return "H"
+	case "B":
+		return "M"
+	}
+	return "L"
+}
+
+func setMeta(task *models.Task, key, value string) {
+	if task.Meta == nil {
+		task.Meta = map[string]string{}
+	}
+	task.Meta[key] = value
+}
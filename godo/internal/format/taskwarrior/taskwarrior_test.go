@@ -0,0 +1,139 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, name string) *format.Result {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	result, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReadExport(t *testing.T) {
+	result := readFile(t, "export.json")
+	var titles []string
+	for _, task := range result.Tasks {
+		titles = append(titles, task.Title)
+	}
+	// deleted・recurring（ひな形）・description のないタスクは読み込まない
+	want := []string{"Write report", "Renew passport", "Follow up with vendor", "Pay rent", "Bad date"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Fatalf("titles = %v, want %v", titles, want)
+	}
+	if len(result.Issues) != 4 {
+		t.Fatalf("Issues = %v", result.Issues)
+	}
+
+	report := result.Tasks[0]
+	if report.Priority != "A" || report.Project != "work.reports" || !reflect.DeepEqual(report.Tags, []string{"office", "writing"}) {
+		t.Errorf("report = %+v", report)
+	}
+	if !report.CreatedAt.Equal(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)) || !report.UpdatedAt.Equal(time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreatedAt = %v, UpdatedAt = %v", report.CreatedAt, report.UpdatedAt)
+	}
+	if report.DueAt == nil || !report.DueAt.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DueAt = %v", report.DueAt)
+	}
+	if len(report.Annotations) != 2 || report.Annotations[0].Text != "Ask Bob for numbers" {
+		t.Errorf("Annotations = %+v", report.Annotations)
+	}
+	if report.Meta[MetaUUID] != "5b0a6d1e-3c1f-4f3a-9a57-2f3c2d6f2f10" || len(report.Meta) != 1 {
+		t.Errorf("Meta = %v", report.Meta)
+	}
+
+	passport := result.Tasks[1]
+	if !passport.Completed || passport.CompletedAt == nil || passport.Priority != "C" {
+		t.Errorf("passport = %+v", passport)
+	}
+
+	// waiting は未完了のタスクとして読み込み、wait と UDA を残す
+	vendor := result.Tasks[2]
+	if vendor.Completed || vendor.Meta["tw.wait"] != "20300101T000000Z" || vendor.Meta["tw.estimate"] != "2h" {
+		t.Errorf("vendor = %+v", vendor)
+	}
+
+	// 繰り返しのひな形から作られたタスクは recur と parent を残す
+	rent := result.Tasks[3]
+	if rent.Meta["tw.recur"] != "monthly" || rent.Meta["tw.parent"] == "" {
+		t.Errorf("rent = %+v", rent)
+	}
+}
+
+func TestReadJSONLines(t *testing.T) {
+	result := readFile(t, "export.jsonl")
+	if len(result.Tasks) != 2 || !result.Tasks[1].Completed {
+		t.Fatalf("tasks = %+v", result.Tasks)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Line != 2 {
+		t.Errorf("Issues = %v", result.Issues)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	completed := created.Add(time.Hour)
+	tasks := []*models.Task{
+		{ID: 1, Title: "書く", Priority: "B", Project: "仕事", Tags: []string{"a"}, CreatedAt: created, UpdatedAt: created,
+			Annotations: []models.Annotation{{Time: created, Text: "メモ"}}, Meta: map[string]string{"tw.estimate": "2h", "tw.id": "ignored", "uid": "other-format", MetaUUID: "0b8f6c1e-1d2a-4c3b-9e4f-5a6b7c8d9e0f"}},
+		{ID: 2, Title: "済み", Completed: true, CompletedAt: &completed, CreatedAt: created, UpdatedAt: completed},
+		{ID: 3, Title: "待ち", CreatedAt: created, UpdatedAt: created, Meta: map[string]string{"tw.wait": "20991231T000000Z"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	var out []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("len = %d", len(out))
+	}
+	first := out[0]
+	if first["description"] != "書く" || first["priority"] != "M" || first["status"] != "pending" ||
+		first["entry"] != "20250101T090000Z" || first["estimate"] != "2h" || first["id"] != nil || first["uid"] != nil {
+		t.Errorf("first = %v", first)
+	}
+	if out[1]["status"] != "completed" || out[1]["end"] != "20250101T100000Z" {
+		t.Errorf("second = %v", out[1])
+	}
+	if out[2]["status"] != "waiting" {
+		t.Errorf("third = %v", out[2])
+	}
+	// 記録した UUID を使い、記録がなければ新しい UUID を作る（タスクIDからは作らない）
+	if out[0]["uuid"] != tasks[0].Meta[MetaUUID] || out[1]["uuid"] == out[2]["uuid"] || !strings.HasPrefix(out[1]["uuid"].(string)[14:], "4") {
+		t.Errorf("uuid = %v, %v, %v", out[0]["uuid"], out[1]["uuid"], out[2]["uuid"])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	result := readFile(t, "export.json")
+	var buf bytes.Buffer
+	if err := Write(&buf, result.Tasks); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Issues) != 0 || !reflect.DeepEqual(again.Tasks, result.Tasks) {
+		t.Errorf("往復変換で内容が変わりました\ngot:  %+v\nwant: %+v", again.Tasks, result.Tasks)
+	}
+}
@@ -0,0 +1,273 @@
+// Package todotxt は todo.txt 形式（https://github.com/todotxt/todo.txt）とタスクを相互に変換する
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"godo/internal/format"
+	"godo/pkg/models"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	priorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	keyPattern      = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	// 拡張と同じ "数字:値" の形の語（時刻など）
+	numericKeyPattern = regexp.MustCompile(`^[0-9]+:[^:]+$`)
+	valueEscaper      = strings.NewReplacer(`\`, `\\`, " ", `\s`, ":", `\c`)
+)
+
+// Read todo.txt を読み込む
+// 空行は無視し、タスクに変換できなかった行や値は Result.Issues に報告する
+func Read(r io.Reader) (*format.Result, error) {
+	result := &format.Result{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		task, problems := ParseLine(text)
+		for _, p := range problems {
+			result.AddIssue(line, text, p)
+		}
+		if task != nil {
+			result.Tasks = append(result.Tasks, task)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("todo.txtの読み込みに失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// ParseLine todo.txt の1行をタスクに変換する
+// タスクにできない行はnilを返し、変換できなかった値や、どう読むか曖昧な値は問題として返す
+// "\" で始まる語は記号の意味を持たない説明の一部として、プロジェクト・タグ・コンテキスト・拡張の値の
+// "\s" は空白、"\c" は ":"、"\\" は "\" として読む（FormatLine が書き出すエスケープ）
+func ParseLine(line string) (*models.Task, []string) {
+	task := &models.Task{}
+	var problems []string
+	fields := strings.Fields(line)
+
+	// 完了マークと完了日
+	if len(fields) > 0 && fields[0] == "x" {
+		task.Completed = true
+		fields = fields[1:]
+		if len(fields) > 0 && datePattern.MatchString(fields[0]) {
+			if t, err := time.ParseInLocation(dateLayout, fields[0], time.Local); err == nil {
+				task.CompletedAt = &t
+				fields = fields[1:]
+			}
+		}
+	}
+	// 優先度（未完了のタスクのみ）
+	if !task.Completed && len(fields) > 0 && priorityPattern.MatchString(fields[0]) {
+		task.Priority = fields[0][1:2]
+		fields = fields[1:]
+	}
+	// 作成日
+	if len(fields) > 0 && datePattern.MatchString(fields[0]) {
+		if t, err := time.ParseInLocation(dateLayout, fields[0], time.Local); err == nil {
+			task.CreatedAt = t
+			fields = fields[1:]
+		}
+	}
+
+	var words []string
+	seen := map[string]bool{}
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '\\':
+			words = append(words, field[1:])
+		case len(field) > 1 && field[0] == '+':
+			if task.Project == "" {
+				task.Project = unescapeValue(field[1:])
+			} else {
+				task.Tags = append(task.Tags, unescapeValue(field[1:]))
+			}
+		case len(field) > 1 && field[0] == '@':
+			task.Contexts = append(task.Contexts, unescapeValue(field[1:]))
+		case isExtension(field):
+			key, value, _ := strings.Cut(field, ":")
+			if seen[key] {
+				problems = append(problems, fmt.Sprintf("%s: が複数あるため、最後の値を使います", key))
+			}
+			seen[key] = true
+			if problem := applyExtension(task, key, unescapeValue(value)); problem != "" {
+				problems = append(problems, problem)
+			}
+		case numericKeyPattern.MatchString(field):
+			problems = append(problems, fmt.Sprintf("%q は拡張ではなく説明の一部として読み込みます", field))
+			words = append(words, field)
+		default:
+			words = append(words, field)
+		}
+	}
+
+	task.Title = strings.Join(words, " ")
+	if task.Title == "" {
+		return nil, append(problems, "タスクの説明がありません")
+	}
+	if task.Completed && task.CompletedAt == nil {
+		// 完了日のない完了タスクは作成日を完了日とみなす
+		if !task.CreatedAt.IsZero() {
+			completed := task.CreatedAt
+			task.CompletedAt = &completed
+		}
+	}
+	if task.Completed {
+		task.Status = ""
+	}
+	return task, problems
+}
+
+// isExtension "key:value" 形式の拡張かを返す（URLなどは除く）
+func isExtension(field string) bool {
+	key, value, ok := strings.Cut(field, ":")
+	return ok && keyPattern.MatchString(key) && value != "" && !strings.Contains(value, ":") && !strings.HasPrefix(value, "//")
+}
+
+// applyExtension "key:value" 形式の拡張をタスクに反映する
+// 対応する項目がない拡張は Meta に保存する
+func applyExtension(task *models.Task, key, value string) string {
+	switch key {
+	case "due":
+		t, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			setMeta(task, key, value)
+			return fmt.Sprintf("期限の日付が不正です: %q", value)
+		}
+		task.DueAt = &t
+	case "pri":
+		if models.ValidatePriority(value) != nil {
+			setMeta(task, key, value)
+			return fmt.Sprintf("優先度が不正です: %q", value)
+		}
+		task.Priority = value
+	case "status":
+		task.Status = value
+	default:
+		setMeta(task, key, value)
+	}
+	return ""
+}
+
+// unescapeValue FormatLine がエスケープした値を元に戻す
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			switch value[i+1] {
+			case '\\':
+				sb.WriteByte('\\')
+				i++
+				continue
+			case 's':
+				sb.WriteByte(' ')
+				i++
+				continue
+			case 'c':
+				sb.WriteByte(':')
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(value[i])
+	}
+	return sb.String()
+}
+
+// escapeWord 説明の語が日付・優先度・完了マーク・プロジェクトなどとして読まれる場合は "\" を付ける
+func escapeWord(word string, first bool) string {
+	ambiguous := strings.HasPrefix(word, `\`) ||
+		(len(word) > 1 && (word[0] == '+' || word[0] == '@')) ||
+		isExtension(word) || numericKeyPattern.MatchString(word) ||
+		(first && (word == "x" || priorityPattern.MatchString(word) || datePattern.MatchString(word)))
+	if ambiguous {
+		return `\` + word
+	}
+	return word
+}
+
+func setMeta(task *models.Task, key, value string) {
+	if task.Meta == nil {
+		task.Meta = map[string]string{}
+	}
+	task.Meta[key] = value
+}
+
+// FormatLine タスクを todo.txt の1行に変換する
+// ParseLine で同じタスクに戻るように、記号として読まれる説明の語や、空白・":" を含む値をエスケープする
+func FormatLine(task *models.Task) string {
+	var parts []string
+	if task.Completed {
+		parts = append(parts, "x")
+		if task.CompletedAt != nil {
+			parts = append(parts, task.CompletedAt.Format(dateLayout))
+		}
+	} else if task.Priority != "" {
+		parts = append(parts, "("+task.Priority+")")
+	}
+	if !task.CreatedAt.IsZero() {
+		parts = append(parts, task.CreatedAt.Format(dateLayout))
+	}
+
+	for i, word := range strings.Fields(task.Title) {
+		parts = append(parts, escapeWord(word, i == 0))
+	}
+	if task.Project != "" {
+		parts = append(parts, "+"+valueEscaper.Replace(task.Project))
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "+"+valueEscaper.Replace(tag))
+	}
+	for _, context := range task.Contexts {
+		parts = append(parts, "@"+valueEscaper.Replace(context))
+	}
+
+	// 完了したタスクは優先度を "pri:" 拡張で残す
+	if task.Completed && task.Priority != "" {
+		parts = append(parts, "pri:"+task.Priority)
+	}
+	if task.DueAt != nil {
+		parts = append(parts, "due:"+task.DueAt.Format(dateLayout))
+	}
+	if task.Status != "" && !task.Completed {
+		parts = append(parts, "status:"+valueEscaper.Replace(task.Status))
+	}
+	keys := make([]string, 0, len(task.Meta))
+	for key := range task.Meta {
+		// 拡張のキーにできない（読み込めない）メタ情報は書き出さない
+		if keyPattern.MatchString(key) && task.Meta[key] != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+":"+valueEscaper.Replace(task.Meta[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Write タスクを todo.txt 形式で書き出す
+func Write(w io.Writer, tasks []*models.Task) error {
+	for _, task := range tasks {
+		if _, err := fmt.Fprintln(w, FormatLine(task)); err != nil {
+			return fmt.Errorf("todo.txtの書き込みに失敗しました: %w", err)
+		}
+	}
+	return nil
+}
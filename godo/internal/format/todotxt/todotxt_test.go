@@ -0,0 +1,146 @@
+package todotxt
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"godo/pkg/models"
+)
+
+func TestParseLine(t *testing.T) {
+	task, problems := ParseLine("(A) 2025-01-02 電話をかける +仕事 +電話 @オフィス due:2025-01-10 id:42")
+	if len(problems) != 0 {
+		t.Fatalf("想定外の問題: %v", problems)
+	}
+	if task.Title != "電話をかける" {
+		t.Errorf("Title = %q", task.Title)
+	}
+	if task.Priority != "A" {
+		t.Errorf("Priority = %q", task.Priority)
+	}
+	if task.CreatedAt.Format(dateLayout) != "2025-01-02" {
+		t.Errorf("CreatedAt = %v", task.CreatedAt)
+	}
+	if task.Project != "仕事" || len(task.Tags) != 1 || task.Tags[0] != "電話" {
+		t.Errorf("Project = %q, Tags = %v", task.Project, task.Tags)
+	}
+	if len(task.Contexts) != 1 || task.Contexts[0] != "オフィス" {
+		t.Errorf("Contexts = %v", task.Contexts)
+	}
+	if task.DueAt == nil || task.DueAt.Format(dateLayout) != "2025-01-10" {
+		t.Errorf("DueAt = %v", task.DueAt)
+	}
+	if task.Meta["id"] != "42" {
+		t.Errorf("Meta = %v", task.Meta)
+	}
+}
+
+func TestParseLineCompleted(t *testing.T) {
+	task, _ := ParseLine("x 2025-01-05 2025-01-01 報告書を書く pri:B")
+	if !task.Completed {
+		t.Fatal("完了タスクとして読み込まれていません")
+	}
+	if task.CompletedAt == nil || task.CompletedAt.Format(dateLayout) != "2025-01-05" {
+		t.Errorf("CompletedAt = %v", task.CompletedAt)
+	}
+	if task.CreatedAt.Format(dateLayout) != "2025-01-01" {
+		t.Errorf("CreatedAt = %v", task.CreatedAt)
+	}
+	if task.Priority != "B" {
+		t.Errorf("Priority = %q", task.Priority)
+	}
+}
+
+func TestParseLineKeepsURL(t *testing.T) {
+	task, _ := ParseLine("資料を読む https://example.com/doc")
+	if task.Title != "資料を読む https://example.com/doc" {
+		t.Errorf("Title = %q", task.Title)
+	}
+	if len(task.Meta) != 0 {
+		t.Errorf("URLが拡張として読み込まれています: %v", task.Meta)
+	}
+}
+
+func TestReadReportsIssues(t *testing.T) {
+	input := "買い物 due:明日\n\n+仕事 @自宅\n掃除\n"
+	result, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tasks) != 2 {
+		t.Fatalf("タスク数 = %d, want 2", len(result.Tasks))
+	}
+	if len(result.Issues) != 2 {
+		t.Fatalf("問題の数 = %d, want 2: %v", len(result.Issues), result.Issues)
+	}
+	if result.Issues[0].Line != 1 || result.Issues[1].Line != 3 {
+		t.Errorf("行番号が不正です: %v", result.Issues)
+	}
+	// 解釈できなかった期限は失わずに残す
+	if result.Tasks[0].Meta["due"] != "明日" {
+		t.Errorf("Meta = %v", result.Tasks[0].Meta)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := strings.Join([]string{
+		"(A) 2025-01-02 電話をかける +仕事 +電話 @オフィス due:2025-01-10 id:42",
+		"x 2025-01-05 2025-01-01 報告書を書く +仕事 pri:B",
+		"買い物 @外出 status:doing",
+	}, "\n") + "\n"
+
+	result, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, result.Tasks); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != input {
+		t.Errorf("往復変換で内容が変わりました\ngot:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestFormatLineRoundTrip(t *testing.T) {
+	created := time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		task *models.Task
+	}{
+		{"完了マークで始まる説明", &models.Task{Title: "x marks the spot"}},
+		{"優先度で始まる説明", &models.Task{Title: "(B) から始まる"}},
+		{"日付で始まる説明", &models.Task{Title: "2025-03-01 の会議"}},
+		{"作成日と日付で始まる説明", &models.Task{Title: "2025-03-01 の会議", CreatedAt: created}},
+		{"時刻を含む説明", &models.Task{Title: "会議 10:30 から"}},
+		{"記号で始まる語", &models.Task{Title: "+1 する @mention key:value \\path"}},
+		{"空白を含むプロジェクト", &models.Task{Title: "a", Project: "my project", Tags: []string{"tag a"}, Contexts: []string{"at home"}}},
+		{"空白と : を含むメタ情報", &models.Task{Title: "a", Meta: map[string]string{"note": "a b:c", "tw.wait": "20300101T000000Z"}}},
+		{"状態", &models.Task{Title: "a", Status: "in review"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := FormatLine(tt.task)
+			got, problems := ParseLine(line)
+			if len(problems) != 0 {
+				t.Fatalf("%q: 想定外の問題: %v", line, problems)
+			}
+			if !reflect.DeepEqual(got, tt.task) {
+				t.Errorf("%q:\ngot:  %+v\nwant: %+v", line, got, tt.task)
+			}
+		})
+	}
+}
+
+func TestParseLineReportsAmbiguousTokens(t *testing.T) {
+	task, problems := ParseLine("会議 10:30 id:1 id:2")
+	if task.Title != "会議 10:30" || task.Meta["id"] != "2" {
+		t.Fatalf("task = %+v", task)
+	}
+	if len(problems) != 2 {
+		t.Errorf("problems = %v", problems)
+	}
+}
@@ -0,0 +1,260 @@
+// Package hooks はタスクの追加・変更・完了・削除のときにユーザーのスクリプトを実行する
+//
+// ~/.godo/hooks に置いた実行ファイルを、タスクを保存する前に呼び出す。
+//
+//	on-add       追加したタスク
+//	on-modify    変更前のタスクと変更後のタスク（1行に1件）
+//	on-complete  完了にした変更。標準入力は on-modify と同じ
+//	on-delete    削除したタスク
+//
+// タスクは1行の JSON で標準入力に渡す。終了コードが 0 以外なら変更を取り消し、
+// 出力をその理由として表示する。終了コードが 0 で、標準出力の最初の行がタスクの JSON なら
+// 変更後のタスクをその内容で書き換える（on-delete を除く）。残りの行はメッセージとして表示する。
+// アーカイブとアーカイブからの復元ではフックを実行しない。
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// フックの名前（~/.godo/hooks に置く実行ファイル名）
+const (
+	OnAdd      = "on-add"
+	OnModify   = "on-modify"
+	OnComplete = "on-complete"
+	OnDelete   = "on-delete"
+)
+
+// DefaultTimeout フックの実行を打ち切るまでの時間
+const DefaultTimeout = 5 * time.Second
+
+// Runner フックを実行する
+type Runner struct {
+	Dir     string        // フックを置くディレクトリ
+	Timeout time.Duration // 1回の実行を打ち切るまでの時間
+}
+
+// NewRunner dir のフックを実行する Runner を作成する
+func NewRunner(dir string) *Runner {
+	return &Runner{Dir: dir, Timeout: DefaultTimeout}
+}
+
+// DefaultDir フックを置くディレクトリ（~/.godo/hooks）を返す
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".godo", "hooks")
+}
+
+// VetoError フックが変更を取り消したことを表すエラー
+type VetoError struct {
+	Hook   string
+	TaskID int
+	Reason string
+}
+
+func (e *VetoError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("フック %s が ID %d のタスクの変更を取り消しました", e.Hook, e.TaskID)
+	}
+	return fmt.Sprintf("フック %s が ID %d のタスクの変更を取り消しました: %s", e.Hook, e.TaskID, e.Reason)
+}
+
+// Snapshot 保存済みのタスクの内容として覚えておくためのコピーを返す
+// Run に渡して、保存するまでの変更を見つけるのに使う
+func Snapshot(tasks []*models.Task) []*models.Task {
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		return nil
+	}
+	var copied []*models.Task
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil
+	}
+	return copied
+}
+
+// Run before（前回の保存時のタスク）からの変更ごとにフックを実行する
+// 取り消された変更は tm から元に戻し、書き換えられた変更は tm に反映する
+// フックが表示したメッセージを返し、取り消された変更があれば VetoError をまとめたエラーを返す
+func (r *Runner) Run(tm *models.TaskManager, before []*models.Task) ([]string, error) {
+	if r == nil || !r.any() {
+		return nil, nil
+	}
+
+	old := map[int]*models.Task{}
+	oldIndex := map[int]int{}
+	for i, task := range before {
+		old[task.ID] = task
+		oldIndex[task.ID] = i
+	}
+	var messages []string
+	var errs []error
+	handle := func(hook string, id int, lines []string, err error) {
+		messages = append(messages, lines...)
+		if err != nil {
+			var veto *VetoError
+			if !errors.As(err, &veto) {
+				err = &VetoError{Hook: hook, TaskID: id, Reason: err.Error()}
+			}
+			errs = append(errs, err)
+			tm.RevertTask(id, old[id], oldIndex[id])
+		}
+	}
+
+	current := map[int]bool{}
+	for _, task := range append([]*models.Task{}, tm.GetTasks()...) {
+		current[task.ID] = true
+		prev, existed := old[task.ID]
+		var hook string
+		var input []*models.Task
+		switch {
+		case !existed && lastEvent(tm, task.ID) == models.EventRestored:
+			continue
+		case !existed:
+			hook, input = OnAdd, []*models.Task{task}
+		case !changed(prev, task):
+			continue
+		case task.Completed && !prev.Completed:
+			hook, input = OnComplete, []*models.Task{prev, task}
+		default:
+			hook, input = OnModify, []*models.Task{prev, task}
+		}
+		rewritten, out, err := r.exec(hook, task.ID, input)
+		if err == nil && rewritten != nil {
+			tm.ApplyTask(tm.FindIndexByID(task.ID), rewritten)
+		}
+		handle(hook, task.ID, out, err)
+	}
+	for _, prev := range before {
+		// アーカイブは削除として扱わない
+		if current[prev.ID] || lastEvent(tm, prev.ID) == models.EventArchived {
+			continue
+		}
+		_, out, err := r.exec(OnDelete, prev.ID, []*models.Task{prev})
+		handle(OnDelete, prev.ID, out, err)
+	}
+	return messages, errors.Join(errs...)
+}
+
+// any フックが1つでも置かれているか
+func (r *Runner) any() bool {
+	for _, hook := range []string{OnAdd, OnModify, OnComplete, OnDelete} {
+		if r.path(hook) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// path フックの実行ファイルのパスを返す（置かれていなければ空）
+func (r *Runner) path(hook string) string {
+	path := filepath.Join(r.Dir, hook)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return ""
+	}
+	return path
+}
+
+// exec フックを実行する。フックが置かれていなければ何もしない
+// 書き換えられたタスク（なければ nil）とメッセージを返す
+func (r *Runner) exec(hook string, id int, tasks []*models.Task) (*models.Task, []string, error) {
+	path := r.path(hook)
+	if path == "" {
+		return nil, nil, nil
+	}
+	var stdin bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	for _, task := range tasks {
+		if err := enc.Encode(task); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = &stdin
+	cmd.Env = append(os.Environ(), "GODO_HOOK="+hook, fmt.Sprintf("GODO_TASK_ID=%d", id))
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, nil, &VetoError{Hook: hook, TaskID: id, Reason: fmt.Sprintf("%s 以内に終了しませんでした", timeout)}
+	}
+	lines := outputLines(stdout.String())
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, nil, err
+		}
+		reason := strings.Join(append(lines, outputLines(stderr.String())...), " ")
+		return nil, nil, &VetoError{Hook: hook, TaskID: id, Reason: reason}
+	}
+
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "{") || hook == OnDelete {
+		return nil, lines, nil
+	}
+	var rewritten models.Task
+	if err := json.Unmarshal([]byte(lines[0]), &rewritten); err != nil {
+		return nil, nil, &VetoError{Hook: hook, TaskID: id, Reason: fmt.Sprintf("出力したタスクの JSON を解釈できません: %v", err)}
+	}
+	rewritten.Title = strings.TrimSpace(rewritten.Title)
+	if rewritten.Title == "" {
+		return nil, nil, &VetoError{Hook: hook, TaskID: id, Reason: "出力したタスクのタイトルが空です"}
+	}
+	if err := models.ValidatePriority(rewritten.Priority); err != nil {
+		return nil, nil, &VetoError{Hook: hook, TaskID: id, Reason: err.Error()}
+	}
+	return &rewritten, lines[1:], nil
+}
+
+// lastEvent タスクの最後の変更の種類を返す
+func lastEvent(tm *models.TaskManager, id int) models.EventType {
+	events := tm.History(id)
+	if len(events) == 0 {
+		return ""
+	}
+	return events[len(events)-1].Type
+}
+
+// changed タスクの内容が変わったか
+func changed(before, after *models.Task) bool {
+	a, errA := json.Marshal(before)
+	b, errB := json.Marshal(after)
+	return errA != nil || errB != nil || !bytes.Equal(a, b)
+}
+
+// outputLines フックの出力を空行を除いた行に分ける
+func outputLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,211 @@
+package hooks
+
+import (
+	"errors"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeHook シェルスクリプトのフックを置く
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトのフックは Windows では実行できません")
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newManager 保存済みのタスクとして titles を持つ TaskManager と、その時点のスナップショットを返す
+func newManager(titles ...string) (*models.TaskManager, []*models.Task) {
+	tm := models.NewTaskManager([]*models.Task{})
+	for _, title := range titles {
+		tm.AddTask(title)
+	}
+	tm.TakePendingEvents()
+	return tm, Snapshot(tm.GetTasks())
+}
+
+func TestRunWithoutHooks(t *testing.T) {
+	r := NewRunner(t.TempDir())
+	tm, before := newManager("牛乳を買う")
+	tm.AddTask("パンを買う")
+	messages, err := r.Run(tm, before)
+	if err != nil || messages != nil || len(tm.GetTasks()) != 2 {
+		t.Fatalf("Run = %v, %v", messages, err)
+	}
+
+	// 実行権限のないファイルはフックとして扱わない
+	if runtime.GOOS != "windows" {
+		os.WriteFile(filepath.Join(r.Dir, OnAdd), []byte("#!/bin/sh\nexit 1\n"), 0644)
+		tm.AddTask("卵を買う")
+		if _, err := r.Run(tm, before); err != nil {
+			t.Fatalf("non-executable hook ran: %v", err)
+		}
+	}
+}
+
+func TestOnAddVetoes(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, OnAdd, `grep -q '"title":"禁止' && { echo "禁止されたタスクです"; exit 1; }
+echo "追加を記録しました"
+`)
+	r := NewRunner(dir)
+	tm, before := newManager("牛乳を買う")
+	tm.AddTask("禁止のタスク")
+	tm.AddTask("パンを買う")
+
+	messages, err := r.Run(tm, before)
+	var veto *VetoError
+	if !errors.As(err, &veto) || veto.Hook != OnAdd || veto.TaskID != 2 || veto.Reason != "禁止されたタスクです" {
+		t.Fatalf("err = %v", err)
+	}
+	if len(messages) != 1 || messages[0] != "追加を記録しました" {
+		t.Errorf("messages = %v", messages)
+	}
+	tasks := tm.GetTasks()
+	if len(tasks) != 2 || tasks[1].Title != "パンを買う" {
+		t.Fatalf("tasks = %v", tasks)
+	}
+	// 取り消したタスクの変更履歴は保存しない
+	for _, e := range tm.TakePendingEvents() {
+		if e.TaskID == 2 {
+			t.Errorf("pending event for vetoed task: %+v", e)
+		}
+	}
+	if len(tm.History(2)) != 0 {
+		t.Errorf("history for vetoed task: %v", tm.History(2))
+	}
+}
+
+func TestOnAddRewrites(t *testing.T) {
+	dir := t.TempDir()
+	// +release を含むタスクに優先度とタグを付ける
+	writeHook(t, dir, OnAdd, `read task
+echo "$task" | sed -e 's/"title":"\([^"]*\) +release"/"title":"\1","priority":"A","tags":["release"]/'
+echo "リリースのタスクです"
+`)
+	r := NewRunner(dir)
+	tm, before := newManager()
+	tm.AddTask("v1.2 を出す +release")
+
+	messages, err := r.Run(tm, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := tm.GetTaskByIndex(0)
+	if task.ID != 1 || task.Title != "v1.2 を出す" || task.Priority != "A" || len(task.Tags) != 1 || task.Tags[0] != "release" {
+		t.Fatalf("task = %+v", task)
+	}
+	if len(messages) != 1 || messages[0] != "リリースのタスクです" {
+		t.Errorf("messages = %v", messages)
+	}
+}
+
+func TestOnModifyAndOnComplete(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	writeHook(t, dir, OnModify, `echo "modify" >> `+log+`
+cat >> `+log+`
+`)
+	writeHook(t, dir, OnComplete, `echo "complete $GODO_TASK_ID" >> `+log+`
+`)
+	r := NewRunner(dir)
+	tm, before := newManager("牛乳を買う", "パンを買う", "卵を買う")
+	tm.UpdateTask(0, "豆乳を買う")
+	tm.ToggleTask(1)
+
+	if _, err := r.Run(tm, before); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(log)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || lines[0] != "modify" || lines[3] != "complete 2" {
+		t.Fatalf("log = %q", data)
+	}
+	// on-modify には変更前と変更後のタスクを1行ずつ渡す
+	if !strings.Contains(lines[1], `"title":"牛乳を買う"`) || !strings.Contains(lines[2], `"title":"豆乳を買う"`) {
+		t.Errorf("stdin = %q, %q", lines[1], lines[2])
+	}
+}
+
+func TestOnModifyVetoRestoresTask(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, OnModify, "echo 変更できません >&2\nexit 2\n")
+	r := NewRunner(dir)
+	tm, before := newManager("牛乳を買う")
+	tm.UpdateTask(0, "豆乳を買う")
+	tm.SetPriority(0, "B")
+
+	_, err := r.Run(tm, before)
+	if err == nil || !strings.Contains(err.Error(), "変更できません") {
+		t.Fatalf("err = %v", err)
+	}
+	if task := tm.GetTaskByIndex(0); task.Title != "牛乳を買う" || task.Priority != "" {
+		t.Fatalf("task = %+v", task)
+	}
+	if events := tm.TakePendingEvents(); len(events) != 0 {
+		t.Errorf("pending = %v", events)
+	}
+}
+
+func TestOnDeleteVetoRestoresPosition(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, OnDelete, "exit 1\n")
+	r := NewRunner(dir)
+	tm, before := newManager("牛乳を買う", "パンを買う", "卵を買う")
+	tm.DeleteTask(1)
+
+	if _, err := r.Run(tm, before); err == nil {
+		t.Fatal("expected veto")
+	}
+	tasks := tm.GetTasks()
+	if len(tasks) != 3 || tasks[1].Title != "パンを買う" {
+		t.Fatalf("tasks = %v", tasks)
+	}
+}
+
+func TestArchiveDoesNotRunOnDelete(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, OnDelete, "exit 1\n")
+	writeHook(t, dir, OnComplete, "exit 0\n")
+	r := NewRunner(dir)
+	tm, _ := newManager("牛乳を買う")
+	tm.ToggleTask(0)
+	before := Snapshot(tm.GetTasks())
+	tm.ArchiveCompleted(0, time.Now().Add(time.Hour))
+
+	if _, err := r.Run(tm, before); err != nil {
+		t.Fatalf("archive ran on-delete: %v", err)
+	}
+	if len(tm.GetTasks()) != 0 {
+		t.Fatalf("tasks = %v", tm.GetTasks())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, OnAdd, "sleep 5\n")
+	r := NewRunner(dir)
+	r.Timeout = 100 * time.Millisecond
+	tm, before := newManager()
+	tm.AddTask("牛乳を買う")
+
+	start := time.Now()
+	_, err := r.Run(tm, before)
+	if err == nil || !strings.Contains(err.Error(), "以内に終了しませんでした") {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("took %s", elapsed)
+	}
+	if len(tm.GetTasks()) != 0 {
+		t.Errorf("tasks = %v", tm.GetTasks())
+	}
+}
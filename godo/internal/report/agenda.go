@@ -0,0 +1,159 @@
+package report
+
+import (
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"sort"
+	"time"
+)
+
+// AgendaGroup 期限で分類したタスクのグループ
+type AgendaGroup struct {
+	Label string
+	Tasks []*models.Task
+}
+
+// アジェンダのグループ名（表示順）
+const (
+	AgendaOverdue  = "期限切れ"
+	AgendaToday    = "今日"
+	AgendaTomorrow = "明日"
+	AgendaThisWeek = "今週"
+	AgendaLater    = "それ以降"
+	AgendaNoDate   = "期限なし"
+)
+
+var agendaLabels = []string{AgendaOverdue, AgendaToday, AgendaTomorrow, AgendaThisWeek, AgendaLater, AgendaNoDate}
+
+// BuildAgenda 未完了のタスクを期限で分類する
+// グループは常に表示順で全て返し、各グループ内は期限の早い順に並べる
+func BuildAgenda(tasks []*models.Task, now time.Time) []AgendaGroup {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	dayAfterTomorrow := today.AddDate(0, 0, 2)
+	_, endOfWeek := WeekRange(now)
+
+	groups := make([]AgendaGroup, len(agendaLabels))
+	for i, label := range agendaLabels {
+		groups[i].Label = label
+	}
+
+	for _, task := range tasks {
+		if task.Completed {
+			continue
+		}
+		var group int
+		switch {
+		case task.DueAt == nil:
+			group = 5
+		case task.DueAt.Before(today):
+			group = 0
+		case task.DueAt.Before(tomorrow):
+			group = 1
+		case task.DueAt.Before(dayAfterTomorrow):
+			group = 2
+		case task.DueAt.Before(endOfWeek):
+			group = 3
+		default:
+			group = 4
+		}
+		groups[group].Tasks = append(groups[group].Tasks, task)
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.Tasks, func(i, j int) bool {
+			a, b := g.Tasks[i].DueAt, g.Tasks[j].DueAt
+			if a == nil || b == nil {
+				return false
+			}
+			return a.Before(*b)
+		})
+	}
+	return groups
+}
+
+// DueWithin 期限が今日からdays日以内（期限切れを含む）のタスクだけを返す
+func DueWithin(tasks []*models.Task, now time.Time, days int) []*models.Task {
+	limit := startOfDay(now).AddDate(0, 0, days)
+	var result []*models.Task
+	for _, task := range tasks {
+		if task.DueAt != nil && task.DueAt.Before(limit) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// DueCounts 未完了のタスク数を期限の日付（"2006-01-02"）ごとに数える
+func DueCounts(tasks []*models.Task) map[string]int {
+	counts := map[string]int{}
+	for _, task := range tasks {
+		if task.Completed || task.DueAt == nil {
+			continue
+		}
+		counts[task.DueAt.Format("2006-01-02")]++
+	}
+	return counts
+}
+
+// MonthGrid dayを含む月のカレンダーを週（月曜始まり）ごとの行で返す
+// 月の範囲外のマスはゼロ値になる
+func MonthGrid(day time.Time) [][]time.Time {
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	offset := (int(first.Weekday()) + 6) % 7
+
+	var weeks [][]time.Time
+	week := make([]time.Time, 7)
+	col := offset
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		week[col] = d
+		col++
+		if col == 7 {
+			weeks = append(weeks, week)
+			week = make([]time.Time, 7)
+			col = 0
+		}
+	}
+	if col > 0 {
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+// WriteAgenda アジェンダをグループごとに書き出す（空のグループは省略する）
+func WriteAgenda(w io.Writer, groups []AgendaGroup) {
+	empty := true
+	for _, g := range groups {
+		if len(g.Tasks) == 0 {
+			continue
+		}
+		if !empty {
+			fmt.Fprintln(w)
+		}
+		empty = false
+
+		fmt.Fprintf(w, "■ %s (%d)\n", g.Label, len(g.Tasks))
+		for _, task := range g.Tasks {
+			line := fmt.Sprintf("  #%d %s", task.ID, task.Title)
+			if task.DueAt != nil {
+				line += fmt.Sprintf("  [期限 %s]", FormatDue(*task.DueAt))
+			}
+			if task.Project != "" {
+				line += "  +" + task.Project
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	if empty {
+		fmt.Fprintln(w, "予定されているタスクはありません。")
+	}
+}
+
+// FormatDue 期限を表示用に変換する（時刻が0:00なら日付のみ）
+func FormatDue(due time.Time) string {
+	if due.Hour() == 0 && due.Minute() == 0 {
+		return fmt.Sprintf("%s(%s)", due.Format("2006-01-02"), weekdayNames[due.Weekday()])
+	}
+	return fmt.Sprintf("%s(%s) %s", due.Format("2006-01-02"), weekdayNames[due.Weekday()], due.Format("15:04"))
+}
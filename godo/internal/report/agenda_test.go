@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"godo/pkg/models"
+)
+
+func dueTask(id int, title string, due *time.Time) *models.Task {
+	return &models.Task{ID: id, Title: title, DueAt: due}
+}
+
+func at(t time.Time) *time.Time { return &t }
+
+func TestBuildAgenda_groupsByDueDate(t *testing.T) {
+	// 2025-03-12 は水曜日
+	now := date(12, 9, 0)
+	tasks := []*models.Task{
+		dueTask(1, "overdue", at(date(11, 18, 0))),
+		dueTask(2, "today-late", at(date(12, 17, 0))),
+		dueTask(3, "today-early", at(date(12, 8, 0))),
+		dueTask(4, "tomorrow", at(date(13, 0, 0))),
+		dueTask(5, "this-week", at(date(16, 12, 0))),
+		dueTask(6, "later", at(date(17, 0, 0))),
+		dueTask(7, "no-date", nil),
+		{ID: 8, Title: "done", Completed: true, DueAt: at(date(12, 10, 0))},
+	}
+
+	groups := BuildAgenda(tasks, now)
+	want := map[string][]string{
+		AgendaOverdue:  {"overdue"},
+		AgendaToday:    {"today-early", "today-late"},
+		AgendaTomorrow: {"tomorrow"},
+		AgendaThisWeek: {"this-week"},
+		AgendaLater:    {"later"},
+		AgendaNoDate:   {"no-date"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("expected %d groups, got %d", len(want), len(groups))
+	}
+	for _, g := range groups {
+		var titles []string
+		for _, task := range g.Tasks {
+			titles = append(titles, task.Title)
+		}
+		if strings.Join(titles, ",") != strings.Join(want[g.Label], ",") {
+			t.Fatalf("group %s: got %v want %v", g.Label, titles, want[g.Label])
+		}
+	}
+}
+
+func TestDueWithin(t *testing.T) {
+	now := date(12, 9, 0)
+	tasks := []*models.Task{
+		dueTask(1, "overdue", at(date(1, 0, 0))),
+		dueTask(2, "in-2-days", at(date(14, 23, 0))),
+		dueTask(3, "in-3-days", at(date(15, 0, 0))),
+		dueTask(4, "no-date", nil),
+	}
+	got := DueWithin(tasks, now, 3)
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 2 {
+		t.Fatalf("unexpected tasks: %+v", got)
+	}
+}
+
+func TestMonthGridAndDueCounts(t *testing.T) {
+	// 2025年3月は土曜日始まりで31日
+	weeks := MonthGrid(date(12, 0, 0))
+	if len(weeks) != 6 {
+		t.Fatalf("expected 6 weeks, got %d", len(weeks))
+	}
+	if !weeks[0][4].IsZero() || weeks[0][5].Day() != 1 {
+		t.Fatalf("march 1st should be on saturday column: %v", weeks[0])
+	}
+	if weeks[5][0].Day() != 31 || !weeks[5][1].IsZero() {
+		t.Fatalf("march 31st should be on monday of last week: %v", weeks[5])
+	}
+
+	counts := DueCounts([]*models.Task{
+		dueTask(1, "a", at(date(12, 9, 0))),
+		dueTask(2, "b", at(date(12, 18, 0))),
+		{ID: 3, Completed: true, DueAt: at(date(12, 9, 0))},
+	})
+	if counts["2025-03-12"] != 2 {
+		t.Fatalf("expected 2 tasks due on 12th, got %v", counts)
+	}
+}
+
+func TestWriteAgenda_skipsEmptyGroups(t *testing.T) {
+	now := date(12, 9, 0)
+	var buf bytes.Buffer
+	WriteAgenda(&buf, BuildAgenda([]*models.Task{dueTask(3, "report", at(date(12, 15, 0)))}, now))
+	out := buf.String()
+	if !strings.Contains(out, "■ 今日 (1)") || !strings.Contains(out, "#3 report") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if strings.Contains(out, AgendaNoDate) {
+		t.Fatalf("empty groups should be omitted:\n%s", out)
+	}
+
+	buf.Reset()
+	WriteAgenda(&buf, BuildAgenda(nil, now))
+	if !strings.Contains(buf.String(), "ありません") {
+		t.Fatalf("empty agenda message expected")
+	}
+}
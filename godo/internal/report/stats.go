@@ -0,0 +1,254 @@
+package report
+
+import (
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DayCount 1日の完了数
+type DayCount struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+}
+
+// ProjectStats プロジェクトごとの集計
+type ProjectStats struct {
+	Name                 string        `json:"name"`
+	Total                int           `json:"total"`
+	Completed            int           `json:"completed"`
+	AverageLeadTime      time.Duration `json:"-"`
+	AverageLeadTimeHours float64       `json:"average_lead_time_hours"`
+}
+
+// Stats タスクの完了状況の統計
+type Stats struct {
+	Total                int            `json:"total"`
+	Completed            int            `json:"completed"`
+	CompletedPerDay      []DayCount     `json:"completed_per_day"`
+	CompletedPerWeek     []DayCount     `json:"completed_per_week"` // Dateは週の開始日（月曜日）
+	AverageLeadTime      time.Duration  `json:"-"`
+	AverageLeadTimeHours float64        `json:"average_lead_time_hours"`
+	CurrentStreak        int            `json:"current_streak_days"`
+	LongestStreak        int            `json:"longest_streak_days"`
+	Projects             []ProjectStats `json:"projects"`
+}
+
+// BuildStats タスクの統計を集計する
+// 日別は直近days日、週別は直近weeks週（今日・今週を含む）を対象とする
+func BuildStats(tasks []*models.Task, now time.Time, days, weeks int) *Stats {
+	stats := &Stats{Total: len(tasks)}
+	today := startOfDay(now)
+	thisWeek, _ := WeekRange(now)
+
+	for i := days - 1; i >= 0; i-- {
+		stats.CompletedPerDay = append(stats.CompletedPerDay, DayCount{Date: today.AddDate(0, 0, -i)})
+	}
+	for i := weeks - 1; i >= 0; i-- {
+		stats.CompletedPerWeek = append(stats.CompletedPerWeek, DayCount{Date: thisWeek.AddDate(0, 0, -7*i)})
+	}
+
+	var totalLead time.Duration
+	leadCount := 0
+	projects := map[string]*ProjectStats{}
+	projectLead := map[string]time.Duration{}
+	projectLeadCount := map[string]int{}
+	completionDays := map[time.Time]bool{}
+
+	for _, task := range tasks {
+		name := task.Project
+		if name == "" {
+			name = NoProject
+		}
+		p, ok := projects[name]
+		if !ok {
+			p = &ProjectStats{Name: name}
+			projects[name] = p
+		}
+		p.Total++
+
+		if !task.Completed {
+			continue
+		}
+		stats.Completed++
+		p.Completed++
+
+		// 完了日時が記録されていないタスクは日別の集計とリードタイムに含めない
+		if task.CompletedAt == nil {
+			continue
+		}
+		day := startOfDay(*task.CompletedAt)
+		completionDays[day] = true
+		for i := range stats.CompletedPerDay {
+			if stats.CompletedPerDay[i].Date.Equal(day) {
+				stats.CompletedPerDay[i].Count++
+			}
+		}
+		for i := range stats.CompletedPerWeek {
+			start := stats.CompletedPerWeek[i].Date
+			if !day.Before(start) && day.Before(start.AddDate(0, 0, 7)) {
+				stats.CompletedPerWeek[i].Count++
+			}
+		}
+
+		if lead := task.CompletedAt.Sub(task.CreatedAt); lead >= 0 {
+			totalLead += lead
+			leadCount++
+			projectLead[name] += lead
+			projectLeadCount[name]++
+		}
+	}
+
+	if leadCount > 0 {
+		stats.AverageLeadTime = totalLead / time.Duration(leadCount)
+		stats.AverageLeadTimeHours = stats.AverageLeadTime.Hours()
+	}
+	for name, p := range projects {
+		if n := projectLeadCount[name]; n > 0 {
+			p.AverageLeadTime = projectLead[name] / time.Duration(n)
+			p.AverageLeadTimeHours = p.AverageLeadTime.Hours()
+		}
+		stats.Projects = append(stats.Projects, *p)
+	}
+	sort.Slice(stats.Projects, func(i, j int) bool {
+		return stats.Projects[i].Name < stats.Projects[j].Name
+	})
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(completionDays, today)
+	return stats
+}
+
+// streaks 完了したタスクがある日が連続した日数を返す
+// 現在の連続記録は今日（今日まだ完了がなければ昨日）から遡って数える
+func streaks(days map[time.Time]bool, today time.Time) (current, longest int) {
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	day := today
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline 値の推移を "▁▃█" のような1行のグラフに変換する
+func Sparkline(values []int) string {
+	maxValue := 0
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+
+	var s strings.Builder
+	for _, v := range values {
+		if maxValue == 0 || v <= 0 {
+			s.WriteRune(' ')
+			continue
+		}
+		level := v * (len(sparkLevels) - 1) / maxValue
+		s.WriteRune(sparkLevels[level])
+	}
+	return s.String()
+}
+
+// Bar 値をwidth文字の棒グラフに変換する
+func Bar(value, maxValue, width int) string {
+	filled := 0
+	if maxValue > 0 {
+		filled = value * width / maxValue
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// padRight 表示幅が width になるように s の後ろを空白で埋める
+// 日本語などの全角文字は2桁として数える（%-*s はバイト数で数えるため揃わない）
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+// WriteStats 統計をグラフ付きで書き出す
+func WriteStats(w io.Writer, stats *Stats) {
+	rate := 0
+	if stats.Total > 0 {
+		rate = stats.Completed * 100 / stats.Total
+	}
+	fmt.Fprintf(w, "完了: %d / 全体: %d (%d%%)\n", stats.Completed, stats.Total, rate)
+	fmt.Fprintf(w, "平均リードタイム: %s\n", FormatLeadTime(stats.AverageLeadTime))
+	fmt.Fprintf(w, "連続記録: %d日 (最長 %d日)\n", stats.CurrentStreak, stats.LongestStreak)
+
+	if len(stats.CompletedPerDay) > 0 {
+		counts := make([]int, len(stats.CompletedPerDay))
+		total := 0
+		for i, d := range stats.CompletedPerDay {
+			counts[i] = d.Count
+			total += d.Count
+		}
+		first := stats.CompletedPerDay[0].Date
+		last := stats.CompletedPerDay[len(stats.CompletedPerDay)-1].Date
+		fmt.Fprintf(w, "\n日別の完了数 (%s 〜 %s)\n", first.Format("01/02"), last.Format("01/02"))
+		fmt.Fprintf(w, "  %s  合計 %d\n", Sparkline(counts), total)
+	}
+
+	if len(stats.CompletedPerWeek) > 0 {
+		maxCount := 0
+		for _, wk := range stats.CompletedPerWeek {
+			maxCount = max(maxCount, wk.Count)
+		}
+		fmt.Fprintln(w, "\n週別の完了数")
+		for _, wk := range stats.CompletedPerWeek {
+			fmt.Fprintf(w, "  %s〜  %s %d\n", wk.Date.Format("01/02"), Bar(wk.Count, maxCount, 20), wk.Count)
+		}
+	}
+
+	if len(stats.Projects) > 0 {
+		width := 0
+		for _, p := range stats.Projects {
+			width = max(width, lipgloss.Width(p.Name))
+		}
+		fmt.Fprintln(w, "\nプロジェクト別")
+		for _, p := range stats.Projects {
+			fmt.Fprintf(w, "  %s  %s %d/%d  平均 %s\n", padRight(p.Name, width), Bar(p.Completed, p.Total, 10), p.Completed, p.Total, FormatLeadTime(p.AverageLeadTime))
+		}
+	}
+}
+
+// FormatLeadTime リードタイムを "2日3時間" のような形式に変換する
+func FormatLeadTime(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%d日%d時間", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%d時間%d分", hours, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%d分", int(d.Minutes()))
+}
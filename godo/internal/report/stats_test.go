@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"godo/pkg/models"
+)
+
+func completedTask(id int, project string, created, completed time.Time) *models.Task {
+	return &models.Task{ID: id, Project: project, Completed: true, CreatedAt: created, CompletedAt: &completed}
+}
+
+func TestBuildStats(t *testing.T) {
+	// 2025-03-12 (水)
+	now := date(12, 18, 0)
+	tasks := []*models.Task{
+		completedTask(1, "web", date(10, 9, 0), date(10, 21, 0)), // 12時間
+		completedTask(2, "web", date(10, 9, 0), date(11, 9, 0)),  // 24時間
+		completedTask(3, "", date(12, 9, 0), date(12, 15, 0)),    // 6時間
+		completedTask(4, "", date(1, 9, 0), date(3, 9, 0)),       // 前の週
+		{ID: 5, Project: "web", CreatedAt: date(12, 9, 0)},
+		// 完了日時のない古いデータは件数だけ数える
+		{ID: 6, Completed: true, CreatedAt: date(1, 0, 0)},
+	}
+
+	stats := BuildStats(tasks, now, 3, 2)
+	if stats.Total != 6 || stats.Completed != 5 {
+		t.Fatalf("unexpected totals: %d/%d", stats.Completed, stats.Total)
+	}
+	days := []int{stats.CompletedPerDay[0].Count, stats.CompletedPerDay[1].Count, stats.CompletedPerDay[2].Count}
+	if days[0] != 1 || days[1] != 1 || days[2] != 1 {
+		t.Fatalf("unexpected per-day counts: %v", days)
+	}
+	if stats.CompletedPerWeek[0].Count != 1 || stats.CompletedPerWeek[1].Count != 3 {
+		t.Fatalf("unexpected per-week counts: %+v", stats.CompletedPerWeek)
+	}
+	wantLead := (12*time.Hour + 24*time.Hour + 6*time.Hour + 48*time.Hour) / 4
+	if stats.AverageLeadTime != wantLead {
+		t.Fatalf("average lead time: got %v want %v", stats.AverageLeadTime, wantLead)
+	}
+	if stats.CurrentStreak != 3 || stats.LongestStreak != 3 {
+		t.Fatalf("streaks: got %d/%d", stats.CurrentStreak, stats.LongestStreak)
+	}
+	if len(stats.Projects) != 2 || stats.Projects[0].Name != NoProject || stats.Projects[1].Name != "web" {
+		t.Fatalf("unexpected projects: %+v", stats.Projects)
+	}
+	if web := stats.Projects[1]; web.Total != 3 || web.Completed != 2 || web.AverageLeadTime != 18*time.Hour {
+		t.Fatalf("unexpected web stats: %+v", web)
+	}
+}
+
+func TestStreaks_currentStartsYesterdayWhenNothingToday(t *testing.T) {
+	days := map[time.Time]bool{
+		date(5, 0, 0): true, date(6, 0, 0): true, date(7, 0, 0): true, date(8, 0, 0): true,
+		date(10, 0, 0): true, date(11, 0, 0): true,
+	}
+	current, longest := streaks(days, date(12, 0, 0))
+	if current != 2 || longest != 4 {
+		t.Fatalf("got current=%d longest=%d", current, longest)
+	}
+	if current, _ := streaks(days, date(14, 0, 0)); current != 0 {
+		t.Fatalf("streak should be broken, got %d", current)
+	}
+}
+
+func TestSparklineAndBar(t *testing.T) {
+	if got := Sparkline([]int{0, 1, 4, 8}); got != " ▁▄█" {
+		t.Fatalf("sparkline: got %q", got)
+	}
+	if got := Bar(1, 4, 8); got != "██░░░░░░" {
+		t.Fatalf("bar: got %q", got)
+	}
+}
+
+func TestWriteStatsAndJSON(t *testing.T) {
+	now := date(12, 18, 0)
+	stats := BuildStats([]*models.Task{completedTask(1, "web", date(12, 9, 0), date(12, 12, 30))}, now, 7, 4)
+
+	var buf bytes.Buffer
+	WriteStats(&buf, stats)
+	for _, want := range []string{"完了: 1 / 全体: 1 (100%)", "平均リードタイム: 3時間30分", "連続記録: 1日", "web"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("output should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded["average_lead_time_hours"] != 3.5 || decoded["current_streak_days"] != float64(1) {
+		t.Fatalf("unexpected json: %s", data)
+	}
+}
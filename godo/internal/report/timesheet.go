@@ -0,0 +1,165 @@
+package report
+
+import (
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NoProject プロジェクト未設定のタスクを集計する際の表示名
+const NoProject = "(なし)"
+
+// DaySummary 1日分の作業時間の集計
+type DaySummary struct {
+	Date      time.Time
+	ByProject map[string]time.Duration
+	Total     time.Duration
+}
+
+// Timesheet 期間内の作業時間を日別・プロジェクト別に集計したもの
+type Timesheet struct {
+	From          time.Time
+	To            time.Time
+	Days          []DaySummary
+	ProjectTotals map[string]time.Duration
+	Total         time.Duration
+}
+
+// WeekRange nowを含む週（月曜始まり）の開始日時と終了日時を返す
+func WeekRange(now time.Time) (time.Time, time.Time) {
+	day := startOfDay(now)
+	offset := (int(day.Weekday()) + 6) % 7 // 月曜日を0とする
+	from := day.AddDate(0, 0, -offset)
+	return from, from.AddDate(0, 0, 7)
+}
+
+// DayRange nowを含む1日の開始日時と終了日時を返す
+func DayRange(now time.Time) (time.Time, time.Time) {
+	from := startOfDay(now)
+	return from, from.AddDate(0, 0, 1)
+}
+
+// BuildTimesheet [from, to) の期間に記録された作業時間を集計する
+// 日をまたぐ記録は日ごとに分割し、計測中の記録はnowまでとして扱う
+func BuildTimesheet(tasks []*models.Task, from, to, now time.Time) *Timesheet {
+	ts := &Timesheet{
+		From:          from,
+		To:            to,
+		ProjectTotals: map[string]time.Duration{},
+	}
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		ts.Days = append(ts.Days, DaySummary{Date: day, ByProject: map[string]time.Duration{}})
+	}
+
+	for _, task := range tasks {
+		project := task.Project
+		if project == "" {
+			project = NoProject
+		}
+		for _, entry := range task.TimeEntries {
+			start := entry.Start
+			end := now
+			if entry.End != nil {
+				end = *entry.End
+			}
+			for i := range ts.Days {
+				dayStart := ts.Days[i].Date
+				dayEnd := dayStart.AddDate(0, 0, 1)
+				d := overlap(start, end, maxTime(dayStart, from), minTime(dayEnd, to))
+				if d <= 0 {
+					continue
+				}
+				ts.Days[i].ByProject[project] += d
+				ts.Days[i].Total += d
+				ts.ProjectTotals[project] += d
+				ts.Total += d
+			}
+		}
+	}
+	return ts
+}
+
+// Projects 集計に含まれるプロジェクト名を名前順で返す
+func (ts *Timesheet) Projects() []string {
+	projects := make([]string, 0, len(ts.ProjectTotals))
+	for p := range ts.ProjectTotals {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// WriteTimesheet タイムシートを表形式で書き出す
+func WriteTimesheet(w io.Writer, ts *Timesheet) {
+	fmt.Fprintf(w, "タイムシート %s 〜 %s\n\n", ts.From.Format("2006-01-02"), ts.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	if ts.Total == 0 {
+		fmt.Fprintln(w, "記録された作業時間はありません。")
+		return
+	}
+
+	projects := ts.Projects()
+	width := lipgloss.Width("合計")
+	for _, p := range projects {
+		width = max(width, lipgloss.Width(p))
+	}
+
+	for _, day := range ts.Days {
+		fmt.Fprintf(w, "%s (%s)  %s\n", day.Date.Format("2006-01-02"), weekdayNames[day.Date.Weekday()], FormatDuration(day.Total))
+		for _, p := range projects {
+			if d := day.ByProject[p]; d > 0 {
+				fmt.Fprintf(w, "  %s  %s\n", padRight(p, width), FormatDuration(d))
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", width+12))
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s  %s\n", padRight(p, width), FormatDuration(ts.ProjectTotals[p]))
+	}
+	fmt.Fprintf(w, "%s  %s\n", padRight("合計", width), FormatDuration(ts.Total))
+}
+
+// FormatDuration 時間を "1h05m" のような短い形式に変換する
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
+
+var weekdayNames = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// overlap [aStart, aEnd) と [bStart, bEnd) が重なる時間を返す
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	start := maxTime(aStart, bStart)
+	end := minTime(aEnd, bEnd)
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
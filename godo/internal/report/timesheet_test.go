@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"godo/pkg/models"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func date(day, hour, min int) time.Time {
+	return time.Date(2025, time.March, day, hour, min, 0, 0, time.UTC)
+}
+
+func entry(start, end time.Time) models.TimeEntry {
+	return models.TimeEntry{Start: start, End: &end}
+}
+
+func TestWeekRange_startsOnMonday(t *testing.T) {
+	// 2025-03-13 は木曜日
+	from, to := WeekRange(date(13, 15, 0))
+	if !from.Equal(date(10, 0, 0)) || !to.Equal(date(17, 0, 0)) {
+		t.Fatalf("unexpected range: %v - %v", from, to)
+	}
+	// 日曜日は前の月曜日から始まる週に含まれる
+	from, _ = WeekRange(date(16, 9, 0))
+	if !from.Equal(date(10, 0, 0)) {
+		t.Fatalf("sunday should belong to week starting 10th, got %v", from)
+	}
+}
+
+func TestBuildTimesheet_aggregatesByDayAndProject(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, Title: "a", Project: "web", TimeEntries: []models.TimeEntry{
+			entry(date(10, 9, 0), date(10, 10, 30)),
+			entry(date(11, 9, 0), date(11, 9, 30)),
+		}},
+		{ID: 2, Title: "b", TimeEntries: []models.TimeEntry{
+			entry(date(10, 13, 0), date(10, 14, 0)),
+		}},
+	}
+	from, to := WeekRange(date(12, 0, 0))
+	ts := BuildTimesheet(tasks, from, to, date(12, 0, 0))
+
+	if len(ts.Days) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(ts.Days))
+	}
+	if got := ts.Days[0].Total; got != 150*time.Minute {
+		t.Fatalf("monday total: got %v", got)
+	}
+	if got := ts.Days[0].ByProject["web"]; got != 90*time.Minute {
+		t.Fatalf("monday web: got %v", got)
+	}
+	if got := ts.ProjectTotals[NoProject]; got != time.Hour {
+		t.Fatalf("no project total: got %v", got)
+	}
+	if ts.Total != 3*time.Hour {
+		t.Fatalf("total: got %v", ts.Total)
+	}
+}
+
+func TestBuildTimesheet_splitsAcrossMidnightAndClipsRange(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, TimeEntries: []models.TimeEntry{
+			// 日曜 23:00 〜 月曜 01:00 （週の開始前の1時間は含まれない）
+			entry(date(9, 23, 0), date(10, 1, 0)),
+			// 月曜 23:30 〜 火曜 00:30
+			entry(date(10, 23, 30), date(11, 0, 30)),
+		}},
+	}
+	from, to := WeekRange(date(10, 12, 0))
+	ts := BuildTimesheet(tasks, from, to, date(12, 0, 0))
+
+	if got := ts.Days[0].Total; got != 90*time.Minute {
+		t.Fatalf("monday: got %v", got)
+	}
+	if got := ts.Days[1].Total; got != 30*time.Minute {
+		t.Fatalf("tuesday: got %v", got)
+	}
+}
+
+func TestBuildTimesheet_runningEntryCountsUntilNow(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, TimeEntries: []models.TimeEntry{{Start: date(10, 9, 0)}}},
+	}
+	from, to := DayRange(date(10, 0, 0))
+	ts := BuildTimesheet(tasks, from, to, date(10, 9, 45))
+	if ts.Total != 45*time.Minute {
+		t.Fatalf("expected 45m, got %v", ts.Total)
+	}
+}
+
+func TestWriteTimesheet(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, Project: "web", TimeEntries: []models.TimeEntry{entry(date(10, 9, 0), date(10, 10, 5))}},
+	}
+	from, to := WeekRange(date(10, 0, 0))
+	var buf bytes.Buffer
+	WriteTimesheet(&buf, BuildTimesheet(tasks, from, to, date(12, 0, 0)))
+	out := buf.String()
+	for _, want := range []string{"2025-03-10 (月)  1h05m", "web", "合計"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTimesheet_alignsWideProjectNames(t *testing.T) {
+	tasks := []*models.Task{
+		{ID: 1, Project: "web", TimeEntries: []models.TimeEntry{entry(date(10, 9, 0), date(10, 10, 0))}},
+		{ID: 2, Project: "開発", TimeEntries: []models.TimeEntry{entry(date(10, 11, 0), date(10, 12, 0))}},
+	}
+	from, to := WeekRange(date(10, 0, 0))
+	var buf bytes.Buffer
+	WriteTimesheet(&buf, BuildTimesheet(tasks, from, to, date(12, 0, 0)))
+
+	// 全角のプロジェクト名があっても、集計の時間は同じ桁から始まる
+	column := -1
+	for _, line := range strings.Split(buf.String(), "\n") {
+		i := strings.Index(line, "1h00m")
+		if i < 0 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		if width := lipgloss.Width(line[:i]); column < 0 {
+			column = width
+		} else if width != column {
+			t.Fatalf("totals are not aligned:\n%s", buf.String())
+		}
+	}
+	if column < 0 {
+		t.Fatalf("no project totals:\n%s", buf.String())
+	}
+}
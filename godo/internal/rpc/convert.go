@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/pkg/models"
+	"godo/rpc/godov1"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto タスクを protobuf のメッセージに変換する
+func ToProto(task *models.Task) *godov1.Task {
+	pb := &godov1.Task{
+		Id:          int64(task.ID),
+		Title:       task.Title,
+		Completed:   task.Completed,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		Project:     task.Project,
+		Tags:        task.Tags,
+		Contexts:    task.Contexts,
+		DueAt:       timestamp(task.DueAt),
+		CompletedAt: timestamp(task.CompletedAt),
+		Pomodoros:   int32(task.Pomodoros),
+		Meta:        task.Meta,
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+		Etag:        api.ETag(task),
+	}
+	for _, e := range task.TimeEntries {
+		pb.TimeEntries = append(pb.TimeEntries, &godov1.TimeEntry{Start: timestamppb.New(e.Start), End: timestamp(e.End), Note: e.Note})
+	}
+	for _, a := range task.Annotations {
+		pb.Annotations = append(pb.Annotations, &godov1.Annotation{Time: timestamppb.New(a.Time), Text: a.Text})
+	}
+	return pb
+}
+
+// FromProto protobuf のメッセージをタスクに変換する（etag は使わない）
+func FromProto(pb *godov1.Task) *models.Task {
+	task := &models.Task{
+		ID:          int(pb.GetId()),
+		Title:       pb.GetTitle(),
+		Completed:   pb.GetCompleted(),
+		Status:      pb.GetStatus(),
+		Priority:    pb.GetPriority(),
+		Project:     pb.GetProject(),
+		Tags:        pb.GetTags(),
+		Contexts:    pb.GetContexts(),
+		DueAt:       fromTimestamp(pb.GetDueAt()),
+		CompletedAt: fromTimestamp(pb.GetCompletedAt()),
+		TimeEntries: timeEntries(pb.GetTimeEntries()),
+		Annotations: annotations(pb.GetAnnotations()),
+		Pomodoros:   int(pb.GetPomodoros()),
+		Meta:        pb.GetMeta(),
+	}
+	if pb.GetCreatedAt() != nil {
+		task.CreatedAt = pb.GetCreatedAt().AsTime().Local()
+	}
+	if pb.GetUpdatedAt() != nil {
+		task.UpdatedAt = pb.GetUpdatedAt().AsTime().Local()
+	}
+	return task
+}
+
+// eventToProto 配信する変更を protobuf のメッセージに変換する
+func eventToProto(c feed.Change) *godov1.TaskEvent {
+	pb := &godov1.TaskEvent{
+		Id:       c.ID,
+		TaskId:   int64(c.TaskID),
+		Field:    c.Event.Field,
+		OldValue: c.Event.Old,
+		NewValue: c.Event.New,
+		Time:     timestamppb.New(c.Event.Time),
+	}
+	switch c.Type {
+	case feed.Created:
+		pb.Type = godov1.TaskEvent_TYPE_CREATED
+	case feed.Updated:
+		pb.Type = godov1.TaskEvent_TYPE_UPDATED
+	case feed.Deleted:
+		pb.Type = godov1.TaskEvent_TYPE_DELETED
+	}
+	if c.Task != nil {
+		pb.Task = ToProto(c.Task)
+	}
+	return pb
+}
+
+// filterFromProto 一覧の絞り込み条件を変換する
+func filterFromProto(req *godov1.ListRequest) api.Filter {
+	f := api.Filter{
+		Completed: req.Completed,
+		Project:   req.GetProject(),
+		Priority:  req.GetPriority(),
+		Status:    req.GetStatus(),
+		Tag:       req.GetTag(),
+		Query:     req.GetQuery(),
+	}
+	if req.GetDueBefore() != nil {
+		f.DueBefore = req.GetDueBefore().AsTime()
+	}
+	return f
+}
+
+func timeEntries(entries []*godov1.TimeEntry) []models.TimeEntry {
+	var out []models.TimeEntry
+	for _, e := range entries {
+		out = append(out, models.TimeEntry{Start: e.GetStart().AsTime().Local(), End: fromTimestamp(e.GetEnd()), Note: e.GetNote()})
+	}
+	return out
+}
+
+func annotations(list []*godov1.Annotation) []models.Annotation {
+	var out []models.Annotation
+	for _, a := range list {
+		out = append(out, models.Annotation{Time: a.GetTime().AsTime().Local(), Text: a.GetText()})
+	}
+	return out
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime().Local()
+	return &t
+}
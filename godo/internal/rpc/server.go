@@ -0,0 +1,281 @@
+// Package rpc は godo.v1.TaskService（proto/godo/v1/tasks.proto）の gRPC サーバーを提供する
+//
+// REST API と同じく api.Store からタスクを読み書きし、変更の受け取り（Watch）は変更履歴ファイルから配信する。
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/pkg/models"
+	"godo/rpc/godov1"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Service godov1.TaskServiceServer の実装
+// 呼び出しごとにタスクを読み込み直すので、CLI や TUI での変更もすぐに反映される
+type Service struct {
+	godov1.UnimplementedTaskServiceServer
+
+	tasks    *api.Access
+	history  *feed.History // nil なら Watch は使えない
+	Interval time.Duration // Watch で履歴ファイルを確認する間隔
+}
+
+// NewService tasks のタスクを操作する Service を作成する
+func NewService(tasks *api.Access, history *feed.History) *Service {
+	return &Service{tasks: tasks, history: history, Interval: 300 * time.Millisecond}
+}
+
+// NewServer Service を登録した gRPC サーバーを作成する
+// token が空でなければ、メタデータの authorization: Bearer <token> を必須にする
+func NewServer(svc *Service, token string, opts ...grpc.ServerOption) *grpc.Server {
+	if token != "" {
+		opts = append(opts,
+			grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				if err := authorize(ctx, token); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := authorize(ss.Context(), token); err != nil {
+					return err
+				}
+				return handler(srv, ss)
+			}),
+		)
+	}
+	s := grpc.NewServer(opts...)
+	godov1.RegisterTaskServiceServer(s, svc)
+	return s
+}
+
+// authorize メタデータのトークンを確認する
+func authorize(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if got, ok := strings.CutPrefix(v, "Bearer "); ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "認証に失敗しました")
+}
+
+// List 絞り込み条件に合うタスクの一覧を返す
+func (s *Service) List(_ context.Context, req *godov1.ListRequest) (*godov1.ListResponse, error) {
+	filter := filterFromProto(req)
+	if filter.Priority != "" {
+		if err := models.ValidatePriority(filter.Priority); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	resp := &godov1.ListResponse{}
+	err := s.tasks.View(func(tm *models.TaskManager) error {
+		for _, task := range tm.GetTasks() {
+			if filter.Match(tm.Workflow(), task) {
+				resp.Tasks = append(resp.Tasks, ToProto(task))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// Get タスクを返す
+func (s *Service) Get(_ context.Context, req *godov1.GetRequest) (*godov1.Task, error) {
+	var task *godov1.Task
+	err := s.tasks.View(func(tm *models.TaskManager) error {
+		index, err := api.Find(tm, int(req.GetId()), "")
+		if err != nil {
+			return err
+		}
+		task = ToProto(tm.GetTaskByIndex(index))
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return task, nil
+}
+
+// Create タスクを作成する
+func (s *Service) Create(_ context.Context, req *godov1.CreateRequest) (*godov1.Task, error) {
+	if req.GetTask() == nil {
+		return nil, status.Error(codes.InvalidArgument, "タスクを指定してください")
+	}
+	task := FromProto(req.GetTask())
+	var created *models.Task
+	err := s.tasks.Update(func(tm *models.TaskManager) (err error) {
+		created, err = api.CreateTask(tm, task)
+		return err
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return ToProto(created), nil
+}
+
+// Update update_mask で指定した項目を変更する
+func (s *Service) Update(_ context.Context, req *godov1.UpdateRequest) (*godov1.Task, error) {
+	if req.GetTask() == nil {
+		return nil, status.Error(codes.InvalidArgument, "タスクを指定してください")
+	}
+	patch, err := patchFromMask(req.GetTask(), req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var updated *godov1.Task
+	err = s.tasks.Update(func(tm *models.TaskManager) error {
+		index, err := api.Find(tm, int(req.GetTask().GetId()), req.GetEtag())
+		if err != nil {
+			return err
+		}
+		if err := api.ApplyPatch(tm, index, patch); err != nil {
+			return err
+		}
+		updated = ToProto(tm.GetTaskByIndex(index))
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return updated, nil
+}
+
+// Delete タスクを削除する
+func (s *Service) Delete(_ context.Context, req *godov1.DeleteRequest) (*godov1.DeleteResponse, error) {
+	err := s.tasks.Update(func(tm *models.TaskManager) error {
+		index, err := api.Find(tm, int(req.GetId()), req.GetEtag())
+		if err != nil {
+			return err
+		}
+		tm.DeleteTask(index)
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &godov1.DeleteResponse{}, nil
+}
+
+// Watch 変更履歴ファイルに追記された変更を送り続ける
+func (s *Service) Watch(req *godov1.WatchRequest, stream grpc.ServerStreamingServer[godov1.TaskEvent]) error {
+	if s.history == nil {
+		return status.Error(codes.Unimplemented, "変更の配信は有効になっていません")
+	}
+	if req.GetAfterEventId() < 0 {
+		return status.Errorf(codes.InvalidArgument, "イベントIDが不正です: %d", req.GetAfterEventId())
+	}
+	// after_event_id を指定しなければ呼び出した時点より後の変更を、0 なら履歴の最初から配信する
+	last := req.GetAfterEventId()
+	if req.AfterEventId == nil {
+		latest, err := s.history.Latest()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		last = latest
+	}
+
+	poll := time.NewTicker(s.Interval)
+	defer poll.Stop()
+	for {
+		changes, next, err := s.history.Changes(last, s.tasks.Tasks)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, c := range changes {
+			if err := stream.Send(eventToProto(c)); err != nil {
+				return err
+			}
+		}
+		last = next
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-poll.C:
+		}
+	}
+}
+
+// toStatus api.Access のエラーを gRPC のステータスに変換する
+func toStatus(err error) error {
+	var storeErr *api.StoreError
+	switch {
+	case errors.As(err, &storeErr):
+		return status.Error(codes.Internal, err.Error())
+	case errors.Is(err, api.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, api.ErrPreconditionFailed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+// patchFromMask update_mask で指定した項目を変更内容に変換する
+func patchFromMask(pb *godov1.Task, paths []string) (api.Patch, error) {
+	var p api.Patch
+	if len(paths) == 0 {
+		return p, errors.New("update_mask に変更する項目を指定してください")
+	}
+	for _, path := range paths {
+		switch path {
+		case "title":
+			title := pb.GetTitle()
+			p.Title = &title
+		case "completed":
+			completed := pb.GetCompleted()
+			p.Completed = &completed
+		case "status":
+			s := pb.GetStatus()
+			p.Status = &s
+		case "priority":
+			priority := pb.GetPriority()
+			p.Priority = &priority
+		case "project":
+			project := pb.GetProject()
+			p.Project = &project
+		case "tags":
+			tags := pb.GetTags()
+			p.Tags = &tags
+		case "contexts":
+			contexts := pb.GetContexts()
+			p.Contexts = &contexts
+		case "due_at":
+			if due := fromTimestamp(pb.GetDueAt()); due != nil {
+				p.DueAt = due
+			} else {
+				p.ClearDue = true
+			}
+		case "time_entries":
+			entries := timeEntries(pb.GetTimeEntries())
+			p.TimeEntries = &entries
+		case "annotations":
+			list := annotations(pb.GetAnnotations())
+			p.Annotations = &list
+		case "pomodoros":
+			pomodoros := int(pb.GetPomodoros())
+			p.Pomodoros = &pomodoros
+		case "meta":
+			meta := pb.GetMeta()
+			p.Meta = &meta
+		default:
+			return p, fmt.Errorf("update_mask の項目が不正です: %q", path)
+		}
+	}
+	return p, nil
+}
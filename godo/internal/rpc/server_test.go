@@ -0,0 +1,260 @@
+package rpc
+
+import (
+	"context"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"godo/rpc/client"
+	"godo/rpc/godov1"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const testToken = "secret-token"
+
+// newTestClient 一時ディレクトリのタスクを操作するサーバーを bufconn で起動し、クライアントを返す
+func newTestClient(t *testing.T, token string, titles ...string) (*storage.TaskStorage, *client.Client) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	ts := storage.NewTaskStorage()
+	tm := models.NewTaskManager([]*models.Task{})
+	for _, title := range titles {
+		tm.AddTask(title)
+	}
+	if err := ts.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService(api.NewAccess(api.NewStorageStore(ts, models.DefaultWorkflow())), feed.NewHistory(ts.GetHistoryPath()))
+	svc.Interval = 10 * time.Millisecond
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(svc, testToken)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c, err := client.Dial("passthrough:///bufnet", token,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return ts, c
+}
+
+func TestCRUD(t *testing.T) {
+	ts, c := newTestClient(t, testToken, "牛乳を買う")
+	ctx := context.Background()
+
+	due := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	created, err := c.Create(ctx, &godov1.Task{Title: "  レポートを書く ", Priority: "A", Tags: []string{"work"}, DueAt: timestamppb.New(due)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetId() != 2 || created.GetTitle() != "レポートを書く" || created.GetEtag() == "" || !created.GetDueAt().AsTime().Equal(due) {
+		t.Fatalf("created = %v", created)
+	}
+
+	got, err := c.Get(ctx, 2)
+	if err != nil || got.GetPriority() != "A" || got.GetEtag() != created.GetEtag() {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+
+	// update_mask に指定した項目だけを変更する
+	updated, err := c.Update(ctx, &godov1.Task{Id: 2, Title: "レポートを提出する", Priority: "B", Completed: true, Etag: got.GetEtag()}, "title", "completed", "due_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetTitle() != "レポートを提出する" || !updated.GetCompleted() || updated.GetCompletedAt() == nil || updated.GetPriority() != "A" || updated.GetDueAt() != nil {
+		t.Fatalf("updated = %v", updated)
+	}
+
+	if err := c.Delete(ctx, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := c.List(ctx, nil)
+	if err != nil || len(tasks) != 1 || tasks[0].GetId() != 2 {
+		t.Fatalf("List = %v, %v", tasks, err)
+	}
+
+	// ファイルに保存され、変更履歴も残る
+	stored, _ := ts.LoadTasks()
+	if len(stored) != 1 || stored[0].Title != "レポートを提出する" {
+		t.Fatalf("stored = %v", stored)
+	}
+	history, _ := ts.LoadHistory()
+	if len(history) < 5 {
+		t.Fatalf("history = %v", history)
+	}
+}
+
+func TestUpdateContextsAnnotationsAndMeta(t *testing.T) {
+	ts, c := newTestClient(t, testToken, "牛乳を買う")
+	ctx := context.Background()
+
+	noted := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	updated, err := c.Update(ctx, &godov1.Task{
+		Id:          1,
+		Contexts:    []string{"home"},
+		Annotations: []*godov1.Annotation{{Time: timestamppb.New(noted), Text: "低脂肪"}},
+		Meta:        map[string]string{"uid": "u1"},
+	}, "contexts", "annotations", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.GetContexts()) != 1 || len(updated.GetAnnotations()) != 1 || updated.GetMeta()["uid"] != "u1" {
+		t.Fatalf("updated = %v", updated)
+	}
+
+	stored, _ := ts.LoadTasks()
+	if task := stored[0]; task.Contexts[0] != "home" || task.Annotations[0].Text != "低脂肪" || !task.Annotations[0].Time.Equal(noted) || task.Meta["uid"] != "u1" {
+		t.Fatalf("stored = %+v", task)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, c := newTestClient(t, testToken, "牛乳を買う")
+	ctx := context.Background()
+
+	task, _ := c.Get(ctx, 1)
+	if _, err := c.Update(ctx, &godov1.Task{Id: 1, Title: "パンを買う"}, "title"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		call func() error
+		want codes.Code
+	}{
+		"見つからない": {func() error { _, err := c.Get(ctx, 99); return err }, codes.NotFound},
+		"古いETag": {func() error {
+			_, err := c.Update(ctx, &godov1.Task{Id: 1, Title: "卵を買う", Etag: task.GetEtag()}, "title")
+			return err
+		}, codes.FailedPrecondition},
+		"古いETagで削除":     {func() error { return c.Delete(ctx, 1, task.GetEtag()) }, codes.FailedPrecondition},
+		"空のタイトル":        {func() error { _, err := c.Create(ctx, &godov1.Task{Title: " "}); return err }, codes.InvalidArgument},
+		"不正な優先度":        {func() error { _, err := c.Create(ctx, &godov1.Task{Title: "a", Priority: "1"}); return err }, codes.InvalidArgument},
+		"update_maskなし": {func() error { _, err := c.Update(ctx, &godov1.Task{Id: 1}); return err }, codes.InvalidArgument},
+		"不明な項目": {func() error {
+			_, err := c.Update(ctx, &godov1.Task{Id: 1}, "created_at")
+			return err
+		}, codes.InvalidArgument},
+		"不正な状態": {func() error {
+			_, err := c.Update(ctx, &godov1.Task{Id: 1, Status: "blocked"}, "status")
+			return err
+		}, codes.InvalidArgument},
+	}
+	for name, tt := range tests {
+		if got := status.Code(tt.call()); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", name, got, tt.want)
+		}
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	_, c := newTestClient(t, testToken)
+	ctx := context.Background()
+	c.Create(ctx, &godov1.Task{Title: "牛乳を買う", Project: "家", Tags: []string{"shop"}})
+	c.Create(ctx, &godov1.Task{Title: "レポートを書く", Project: "仕事", Priority: "A", Completed: true})
+
+	completed := true
+	tests := []struct {
+		req  *godov1.ListRequest
+		want int
+	}{
+		{&godov1.ListRequest{Completed: &completed}, 1},
+		{&godov1.ListRequest{Project: "家"}, 1},
+		{&godov1.ListRequest{Tag: "shop"}, 1},
+		{&godov1.ListRequest{Priority: "A"}, 1},
+		{&godov1.ListRequest{Query: "レポート"}, 1},
+		{&godov1.ListRequest{}, 2},
+	}
+	for _, tt := range tests {
+		tasks, err := c.List(ctx, tt.req)
+		if err != nil || len(tasks) != tt.want {
+			t.Errorf("List(%v) = %d tasks, %v; want %d", tt.req, len(tasks), err, tt.want)
+		}
+	}
+}
+
+func TestRequiresToken(t *testing.T) {
+	_, c := newTestClient(t, "wrong", "牛乳を買う")
+	if _, err := c.List(context.Background(), nil); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("List err = %v", err)
+	}
+	err := c.Watch(context.Background(), nil, func(*godov1.TaskEvent) error { return nil })
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Watch err = %v", err)
+	}
+}
+
+func TestWatchStreamsAndResumes(t *testing.T) {
+	_, c := newTestClient(t, testToken, "牛乳を買う")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *godov1.TaskEvent, 10)
+	go c.Watch(ctx, nil, func(e *godov1.TaskEvent) error {
+		received <- e
+		return nil
+	})
+	next := func(ch chan *godov1.TaskEvent) *godov1.TaskEvent {
+		t.Helper()
+		select {
+		case e := <-ch:
+			return e
+		case <-ctx.Done():
+			t.Fatal("変更を受け取れませんでした")
+		}
+		return nil
+	}
+
+	// 呼び出した時点より後の変更だけを受け取る
+	time.Sleep(50 * time.Millisecond)
+	c.Update(ctx, &godov1.Task{Id: 1, Title: "パンを買う"}, "title")
+	updated := next(received)
+	c.Delete(ctx, 1, "")
+	deleted := next(received)
+	if updated.GetType() != godov1.TaskEvent_TYPE_UPDATED || updated.GetField() != "title" || updated.GetNewValue() != "パンを買う" || updated.GetTask().GetTitle() != "パンを買う" {
+		t.Errorf("updated = %v", updated)
+	}
+	if deleted.GetType() != godov1.TaskEvent_TYPE_DELETED || deleted.GetTaskId() != 1 || deleted.GetTask().GetTitle() != "パンを買う" {
+		t.Errorf("deleted = %v", deleted)
+	}
+
+	// イベントIDを指定すると、その後の変更から受け取り直せる
+	again := make(chan *godov1.TaskEvent, 10)
+	go c.Watch(ctx, proto.Int64(updated.GetId()-1), func(e *godov1.TaskEvent) error {
+		again <- e
+		return nil
+	})
+	for _, want := range []int64{updated.GetId(), deleted.GetId()} {
+		if e := next(again); e.GetId() != want {
+			t.Errorf("resumed ID = %d, want %d", e.GetId(), want)
+		}
+	}
+
+	// 0 を指定すると履歴の最初から受け取る
+	all := make(chan *godov1.TaskEvent, 10)
+	go c.Watch(ctx, proto.Int64(0), func(e *godov1.TaskEvent) error {
+		all <- e
+		return nil
+	})
+	if e := next(all); e.GetType() != godov1.TaskEvent_TYPE_CREATED || e.GetTaskId() != 1 {
+		t.Errorf("first event = %v", e)
+	}
+}
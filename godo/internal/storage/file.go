@@ -3,51 +3,180 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
-	"godo/internal/models"
+	"godo/pkg/models"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
 	//タスクファイル名
 	TasksFileName = "tasks.json"
+	//アーカイブファイル名
+	ArchiveFileName = "archive.json"
 )
 
+// Mode はタスクの保存形式
+type Mode string
+
+const (
+	//タスク全体をtasks.jsonに書き直す（デフォルト）
+	SnapshotMode Mode = "snapshot"
+	//変更をjournal.jsonlに追記し、定期的にtasks.jsonにまとめる
+	JournalMode Mode = "journal"
+)
+
+// ParseModeは保存形式の名前を解釈する（空文字はスナップショット）
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case "", SnapshotMode:
+		return SnapshotMode, nil
+	case JournalMode:
+		return JournalMode, nil
+	}
+	return "", fmt.Errorf("保存形式が不正です: %q (snapshot または journal)", name)
+}
+
 //TaskStorage はタスクの保存・読み込みを管理する
 type TaskStorage struct {
-	filePath string
+	filePath    string
+	archivePath string
+	autoArchive time.Duration // 0より大きい場合、保存時に完了後この期間が経過したタスクをアーカイブする
+	journal     *journal      // ジャーナル形式の場合のみ設定される
 }
 
 //NewTaskStorageは新しいTaskStorageを作成する
 func NewTaskStorage() *TaskStorage {
+	return NewTaskStorageAt(DefaultDir())
+}
+
+// DefaultDirはタスクを保存するデフォルトのディレクトリ（~/.godo）を返す
+func DefaultDir() string {
 	//ホームディレクトリ配下にファイルを作成
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		//エラーの場合は現在のディレクトリを使用
 		homeDir = "."
 	}
+	return filepath.Join(homeDir, ".godo")
+}
 
+// NewTaskStorageAtはdirにタスクを保存するTaskStorageを作成する
+func NewTaskStorageAt(dir string) *TaskStorage {
 	//ファイルパスを作成
-	filePath := filepath.Join(homeDir, ".godo", TasksFileName)
+	filePath := filepath.Join(dir, TasksFileName)
 
-	// .godoディレクトリが存在しない場合は作成
-	dir := filepath.Dir(filePath)
+	// ディレクトリが存在しない場合は作成
 	os.MkdirAll(dir, 0755)
 
 	return &TaskStorage{
-		filePath: filePath,
+		filePath:    filePath,
+		archivePath: filepath.Join(dir, ArchiveFileName),
+	}
+}
+
+// SetModeはタスクの保存形式を設定する
+func (ts *TaskStorage) SetMode(mode Mode) {
+	if mode == JournalMode {
+		ts.journal = newJournal(ts.filePath)
+	} else {
+		ts.journal = nil
+	}
+}
+
+// Compactはジャーナルの変更をスナップショットにまとめる（ジャーナル形式以外では何もしない）
+func (ts *TaskStorage) Compact() error {
+	if ts.journal == nil {
+		return nil
 	}
+	return ts.journal.compact()
+}
+
+// SetAutoArchiveは保存時の自動アーカイブの期間を設定する（0で無効）
+func (ts *TaskStorage) SetAutoArchive(olderThan time.Duration) {
+	ts.autoArchive = olderThan
 }
 
-// LoadTasksはファイルからタスクを読み込む
+// LoadTasksはファイルからタスクを読み込む（ファイルは変更しない）
 func (ts *TaskStorage) LoadTasks() ([]*models.Task, error) {
+	if ts.journal != nil {
+		return ts.journal.load()
+	}
+	return readTasksFile(ts.filePath)
+}
+
+// SaveTasksはタスクはタスクをファイルに保存する
+func (ts *TaskStorage) SaveTasks(tasks []*models.Task) error {
+	if ts.journal != nil {
+		return ts.journal.save(tasks)
+	}
+	return writeTasksFile(ts.filePath, tasks)
+}
+
+// LoadArchiveはアーカイブされたタスクを読み込む
+func (ts *TaskStorage) LoadArchive() ([]*models.Task, error) {
+	return readTasksFile(ts.archivePath)
+}
+
+// SaveArchiveはアーカイブされたタスクを保存する
+func (ts *TaskStorage) SaveArchive(tasks []*models.Task) error {
+	return writeTasksFile(ts.archivePath, tasks)
+}
+
+// AppendArchiveはタスクをアーカイブに追加する
+func (ts *TaskStorage) AppendArchive(tasks []*models.Task) error {
+	archive, err := ts.LoadArchive()
+	if err != nil {
+		return err
+	}
+	return ts.SaveArchive(append(archive, tasks...))
+}
+
+// ArchiveCompletedは完了してからolderThan以上経過したタスクをアーカイブに移して保存し、移したタスクを返す
+// 読み込みから保存までを他のプロセスと排他するには、呼び出す側で Lock を取ること
+func (ts *TaskStorage) ArchiveCompleted(tm *models.TaskManager, olderThan time.Duration, now time.Time) ([]*models.Task, error) {
+	archived, err := ts.archive(tm, olderThan, now)
+	if err != nil || len(archived) == 0 {
+		return nil, err
+	}
+	if err := ts.Save(tm); err != nil {
+		return nil, err
+	}
+	return archived, nil
+}
+
+// archiveは完了してからolderThan以上経過したタスクをアーカイブに書き込んでから tm から取り除く
+// アーカイブに書き込めなかった場合は tm を変更しないので、後で保存してもタスクは失われない
+func (ts *TaskStorage) archive(tm *models.TaskManager, olderThan time.Duration, now time.Time) ([]*models.Task, error) {
+	_, archivable := models.SplitArchivable(tm.GetTasks(), olderThan, now)
+	if len(archivable) == 0 {
+		return nil, nil
+	}
+	if err := ts.AppendArchive(archivable); err != nil {
+		return nil, err
+	}
+	return tm.ArchiveCompleted(olderThan, now), nil
+}
+
+//GetFilePathは保存先のファイルパスを返す（デバック用）
+func (ts *TaskStorage) GetFilePath() string {
+	return ts.filePath
+}
+
+//GetArchivePathはアーカイブのファイルパスを返す
+func (ts *TaskStorage) GetArchivePath() string {
+	return ts.archivePath
+}
+
+// readTasksFileはタスクのJSONファイルを読み込む（ファイルがなければ空のスライスを返す）
+func readTasksFile(path string) ([]*models.Task, error) {
 	//ファイルが存在しない場合は空のスライスを返す
-	if _, err := os.Stat(ts.filePath); os.IsNotExist(err) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return []*models.Task{}, nil
 	}
 
 	//ファイルを読み込む
-	data, err := os.ReadFile(ts.filePath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
 	}
@@ -59,11 +188,10 @@ func (ts *TaskStorage) LoadTasks() ([]*models.Task, error) {
 	}
 
 	return tasks, nil
-
 }
 
-// SaveTasksはタスクはタスクをファイルに保存する
-func (ts *TaskStorage) SaveTasks(tasks []*models.Task) error {
+// writeTasksFileはタスクをJSONファイルに書き込む
+func writeTasksFile(path string, tasks []*models.Task) error {
 	//JSONに変換（見やすくインデント付き）
 	data, err := json.MarshalIndent(tasks, "", "  ")
 	if err != nil {
@@ -71,14 +199,9 @@ func (ts *TaskStorage) SaveTasks(tasks []*models.Task) error {
 	}
 
 	//ファイルに保存
-	if err := os.WriteFile(ts.filePath, data, 0644); err != nil {
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("ファイルへの保存に失敗しました: %w", err)
 	}
 
 	return nil
 }
-
-//GetFilePathは保存先のファイルパスを返す（デバック用）
-func (ts *TaskStorage) GetFilePath() string {
-	return ts.filePath
-}
\ No newline at end of file
@@ -5,8 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
-	"godo/internal/models"
+	"godo/pkg/models"
 )
 
 // helper to write arbitrary tasks JSON to a path
@@ -91,3 +92,98 @@ func TestSaveThenLoadTasks_roundTrip(t *testing.T) {
 }
 
 
+
+func TestArchiveCompleted_movesTasksToArchiveFile(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := NewTaskStorage()
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("done")
+	tm.AddTask("open")
+	tm.ToggleTask(0)
+
+	archived, err := ts.ArchiveCompleted(tm, 0, time.Now())
+	if err != nil {
+		t.Fatalf("ArchiveCompleted error: %v", err)
+	}
+	if len(archived) != 1 || archived[0].Title != "done" {
+		t.Fatalf("unexpected archived tasks: %+v", archived)
+	}
+
+	tasks, err := ts.LoadTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "open" {
+		t.Fatalf("tasks file should only contain open task: %+v (%v)", tasks, err)
+	}
+	archive, err := ts.LoadArchive()
+	if err != nil || len(archive) != 1 || archive[0].Title != "done" {
+		t.Fatalf("archive should contain done task: %+v (%v)", archive, err)
+	}
+	if filepath.Dir(ts.GetArchivePath()) != filepath.Dir(ts.GetFilePath()) {
+		t.Fatalf("archive should live next to tasks file")
+	}
+}
+
+func TestSave_appliesAutoArchivePolicy(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := NewTaskStorage()
+
+	old := models.NewTask(1, "old")
+	old.Completed = true
+	old.UpdatedAt = time.Now().Add(-10 * 24 * time.Hour)
+	recent := models.NewTask(2, "recent")
+	recent.Completed = true
+	writeTasksJSON(t, ts.GetFilePath(), []*models.Task{old, recent, models.NewTask(3, "open")})
+
+	ts.SetAutoArchive(7 * 24 * time.Hour)
+	// 読み込みではファイルを変更しない
+	tm, err := ts.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(tm.GetTasks()) != 3 {
+		t.Fatalf("Load should not archive tasks: %+v", tm.GetTasks())
+	}
+	if archive, _ := ts.LoadArchive(); len(archive) != 0 {
+		t.Fatalf("Load should not write the archive: %+v", archive)
+	}
+
+	if err := ts.Save(tm); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	tasks, _ := ts.LoadTasks()
+	if len(tasks) != 2 || tasks[0].Title != "recent" || tasks[1].Title != "open" {
+		t.Fatalf("old completed task should be archived on save: %+v", tasks)
+	}
+	archive, _ := ts.LoadArchive()
+	if len(archive) != 1 || archive[0].ID != 1 {
+		t.Fatalf("archive should contain the old task: %+v", archive)
+	}
+	// 変更履歴にアーカイブとして記録する
+	history, _ := ts.LoadHistory()
+	if len(history) != 1 || history[0].Type != models.EventArchived || history[0].TaskID != 1 {
+		t.Fatalf("history should record the archive: %+v", history)
+	}
+}
+
+func TestArchiveCompleted_keepsTasksWhenArchiveCannotBeWritten(t *testing.T) {
+	ts := NewTaskStorageAt(t.TempDir())
+	// アーカイブのパスにディレクトリがあると書き込めない
+	if err := os.Mkdir(ts.GetArchivePath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("done")
+	tm.ToggleTask(0)
+	tm.TakePendingEvents()
+	if _, err := ts.ArchiveCompleted(tm, 0, time.Now().Add(time.Hour)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(tm.GetTasks()) != 1 || len(tm.TakePendingEvents()) != 0 {
+		t.Fatalf("tasks should be kept when the archive cannot be written: %+v", tm.GetTasks())
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	//変更履歴ファイル名（1行に1件のJSONを追記していく）
+	HistoryFileName = "history.jsonl"
+)
+
+// LoadHistoryは変更履歴を古い順に読み込む
+func (ts *TaskStorage) LoadHistory() ([]models.Event, error) {
+	file, err := os.Open(ts.historyPath())
+	if os.IsNotExist(err) {
+		return []models.Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("履歴ファイルの読み込みに失敗しました: %w", err)
+	}
+	defer file.Close()
+
+	events := []models.Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e models.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("履歴ファイルの%d行目のパースに失敗しました: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("履歴ファイルの読み込みに失敗しました: %w", err)
+	}
+	return events, nil
+}
+
+// AppendHistoryは変更履歴をファイルの末尾に追記する
+func (ts *TaskStorage) AppendHistory(events []models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(ts.historyPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("履歴ファイルを開けませんでした: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("履歴の書き込みに失敗しました: %w", err)
+		}
+	}
+	return nil
+}
+
+// Saveはタスクと次に割り当てるIDを保存し、まだ保存していない変更履歴を追記する
+// 自動アーカイブが有効な場合は、対象のタスクをアーカイブに移してから保存する（変更履歴にはアーカイブとして記録する）
+// 読み込みから保存までを他のプロセスと排他するには、呼び出す側で Lock を取ること
+func (ts *TaskStorage) Save(tm *models.TaskManager) error {
+	if ts.autoArchive > 0 {
+		if _, err := ts.archive(tm, ts.autoArchive, time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		return err
+	}
+	if err := ts.saveNextID(tm.NextID()); err != nil {
+		return err
+	}
+	return ts.AppendHistory(tm.TakePendingEvents())
+}
+
+// GetHistoryPathは変更履歴ファイルのパスを返す
+func (ts *TaskStorage) GetHistoryPath() string {
+	return ts.historyPath()
+}
+
+// historyPathは変更履歴ファイルのパスを返す
+func (ts *TaskStorage) historyPath() string {
+	return filepath.Join(filepath.Dir(ts.filePath), HistoryFileName)
+}
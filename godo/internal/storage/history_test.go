@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"godo/pkg/models"
+)
+
+func TestSave_appendsPendingHistory(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := NewTaskStorage()
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	if err := ts.Save(tm); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	tm.UpdateTask(0, "b")
+	if err := ts.Save(tm); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	// 変更がなければ何も追記しない
+	if err := ts.Save(tm); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	events, err := ts.LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory error: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != models.EventCreated || events[1].Type != models.EventRenamed {
+		t.Fatalf("unexpected history: %+v", events)
+	}
+
+	data, err := os.ReadFile(ts.historyPath())
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Fatalf("expected one JSON line per event, got %d lines", lines)
+	}
+}
+
+func TestLoadHistory_missingFileAndBrokenLine(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := NewTaskStorage()
+
+	events, err := ts.LoadHistory()
+	if err != nil || len(events) != 0 {
+		t.Fatalf("missing history should be empty: %v %v", events, err)
+	}
+
+	if err := os.WriteFile(ts.historyPath(), []byte("{\"task_id\":1,\"type\":\"created\"}\n{broken\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ts.LoadHistory(); err == nil || !strings.Contains(err.Error(), "2行目") {
+		t.Fatalf("expected parse error pointing to line 2, got %v", err)
+	}
+}
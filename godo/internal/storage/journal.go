@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"godo/internal/filelock"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	//ジャーナルファイル名（1行に1件の変更を追記していく）
+	JournalFileName = "journal.jsonl"
+	//ジャーナルの読み書きとスナップショットへのまとめを排他するロックファイル名
+	JournalLockFileName = "journal.lock"
+	//ジャーナルをスナップショットにまとめるまでの変更件数
+	DefaultCompactEvery = 200
+)
+
+// ジャーナルに記録する操作の種類
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+	OpToggle = "toggle"
+	OpDelete = "delete"
+)
+
+// JournalEntry ジャーナルの1行（タスクへの1回の変更）
+type JournalEntry struct {
+	Seq  int64        `json:"seq"`
+	Time time.Time    `json:"time"`
+	Op   string       `json:"op"`
+	ID   int          `json:"id"`
+	Task *models.Task `json:"task,omitempty"` // 変更後のタスク（削除の場合はなし）
+}
+
+// journal スナップショット（tasks.json）と追記専用のジャーナルでタスクを保存する
+// 保存時は前回の状態との差分だけをジャーナルに追記するので、書き込み量は変更の数に比例する
+type journal struct {
+	path         string
+	lockPath     string
+	snapshotPath string
+	compactEvery int
+
+	known   map[int][]byte // 最後に保存・読み込みした各タスクのJSON
+	seq     int64          // 最後に書き込んだ変更の番号
+	entries int            // スナップショット以降にジャーナルに記録された変更の数
+}
+
+func newJournal(snapshotPath string) *journal {
+	return &journal{
+		path:         filepath.Join(filepath.Dir(snapshotPath), JournalFileName),
+		lockPath:     filepath.Join(filepath.Dir(snapshotPath), JournalLockFileName),
+		snapshotPath: snapshotPath,
+		compactEvery: DefaultCompactEvery,
+		known:        map[int][]byte{},
+	}
+}
+
+// load スナップショットを読み込み、ジャーナルの変更を順に適用してタスクを復元する
+// 他のプロセスがまとめている途中の、新しいスナップショットと古いジャーナルを読まないようにロックを取る
+func (j *journal) load() ([]*models.Task, error) {
+	lock, err := filelock.Acquire(j.lockPath)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+	return j.loadLocked()
+}
+
+// loadLocked ロックを取った状態で load する
+func (j *journal) loadLocked() ([]*models.Task, error) {
+	tasks, err := readTasksFile(j.snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := j.readEntries()
+	if err != nil {
+		return nil, err
+	}
+	tasks = replay(tasks, entries)
+
+	j.entries = len(entries)
+	if len(entries) > 0 {
+		j.seq = entries[len(entries)-1].Seq
+	}
+	j.remember(tasks)
+	return tasks, nil
+}
+
+// save 前回の状態との差分をジャーナルに追記する
+// 変更の数がcompactEveryを超えたらスナップショットにまとめる
+func (j *journal) save(tasks []*models.Task) error {
+	now := time.Now()
+	var entries []JournalEntry
+	seen := map[int]bool{}
+
+	for _, task := range tasks {
+		seen[task.ID] = true
+		data, err := json.Marshal(task)
+		if err != nil {
+			return fmt.Errorf("JSONの作成に失敗しました: %w", err)
+		}
+		old, ok := j.known[task.ID]
+		switch {
+		case !ok:
+			entries = append(entries, JournalEntry{Op: OpAdd, ID: task.ID, Task: task})
+		case !bytes.Equal(old, data):
+			op := OpUpdate
+			if onlyCompletionChanged(old, task) {
+				op = OpToggle
+			}
+			entries = append(entries, JournalEntry{Op: op, ID: task.ID, Task: task})
+		}
+	}
+	for id := range j.known {
+		if !seen[id] {
+			entries = append(entries, JournalEntry{Op: OpDelete, ID: id})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range entries {
+		j.seq++
+		entries[i].Seq = j.seq
+		entries[i].Time = now
+		if err := encoder.Encode(entries[i]); err != nil {
+			return fmt.Errorf("JSONの作成に失敗しました: %w", err)
+		}
+	}
+
+	// 他のプロセスの追記やスナップショットへのまとめと重ならないようにロックを取る
+	lock, err := filelock.Acquire(j.lockPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	if err := j.append(buf.Bytes()); err != nil {
+		return err
+	}
+
+	j.remember(tasks)
+	j.entries += len(entries)
+	if j.compactEvery > 0 && j.entries >= j.compactEvery {
+		return j.compactLocked()
+	}
+	return nil
+}
+
+// append ジャーナルに追記する
+// 異常終了したプロセスが途中まで書いた行があれば、その続きに書かないよう先に取り除く
+func (j *journal) append(data []byte) error {
+	if err := j.repairTail(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("ジャーナルを開けませんでした: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("ジャーナルへの書き込みに失敗しました: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ジャーナルへの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// repairTail ジャーナルが改行で終わっていなければ、最後の行を直す
+// 最後の行が JSON として完全なら改行を補い、途中で切れていれば取り除く
+func (j *journal) repairTail() error {
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) || (err == nil && (len(data) == 0 || data[len(data)-1] == '\n')) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ジャーナルの読み込みに失敗しました: %w", err)
+	}
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if json.Valid(data[end:]) {
+		data = append(data, '\n')
+	} else {
+		data = data[:end]
+	}
+	if err := os.WriteFile(j.path, data, 0644); err != nil {
+		return fmt.Errorf("ジャーナルの修復に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// compact ジャーナルを含めた最新の状態をスナップショットに書き出し、ジャーナルを空にする
+func (j *journal) compact() error {
+	lock, err := filelock.Acquire(j.lockPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	return j.compactLocked()
+}
+
+// compactLocked ロックを取った状態で compact する
+// 他のプロセスが追記した変更も取り込むため、ジャーナルを読み直してから書き出す
+// 読み直してから切り詰めるまでロックを取っているので、その間の追記は失われない
+func (j *journal) compactLocked() error {
+	tasks, err := j.loadLocked()
+	if err != nil {
+		return err
+	}
+
+	tmp := j.snapshotPath + ".tmp"
+	if err := writeTasksFile(tmp, tasks); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.snapshotPath); err != nil {
+		return fmt.Errorf("スナップショットの保存に失敗しました: %w", err)
+	}
+	if err := os.Truncate(j.path, 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ジャーナルの切り詰めに失敗しました: %w", err)
+	}
+	j.entries = 0
+	return nil
+}
+
+// readEntries ジャーナルの全ての行を読み込む
+// 改行で終わっていない最後の行は、異常終了したプロセスが途中まで書いた行として読み飛ばす
+func (j *journal) readEntries() ([]JournalEntry, error) {
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ジャーナルの読み込みに失敗しました: %w", err)
+	}
+
+	var entries []JournalEntry
+	lines := bytes.Split(data, []byte("\n"))
+	for i, text := range lines {
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(text, &e); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("ジャーナルの%d行目のパースに失敗しました: %w", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// remember 保存済みの状態として各タスクのJSONを記録する
+func (j *journal) remember(tasks []*models.Task) {
+	j.known = make(map[int][]byte, len(tasks))
+	for _, task := range tasks {
+		if data, err := json.Marshal(task); err == nil {
+			j.known[task.ID] = data
+		}
+	}
+}
+
+// replay タスクの一覧にジャーナルの変更を順に適用する
+func replay(tasks []*models.Task, entries []JournalEntry) []*models.Task {
+	index := func(id int) int {
+		for i, task := range tasks {
+			if task.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, e := range entries {
+		i := index(e.ID)
+		switch e.Op {
+		case OpAdd, OpUpdate, OpToggle:
+			if e.Task == nil {
+				continue
+			}
+			if i >= 0 {
+				tasks[i] = e.Task
+			} else {
+				tasks = append(tasks, e.Task)
+			}
+		case OpDelete:
+			if i >= 0 {
+				tasks = append(tasks[:i], tasks[i+1:]...)
+			}
+		}
+	}
+	return tasks
+}
+
+// onlyCompletionChanged 完了状態に関する項目だけが変わったかを返す
+func onlyCompletionChanged(old []byte, task *models.Task) bool {
+	var before models.Task
+	if err := json.Unmarshal(old, &before); err != nil {
+		return false
+	}
+	after := *task
+	if before.Completed == after.Completed {
+		return false
+	}
+	// 完了の切り替えで変わる項目を揃えて比較する
+	before.Completed, before.Status, before.CompletedAt, before.UpdatedAt = after.Completed, after.Status, after.CompletedAt, after.UpdatedAt
+	a, err1 := json.Marshal(before)
+	b, err2 := json.Marshal(after)
+	return err1 == nil && err2 == nil && bytes.Equal(a, b)
+}
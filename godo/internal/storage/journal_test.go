@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"godo/pkg/models"
+)
+
+func newJournalStorage(t *testing.T) *TaskStorage {
+	t.Helper()
+	ts := NewTaskStorage()
+	ts.SetMode(JournalMode)
+	return ts
+}
+
+func TestJournal_appendsOneLinePerMutationAndReplays(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := newJournalStorage(t)
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	tm.AddTask("b")
+	tm.AddTask("c")
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	tm.ToggleTask(0)
+	tm.UpdateTask(1, "bb")
+	tm.DeleteTask(2)
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	// 変更がなければ何も追記しない
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+
+	entries, err := ts.journal.readEntries()
+	if err != nil {
+		t.Fatalf("readEntries error: %v", err)
+	}
+	ops := []string{}
+	for _, e := range entries {
+		ops = append(ops, e.Op)
+	}
+	want := []string{OpAdd, OpAdd, OpAdd, OpToggle, OpUpdate, OpDelete}
+	if len(ops) != len(want) {
+		t.Fatalf("expected ops %v, got %v", want, ops)
+	}
+	for i := range want {
+		if ops[i] != want[i] || entries[i].Seq != int64(i+1) {
+			t.Fatalf("entry %d: got op=%s seq=%d", i, ops[i], entries[i].Seq)
+		}
+	}
+	// スナップショットは書かれていない
+	if _, err := os.Stat(ts.GetFilePath()); !os.IsNotExist(err) {
+		t.Fatalf("journal mode should not rewrite the snapshot")
+	}
+
+	loaded, err := newJournalStorage(t).LoadTasks()
+	if err != nil {
+		t.Fatalf("LoadTasks error: %v", err)
+	}
+	if len(loaded) != 2 || !loaded[0].Completed || loaded[1].Title != "bb" {
+		t.Fatalf("unexpected replayed tasks: %+v %+v", loaded[0], loaded[1])
+	}
+}
+
+func TestJournal_mergesConcurrentWriters(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+
+	setup := newJournalStorage(t)
+	base := models.NewTaskManager([]*models.Task{})
+	base.AddTask("a")
+	base.AddTask("b")
+	if err := setup.SaveTasks(base.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+
+	// 2つのプロセスが同じ状態を読み込み、別々のタスクを変更する
+	first, second := newJournalStorage(t), newJournalStorage(t)
+	tasks1, _ := first.LoadTasks()
+	tasks2, _ := second.LoadTasks()
+	tm1, tm2 := models.NewTaskManager(tasks1), models.NewTaskManager(tasks2)
+	tm1.ToggleTask(0)
+	tm2.UpdateTask(1, "b2")
+	if err := first.SaveTasks(tm1.GetTasks()); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if err := second.SaveTasks(tm2.GetTasks()); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+
+	loaded, err := newJournalStorage(t).LoadTasks()
+	if err != nil {
+		t.Fatalf("LoadTasks error: %v", err)
+	}
+	if !loaded[0].Completed || loaded[1].Title != "b2" {
+		t.Fatalf("both changes should survive: %+v %+v", loaded[0], loaded[1])
+	}
+}
+
+func TestJournal_compactsIntoSnapshot(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := newJournalStorage(t)
+	ts.journal.compactEvery = 3
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	tm.AddTask("b")
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	tm.ToggleTask(1)
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+
+	// 3件目の変更でスナップショットにまとめられ、ジャーナルは空になる
+	info, err := os.Stat(ts.journal.path)
+	if err != nil || info.Size() != 0 {
+		t.Fatalf("journal should be truncated after compaction: %v %v", info, err)
+	}
+	snapshot, err := readTasksFile(ts.GetFilePath())
+	if err != nil || len(snapshot) != 2 || !snapshot[1].Completed {
+		t.Fatalf("snapshot should contain compacted state: %+v %v", snapshot, err)
+	}
+
+	// スナップショット以降の変更も復元される
+	tm.DeleteTask(0)
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	loaded, err := newJournalStorage(t).LoadTasks()
+	if err != nil || len(loaded) != 1 || loaded[0].Title != "b" {
+		t.Fatalf("unexpected tasks after compaction: %+v %v", loaded, err)
+	}
+}
+
+func TestJournal_ignoresTornLastLine(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+	ts := newJournalStorage(t)
+
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("a")
+	if err := ts.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	// 異常終了したプロセスが途中まで書いた行を再現する
+	f, err := os.OpenFile(ts.journal.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":2,"op":"upd`)
+	f.Close()
+
+	other := newJournalStorage(t)
+	loaded, err := other.LoadTasks()
+	if err != nil || len(loaded) != 1 || loaded[0].Title != "a" {
+		t.Fatalf("torn last line should be ignored: %+v %v", loaded, err)
+	}
+
+	// 次の追記は途中の行の続きに書かれない
+	tm = models.NewTaskManager(loaded)
+	tm.UpdateTask(0, "b")
+	if err := other.SaveTasks(tm.GetTasks()); err != nil {
+		t.Fatalf("SaveTasks error: %v", err)
+	}
+	entries, err := other.journal.readEntries()
+	if err != nil || len(entries) != 2 || entries[1].Task.Title != "b" {
+		t.Fatalf("entries = %+v, err = %v", entries, err)
+	}
+}
+
+func TestJournal_compactionDoesNotLoseConcurrentAppends(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("HOME", temp)
+	t.Setenv("USERPROFILE", temp)
+
+	// 別々のプロセスを再現し、それぞれ自分のタスクを追加しながら頻繁にまとめる
+	const writers, adds = 4, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ts := newJournalStorage(t)
+			ts.journal.compactEvery = 3
+			for i := 0; i < adds; i++ {
+				tasks, err := ts.LoadTasks()
+				if err != nil {
+					errs <- err
+					return
+				}
+				tasks = append(tasks, &models.Task{ID: w*adds + i + 1, Title: fmt.Sprintf("%d-%d", w, i)})
+				if err := ts.SaveTasks(tasks); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	loaded, err := newJournalStorage(t).LoadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != writers*adds {
+		t.Fatalf("expected %d tasks, got %d", writers*adds, len(loaded))
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode(""); err != nil || m != SnapshotMode {
+		t.Fatalf("empty should be snapshot: %v %v", m, err)
+	}
+	if m, err := ParseMode("journal"); err != nil || m != JournalMode {
+		t.Fatalf("journal: %v %v", m, err)
+	}
+	if _, err := ParseMode("sqlite"); err == nil {
+		t.Fatalf("unknown mode should fail")
+	}
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"godo/internal/filelock"
+	"path/filepath"
+)
+
+const (
+	//タスクの読み込みから保存までを他のプロセスと排他するロックファイル名
+	LockFileName = "tasks.lock"
+)
+
+// Lockはタスクを読み込んでから保存するまでの間、他のプロセスが同じ操作をしないようにロックを取り、ロックを外す関数を返す
+// 同じプロセスの中で二重に取ると待ち続けるので、プロセス内の排他には別に sync.Mutex を使う
+func (ts *TaskStorage) Lock() (unlock func(), err error) {
+	lock, err := filelock.Acquire(filepath.Join(filepath.Dir(ts.filePath), LockFileName))
+	if err != nil {
+		return nil, err
+	}
+	return func() { lock.Release() }, nil
+}
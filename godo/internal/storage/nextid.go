@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	//次に割り当てるタスクIDを記録するファイル名
+	NextIDFileName = "next_id"
+)
+
+// Loadはタスクと次に割り当てるIDを読み込み、TaskManagerを作成する
+// 削除・アーカイブしたタスクのIDは、変更履歴やWebhookがタスクを区別するために使うので再び割り当てない
+func (ts *TaskStorage) Load() (*models.TaskManager, error) {
+	tasks, err := ts.LoadTasks()
+	if err != nil {
+		return nil, err
+	}
+	next, err := ts.NextID()
+	if err != nil {
+		return nil, err
+	}
+	tm := models.NewTaskManager(tasks)
+	tm.ReserveIDs(next)
+	return tm, nil
+}
+
+// NextIDは次に割り当てるタスクIDの記録を返す
+// 記録がない場合（古いバージョンで作ったデータ）は、アーカイブと変更履歴に現れた最大のIDの次にする
+func (ts *TaskStorage) NextID() (int, error) {
+	data, err := os.ReadFile(ts.nextIDPath())
+	if err == nil {
+		next, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("%sの形式が不正です: %w", NextIDFileName, err)
+		}
+		return next, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("%sの読み込みに失敗しました: %w", NextIDFileName, err)
+	}
+
+	next := 1
+	archive, err := ts.LoadArchive()
+	if err != nil {
+		return 0, err
+	}
+	for _, task := range archive {
+		next = max(next, task.ID+1)
+	}
+	history, err := ts.LoadHistory()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range history {
+		next = max(next, e.TaskID+1)
+	}
+	return next, nil
+}
+
+// saveNextIDは次に割り当てるIDを記録する
+// 他のプロセスがより大きいIDを記録していた場合はそのままにする
+func (ts *TaskStorage) saveNextID(next int) error {
+	current, err := ts.NextID()
+	if err == nil {
+		if _, statErr := os.Stat(ts.nextIDPath()); statErr == nil && current >= next {
+			return nil
+		}
+		next = max(next, current)
+	}
+	if err := os.WriteFile(ts.nextIDPath(), []byte(strconv.Itoa(next)+"\n"), 0644); err != nil {
+		return fmt.Errorf("%sの保存に失敗しました: %w", NextIDFileName, err)
+	}
+	return nil
+}
+
+// nextIDPathは次に割り当てるIDを記録するファイルのパスを返す
+func (ts *TaskStorage) nextIDPath() string {
+	return filepath.Join(filepath.Dir(ts.filePath), NextIDFileName)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"godo/pkg/models"
+)
+
+func TestLoad_doesNotReuseDeletedOrArchivedIDs(t *testing.T) {
+	ts := NewTaskStorageAt(t.TempDir())
+
+	tm, err := ts.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.AddTask("first")
+	tm.AddTask("second")
+	tm.AddTask("third")
+	tm.ToggleTask(0)
+	if _, err := ts.ArchiveCompleted(tm, 0, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	tm.DeleteTask(tm.FindIndexByID(3))
+	if err := ts.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+
+	tm, err = ts.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.AddTask("fourth")
+	if task := tm.GetTaskByIndex(tm.FindIndexByID(4)); task == nil || task.Title != "fourth" {
+		t.Fatalf("expected new task to get ID 4, tasks = %+v", tm.GetTasks())
+	}
+	if err := ts.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+
+	// アーカイブから戻しても、その後に追加したタスクとIDが重ならない
+	archive, _ := ts.LoadArchive()
+	tm, _ = ts.Load()
+	if restored := tm.RestoreTask(archive[0]); restored.ID != 1 {
+		t.Fatalf("restored task was renumbered to %d", restored.ID)
+	}
+	tm.AddTask("fifth")
+	if last := tm.GetTaskByIndex(len(tm.GetTasks()) - 1); last.ID != 5 {
+		t.Fatalf("expected ID 5, got %d", last.ID)
+	}
+}
+
+func TestNextID_seedsFromArchiveAndHistory(t *testing.T) {
+	ts := NewTaskStorageAt(t.TempDir())
+	if err := ts.SaveTasks([]*models.Task{{ID: 2, Title: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SaveArchive([]*models.Task{{ID: 4, Title: "archived"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.AppendHistory([]models.Event{{TaskID: 7, Type: models.EventDeleted}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tm, err := ts.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := tm.NextID(); next != 8 {
+		t.Fatalf("NextID() = %d, want 8", next)
+	}
+	if err := ts.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(ts.nextIDPath()); string(data) != "8\n" {
+		t.Fatalf("next_id = %q", data)
+	}
+}
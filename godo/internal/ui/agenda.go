@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"godo/internal/report"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// アジェンダ表示のキー入力を処理する（処理した場合はtrueを返す）
+// 矢印キーでカレンダーの選択日を移動する
+func (m *Model) handleAgendaKey(key string) bool {
+	switch key {
+	case "left", "h":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, -1)
+	case "right", "l":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, 1)
+	case "up", "k":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, -7)
+	case "down", "j":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, 7)
+	case "t":
+		// 今日に戻る
+		m.calendarDay = startOfDay(time.Now())
+	case "q", "a", "esc":
+		return false
+	default:
+		// アジェンダ表示ではタスクの操作は行わない
+		return true
+	}
+	return true
+}
+
+// アジェンダ表示を描画する（左に期限ごとのグループ、右に月のカレンダー）
+func (m *Model) agendaView(now time.Time) string {
+	tasks := m.taskManager.GetTasks()
+	theme := m.options.Theme
+
+	paneStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(0, 1)
+
+	groupStyle := lipgloss.NewStyle().Bold(true)
+
+	overdueStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Alert)
+
+	dueStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+
+	var agenda strings.Builder
+	agenda.WriteString(groupStyle.Render("アジェンダ"))
+	for _, g := range report.BuildAgenda(tasks, now) {
+		if len(g.Tasks) == 0 {
+			continue
+		}
+		agenda.WriteString("\n\n")
+		label := fmt.Sprintf("%s (%d)", g.Label, len(g.Tasks))
+		if g.Label == report.AgendaOverdue {
+			agenda.WriteString(overdueStyle.Render(label))
+		} else {
+			agenda.WriteString(groupStyle.Render(label))
+		}
+		for _, task := range g.Tasks {
+			agenda.WriteString("\n• " + task.Title)
+			if task.DueAt != nil {
+				agenda.WriteString(dueStyle.Render("  " + report.FormatDue(*task.DueAt)))
+			}
+		}
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		paneStyle.Render(agenda.String()),
+		paneStyle.Render(m.calendarView(now)))
+}
+
+// 選択日を含む月のカレンダーと、選択日に期限があるタスクを描画する
+func (m *Model) calendarView(now time.Time) string {
+	tasks := m.taskManager.GetTasks()
+	counts := report.DueCounts(tasks)
+	today := startOfDay(now)
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	theme := m.options.Theme
+	weekdayStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	todayStyle := lipgloss.NewStyle().Underline(true).Foreground(theme.Accent)
+	hasTaskStyle := lipgloss.NewStyle().Foreground(theme.Pending)
+	selectedStyle := theme.selected()
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(m.calendarDay.Format("2006年01月")))
+	s.WriteString("\n")
+	s.WriteString(weekdayStyle.Render(" 月  火  水  木  金  土  日  "))
+	for _, week := range report.MonthGrid(m.calendarDay) {
+		s.WriteString("\n")
+		for _, day := range week {
+			if day.IsZero() {
+				s.WriteString("     ")
+				continue
+			}
+			// "12·3" のように日付とその日が期限のタスク数を表示する
+			cell := fmt.Sprintf("%2d", day.Day())
+			if n := counts[day.Format("2006-01-02")]; n > 0 {
+				cell += fmt.Sprintf("·%-1d", min(n, 9))
+			} else {
+				cell += "  "
+			}
+
+			style := lipgloss.NewStyle()
+			if counts[day.Format("2006-01-02")] > 0 {
+				style = hasTaskStyle
+			}
+			if day.Equal(today) {
+				style = todayStyle
+			}
+			if day.Equal(m.calendarDay) {
+				style = style.Inherit(selectedStyle)
+			}
+			s.WriteString(style.Render(cell) + " ")
+		}
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render(report.FormatDue(m.calendarDay) + " の期限"))
+	found := false
+	for _, task := range tasks {
+		if task.DueAt != nil && startOfDay(*task.DueAt).Equal(m.calendarDay) {
+			status := "○"
+			if task.Completed {
+				status = "✓"
+			}
+			s.WriteString(fmt.Sprintf("\n%s %s", status, task.Title))
+			found = true
+		}
+	}
+	if !found {
+		s.WriteString("\nなし")
+	}
+	return s.String()
+}
+
+// 日付の0時0分を返す
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
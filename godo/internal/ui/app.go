@@ -1,10 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"godo/internal/models"
+	"godo/internal/api"
+	"godo/internal/feed"
+	"godo/internal/hooks"
 	"godo/internal/storage"
+	"godo/internal/webhook"
+	"godo/pkg/extension"
+	"godo/pkg/models"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,39 +27,146 @@ const (
 	deleteConfirmMode
 )
 
+// Options TUIアプリケーションの設定
+type Options struct {
+	Pomodoro    PomodoroSettings
+	Workflow    models.Workflow
+	AutoArchive time.Duration       // 保存時に自動アーカイブする期間（0で無効）
+	StorageMode storage.Mode        // タスクの保存形式
+	Remote      *api.Remote         // godo serve のサーバーに接続する場合に設定する（nilならローカルのファイル）
+	Hooks       *hooks.Runner       // 保存する前に実行するフック（nilなら実行しない）
+	Webhooks    *webhook.Dispatcher // 保存した変更を送る Webhook（nilなら送らない）
+	Extensions  extension.Set       // 計算項目を一覧に表示する拡張機能
+	DateFormat  string              // 日時の表示形式（Go の time.Format のレイアウト）
+	Theme       Theme               // 配色
+	Keys        KeyMap              // 通常モードのキー割り当て
+	DataDir     string              // タスクを保存するディレクトリ（空なら ~/.godo）
+}
+
+// DefaultOptions デフォルトの設定を返す
+func DefaultOptions() Options {
+	return Options{
+		Pomodoro:   DefaultPomodoroSettings(),
+		Workflow:   models.DefaultWorkflow(),
+		DateFormat: DefaultDateFormat,
+		Theme:      DefaultTheme(),
+		Keys:       DefaultKeyMap(),
+	}
+}
+
+// DefaultDateFormat デフォルトの日時の表示形式
+const DefaultDateFormat = "2006-01-02 15:04"
+
+// タスクの表示レイアウト
+type layout int
+
+const (
+	listLayout layout = iota
+	boardLayout
+	agendaLayout
+)
+
 // アプリケーションのモデル
 type Model struct {
 	taskManager *models.TaskManager
 	storage     *storage.TaskStorage
+	remote      *api.Remote       // サーバーに接続している場合のみ設定される
+	changes     chan feed.Change  // サーバーから受け取った変更（サーバーに接続している場合のみ）
+	saved       []*models.Task    // 最後に保存した時点のタスク（フックに渡す変更前の内容）
 	cursor      int                // 選択中のタスクのインデックス
 	mode        mode              // 現在のモード
 	inputValue  string            // 入力中のテキスト
 	editingTask int               // 編集中のタスクのインデックス
+	ticking     bool              // 経過時間表示用のtickが動いているか
+	options     Options           // アプリケーションの設定
+	pomodoro    *pomodoro         // 実行中のポモドーロ（なければnil）
+	layout      layout            // 現在の表示レイアウト
+	boardColumn int               // ボード表示で選択中の列
+	boardRow    int               // ボード表示で選択中の列内の行
+	calendarDay time.Time         // アジェンダ表示のカレンダーで選択中の日
+	message     string            // フッターに一度だけ表示するメッセージ
+	showHistory bool              // 選択中のタスクの変更履歴を表示するか
+	fields      map[int]map[string]string // 拡張機能が計算した項目の値（タスクのIDごと）
+}
+
+// 経過時間表示を更新するためのメッセージ
+type tickMsg time.Time
+
+// 1秒後にtickMsgを送るコマンド
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
 }
 
 // 初期化関数
 func NewModel() *Model {
-	storage := storage.NewTaskStorage()
-	tasks, err := storage.LoadTasks()
+	return NewModelWithOptions(DefaultOptions())
+}
+
+// 設定を指定して初期化する関数
+func NewModelWithOptions(options Options) *Model {
+	dir := options.DataDir
+	if dir == "" {
+		dir = storage.DefaultDir()
+	}
+	storage := storage.NewTaskStorageAt(dir)
+	storage.SetMode(options.StorageMode)
+	storage.SetAutoArchive(options.AutoArchive)
+	manager, err := storage.Load()
 	if err != nil {
-		tasks = []*models.Task{}
+		manager = models.NewTaskManager([]*models.Task{})
+	}
+	manager.SetWorkflow(options.Workflow)
+	if history, err := storage.LoadHistory(); err == nil {
+		manager.LoadHistory(history)
+	}
+	message := ""
+	if options.Remote != nil {
+		// サーバーに接続する場合はローカルのタスクの代わりにサーバーのタスクを使う
+		manager = models.NewTaskManager([]*models.Task{})
+		manager.SetWorkflow(options.Workflow)
+		if remoteManager, err := options.Remote.Load(); err != nil {
+			message = fmt.Sprintf("サーバーから読み込めません: %v", err)
+		} else {
+			manager = remoteManager
+		}
 	}
 	
-	manager := models.NewTaskManager(tasks)
-	
-	return &Model{
+	m := &Model{
 		taskManager: manager,
 		storage:     storage,
+		saved:       hooks.Snapshot(manager.GetTasks()),
+		remote:      options.Remote,
+		message:     message,
 		cursor:      0,
 		mode:        normalMode,
 		inputValue:  "",
 		editingTask: -1,
+		options:     options,
 	}
+	m.refreshFields()
+	return m
 }
 
 // 初期化コマンド
 func (m *Model) Init() tea.Cmd {
-	return nil
+	// 前回から計測中のタイマーがあれば経過時間の表示を開始
+	return tea.Batch(m.startTicking(), m.watchChanges())
+}
+
+// 計測中のタイマーか実行中のポモドーロがあればtickが必要
+func (m *Model) needsTick() bool {
+	return m.pomodoro != nil || m.taskManager.RunningTimerIndex() >= 0
+}
+
+// tickが必要でまだ動いていなければ開始する
+func (m *Model) startTicking() tea.Cmd {
+	if m.ticking || !m.needsTick() {
+		return nil
+	}
+	m.ticking = true
+	return tick()
 }
 
 // アップデート関数
@@ -60,12 +174,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
+	case tickMsg:
+		// 計測中のタイマーやポモドーロがなくなったらtickを止める
+		if !m.needsTick() {
+			m.ticking = false
+			return m, nil
+		}
+		return m, tea.Batch(tick(), m.advancePomodoro(time.Time(msg)))
+	case changeMsg:
+		return m, m.handleChange(msg)
 	}
 	return m, nil
 }
 
 // キー入力の処理
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// メッセージは次のキー入力で消す
+	m.message = ""
+
 	switch m.mode {
 	case normalMode:
 		return m.handleNormalMode(msg)
@@ -82,10 +208,68 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // ノーマルモードの処理
 func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	tasks := m.taskManager.GetTasks()
+	// 設定で割り当てを変えたキーはデフォルトのキーとして処理する
+	key := m.options.Keys.normalize(msg.String())
+
+	// ボード表示では移動キーをボード用に処理する
+	if m.layout == boardLayout && m.handleBoardKey(key) {
+		return m, nil
+	}
+	if m.layout == agendaLayout && m.handleAgendaKey(key) {
+		return m, nil
+	}
 	
-	switch msg.String() {
+	switch key {
 	case "q":
 		return m, tea.Quit
+	case "b":
+		// リスト表示とボード表示の切り替え
+		if m.layout == boardLayout {
+			m.layout = listLayout
+		} else {
+			m.layout = boardLayout
+		}
+		if m.layout == boardLayout && len(tasks) > 0 && m.cursor < len(tasks) {
+			// 選択中のタスクがある列から表示する
+			m.boardColumn = m.taskManager.Workflow().Index(m.taskManager.StatusOf(tasks[m.cursor]))
+			m.boardRow = indexOf(m.columnTasks(m.boardColumn), m.cursor)
+		}
+		m.syncBoardCursor()
+	case "a":
+		// アジェンダ表示の切り替え
+		if m.layout == agendaLayout {
+			m.layout = listLayout
+		} else {
+			m.layout = agendaLayout
+			m.calendarDay = startOfDay(time.Now())
+		}
+	case "esc":
+		m.layout = listLayout
+	case "i":
+		// 変更履歴パネルの表示切り替え
+		m.showHistory = !m.showHistory
+	case "A":
+		// 完了済みのタスクをアーカイブに移す
+		if m.remote != nil {
+			m.message = "サーバーに接続している間はアーカイブできません"
+			break
+		}
+		var archived []*models.Task
+		err := m.withLock(func() (err error) {
+			archived, err = m.storage.ArchiveCompleted(m.taskManager, 0, time.Now())
+			return err
+		})
+		if err != nil {
+			m.message = fmt.Sprintf("アーカイブに失敗しました: %v", err)
+			break
+		}
+		m.message = fmt.Sprintf("%d件のタスクをアーカイブしました", len(archived))
+		if m.cursor >= len(m.taskManager.GetTasks()) {
+			m.cursor = max(len(m.taskManager.GetTasks())-1, 0)
+		}
+		if m.layout == boardLayout {
+			m.syncBoardCursor()
+		}
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
@@ -95,10 +279,13 @@ func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.cursor++
 		}
 	case "enter":
-		if len(tasks) > 0 && m.cursor < len(tasks) {
+		if m.hasSelection() {
 			// タスクの完了状態を切り替え
-			tasks[m.cursor].Completed = !tasks[m.cursor].Completed
+			m.taskManager.ToggleTask(m.cursor)
 			m.saveToFile()
+			if m.layout == boardLayout {
+				m.syncBoardCursor()
+			}
 		}
 	case "n":
 		// 新しいタスクを追加モード
@@ -106,16 +293,37 @@ func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.inputValue = ""
 	case "e":
 		// タスク編集モード
-		if len(tasks) > 0 && m.cursor < len(tasks) {
+		if m.hasSelection() {
 			m.mode = editMode
 			m.editingTask = m.cursor
 			m.inputValue = tasks[m.cursor].Title
 		}
 	case "d":
 		// タスク削除確認モード
-		if len(tasks) > 0 && m.cursor < len(tasks) {
+		if m.hasSelection() {
 			m.mode = deleteConfirmMode
 		}
+	case "s":
+		// タイマーの開始/停止
+		if m.hasSelection() {
+			if tasks[m.cursor].RunningEntry() != nil {
+				m.taskManager.StopTimer()
+			} else {
+				m.taskManager.StartTimer(m.cursor, "")
+			}
+			m.saveToFile()
+			return m, m.startTicking()
+		}
+	case "p":
+		// 選択したタスクでポモドーロを開始/停止
+		if m.hasSelection() {
+			if m.pomodoro != nil && m.pomodoro.taskID == tasks[m.cursor].ID {
+				m.pomodoro = nil
+				return m, nil
+			}
+			m.pomodoro = newPomodoro(tasks[m.cursor].ID, m.options.Pomodoro, time.Now())
+			return m, m.startTicking()
+		}
 	}
 	return m, nil
 }
@@ -150,9 +358,7 @@ func (m *Model) handleEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
 		if strings.TrimSpace(m.inputValue) != "" {
-			tasks := m.taskManager.GetTasks()
-			if m.editingTask < len(tasks) {
-				tasks[m.editingTask].Title = strings.TrimSpace(m.inputValue)
+			if m.taskManager.UpdateTask(m.editingTask, strings.TrimSpace(m.inputValue)) {
 				m.saveToFile()
 			}
 		}
@@ -180,13 +386,9 @@ func (m *Model) handleDeleteConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y":
 		// タスクを削除
-		tasks := m.taskManager.GetTasks()
-		if m.cursor < len(tasks) {
-			// スライスから要素を削除
-			copy(tasks[m.cursor:], tasks[m.cursor+1:])
-			tasks = tasks[:len(tasks)-1]
-			m.taskManager = models.NewTaskManager(tasks)
-			
+		if m.taskManager.DeleteTask(m.cursor) {
+			tasks := m.taskManager.GetTasks()
+
 			// カーソル位置を調整
 			if m.cursor >= len(tasks) && len(tasks) > 0 {
 				m.cursor = len(tasks) - 1
@@ -195,6 +397,10 @@ func (m *Model) handleDeleteConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.cursor = 0
 			}
 			
+			if m.layout == boardLayout {
+				m.syncBoardCursor()
+			}
+
 			m.saveToFile()
 		}
 		m.mode = normalMode
@@ -204,9 +410,91 @@ func (m *Model) handleDeleteConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// ポモドーロのフェーズが終了していれば次のフェーズに進め、通知する
+func (m *Model) advancePomodoro(now time.Time) tea.Cmd {
+	if m.pomodoro == nil {
+		return nil
+	}
+	finished, ok := m.pomodoro.advance(now)
+	if !ok {
+		return nil
+	}
+
+	title := ""
+	if index := m.taskManager.FindIndexByID(m.pomodoro.taskID); index >= 0 {
+		title = m.taskManager.GetTaskByIndex(index).Title
+		if finished == pomodoroWork {
+			// 完了したポモドーロをタスクに記録
+			m.taskManager.AddPomodoro(index)
+			m.saveToFile()
+		}
+	}
+
+	if finished == pomodoroWork {
+		return notify(fmt.Sprintf("ポモドーロ完了: %s - %sを始めましょう", title, m.pomodoro.phase))
+	}
+	return notify(fmt.Sprintf("休憩終了: %s の作業を再開しましょう", title))
+}
+
 // ファイルに保存
 func (m *Model) saveToFile() {
-	m.storage.SaveTasks(m.taskManager.GetTasks())
+	m.runHooks()
+	if m.remote != nil {
+		m.saveToServer()
+		return
+	}
+	if err := m.withLock(func() error { return m.storage.Save(m.taskManager) }); err != nil {
+		m.message = fmt.Sprintf("保存できません: %v", err)
+		return
+	}
+	m.saved = hooks.Snapshot(m.taskManager.GetTasks())
+	m.refreshFields()
+	m.flushWebhooks()
+}
+
+// 他のプロセス（godo serve や godo コマンド）がタスクを書き込まないようにロックを取って fn を実行する
+func (m *Model) withLock(fn func() error) error {
+	unlock, err := m.storage.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// 保存した変更を Webhook に送る
+// 送れなかった変更は送信待ちに残って次の保存のときに送り直されるので、画面の操作は待たせない
+func (m *Model) flushWebhooks() {
+	if m.options.Webhooks == nil {
+		return
+	}
+	go func(d *webhook.Dispatcher) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		d.Flush(ctx)
+	}(m.options.Webhooks)
+}
+
+// 前回の保存からの変更についてフックを実行する
+// 取り消された変更は元に戻っているので、理由をフッターに表示する
+func (m *Model) runHooks() {
+	messages, err := m.options.Hooks.Run(m.taskManager, m.saved)
+	if err != nil {
+		m.message = strings.ReplaceAll(err.Error(), "\n", " / ")
+	} else if len(messages) > 0 {
+		m.message = strings.Join(messages, " / ")
+	}
+	if m.cursor >= len(m.taskManager.GetTasks()) {
+		m.cursor = max(len(m.taskManager.GetTasks())-1, 0)
+	}
+}
+
+// サーバーに変更を送り、他のクライアントの変更も含めて読み込み直す
+func (m *Model) saveToServer() {
+	if err := m.remote.Save(m.taskManager); err != nil {
+		m.message = fmt.Sprintf("サーバーに保存できません: %v", err)
+	}
+	m.reloadFromServer()
 }
 
 // ビュー関数
@@ -214,27 +502,36 @@ func (m *Model) View() string {
 	var s strings.Builder
 	
 	// スタイル定義
+	theme := m.options.Theme
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("205")).
+		Foreground(theme.Accent).
 		MarginBottom(1)
 		
 	completedStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("2")) // 緑
+		Foreground(theme.Done)
 		
 	incompleteStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("208")) // オレンジ
+		Foreground(theme.Pending)
 		
-	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("240")).
+	selectedStyle := theme.selected().
 		Padding(0, 1)
 		
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
+		Foreground(theme.Footer).
 		MarginTop(1)
 		
 	dateStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))
+		Foreground(theme.Muted)
+
+	timerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Timer)
+
+	pomodoroStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Alert).
+		Padding(0, 1)
 
 	// ヘッダー
 	completed, total := m.taskManager.GetStats()
@@ -242,11 +539,31 @@ func (m *Model) View() string {
 	s.WriteString(headerStyle.Render(header))
 	s.WriteString("\n\n")
 
+	now := time.Now()
+	if running := m.taskManager.RunningTimerIndex(); running >= 0 {
+		task := m.taskManager.GetTaskByIndex(running)
+		s.WriteString(timerStyle.Render(fmt.Sprintf("⏱ 計測中: %s  %s",
+			task.Title, formatElapsed(task.RunningEntry().Duration(now)))))
+		s.WriteString("\n\n")
+	}
+
+	if m.pomodoro != nil {
+		s.WriteString(pomodoroStyle.Render(m.pomodoroPanel(now)))
+		s.WriteString("\n\n")
+	}
+
 	// タスクリスト
 	tasks := m.taskManager.GetTasks()
 	if len(tasks) == 0 {
 		s.WriteString("タスクがありません。'n'で新しいタスクを追加してください。\n")
+	} else if m.layout == boardLayout {
+		s.WriteString(m.boardView())
+		s.WriteString("\n")
+	} else if m.layout == agendaLayout {
+		s.WriteString(m.agendaView(now))
+		s.WriteString("\n")
 	} else {
+		workflow := m.taskManager.Workflow()
 		for i, task := range tasks {
 			var status string
 			var taskStyle lipgloss.Style
@@ -260,9 +577,40 @@ func (m *Model) View() string {
 			}
 			
 			taskLine := fmt.Sprintf("%s %s", status, task.Title)
-			dateLine := dateStyle.Render(fmt.Sprintf("    作成: %s | 更新: %s", 
-				task.CreatedAt.Format("2006-01-02 15:04"),
-				task.UpdatedAt.Format("2006-01-02 15:04")))
+			if task.Priority != "" {
+				taskLine = fmt.Sprintf("%s (%s) %s", status, task.Priority, task.Title)
+			}
+			if st := workflow.StatusOf(task); st != workflow.FirstStatus() && st != workflow.DoneStatus() {
+				// 途中の状態はラベルで表示
+				taskLine += fmt.Sprintf(" [%s]", st)
+			}
+			if task.RunningEntry() != nil {
+				taskLine += " ⏱"
+			}
+			if task.Pomodoros > 0 {
+				taskLine += fmt.Sprintf(" 🍅x%d", task.Pomodoros)
+			}
+			dateInfo := fmt.Sprintf("    作成: %s | 更新: %s", 
+				task.CreatedAt.Format(m.options.DateFormat),
+				task.UpdatedAt.Format(m.options.DateFormat))
+			if task.Completed && task.CompletedAt != nil {
+				dateInfo += " | 完了: " + task.CompletedAt.Format(m.options.DateFormat)
+			}
+			if task.DueAt != nil {
+				dateInfo += " | 期限: " + task.DueAt.Format(m.options.DateFormat)
+				if !task.Completed && task.DueAt.Before(now) {
+					dateInfo += " (期限切れ)"
+				}
+			}
+			if len(task.TimeEntries) > 0 {
+				dateInfo += fmt.Sprintf(" | 作業: %s", formatElapsed(task.TrackedTime(now)))
+			}
+			for _, f := range m.options.Extensions.Columns() {
+				if value := m.fields[task.ID][f.Name]; value != "" {
+					dateInfo += fmt.Sprintf(" | %s: %s", f.Label(), value)
+				}
+			}
+			dateLine := dateStyle.Render(dateInfo)
 			
 			if i == m.cursor {
 				s.WriteString(selectedStyle.Render(taskStyle.Render(taskLine)))
@@ -277,6 +625,11 @@ func (m *Model) View() string {
 		}
 	}
 
+	if m.showHistory && m.hasSelection() {
+		s.WriteString(m.historyPanel())
+		s.WriteString("\n")
+	}
+
 	// モード別の表示
 	switch m.mode {
 	case inputMode:
@@ -297,17 +650,84 @@ func (m *Model) View() string {
 		
 	default:
 		// フッター（操作説明）
-		footer := "操作: Enter=完了切替 | n=追加 | e=編集 | d=削除 | ↑↓=選択 | q=終了"
+		keys := m.options.Keys.labels()
+		footer := fmt.Sprintf("操作: %s=完了切替 | %s=追加 | %s=編集 | %s=削除 | %s=タイマー | %s=ポモドーロ | %s=ボード | %s=アジェンダ | %s=アーカイブ | %s=履歴 | ↑↓=選択 | %s=終了",
+			keys.Toggle, keys.Add, keys.Edit, keys.Delete, keys.Timer, keys.Pomodoro, keys.Board, keys.Agenda, keys.Archive, keys.History, keys.Quit)
+		if m.layout == agendaLayout {
+			footer = fmt.Sprintf("アジェンダ: ←→/hl=前後の日 | ↑↓/%s%s=前後の週 | t=今日 | %s=リスト | %s=終了",
+				keys.Down, keys.Up, keys.Agenda, keys.Quit)
+		}
+		if m.layout == boardLayout {
+			footer = fmt.Sprintf("ボード: ←→/hl=列 | ↑↓/%s%s=選択 | H/L=タスクを移動 | %s=完了切替 | %s=追加 | %s=編集 | %s=削除 | %s=リスト | %s=終了",
+				keys.Down, keys.Up, keys.Toggle, keys.Add, keys.Edit, keys.Delete, keys.Board, keys.Quit)
+		}
 		s.WriteString("\n")
+		if m.message != "" {
+			s.WriteString("\n")
+			s.WriteString(m.message)
+		}
 		s.WriteString(footerStyle.Render(footer))
 	}
 
 	return s.String()
 }
 
+// 変更履歴パネルに表示する最大件数
+const historyPanelSize = 10
+
+// 選択中のタスクの変更履歴パネルを描画する（新しい順）
+func (m *Model) historyPanel() string {
+	task := m.taskManager.GetTaskByIndex(m.cursor)
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.options.Theme.Muted).
+		Padding(0, 1)
+
+	timeStyle := lipgloss.NewStyle().
+		Foreground(m.options.Theme.Muted)
+
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("📜 '%s' の変更履歴", task.Title))
+	events := m.taskManager.History(task.ID)
+	if len(events) == 0 {
+		s.WriteString("\n履歴はありません")
+	}
+	for i := len(events) - 1; i >= 0 && i >= len(events)-historyPanelSize; i-- {
+		s.WriteString("\n")
+		s.WriteString(timeStyle.Render(events[i].Time.Format(m.options.DateFormat)))
+		s.WriteString("  " + events[i].Summary())
+	}
+	return panelStyle.Render(s.String())
+}
+
+// ポモドーロのカウントダウンパネルを描画する
+func (m *Model) pomodoroPanel(now time.Time) string {
+	title := "(削除されたタスク)"
+	if index := m.taskManager.FindIndexByID(m.pomodoro.taskID); index >= 0 {
+		title = m.taskManager.GetTaskByIndex(index).Title
+	}
+
+	const barWidth = 20
+	filled := int(m.pomodoro.progress(now) * barWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	return fmt.Sprintf("🍅 %s: %s\n%s %s  (このセッション: %d回)",
+		m.pomodoro.phase, title, bar, formatCountdown(m.pomodoro.remaining(now)), m.pomodoro.cycles)
+}
+
+// 経過時間を "01:02:03" の形式に変換する
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	sec := int(d.Seconds()) % 60
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, sec)
+}
+
 // TUIアプリケーションを開始する関数
-func RunApp() error {
-	model := NewModel()
+func RunApp(options Options) error {
+	model := NewModelWithOptions(options)
 	p := tea.NewProgram(model)
 	_, err := p.Run()
 	return err
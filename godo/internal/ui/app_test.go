@@ -1,8 +1,14 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"godo/pkg/models"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -189,3 +195,246 @@ func TestViewShowsStates(t *testing.T) {
 }
 
 
+
+func TestTimerToggleWithS(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter")
+	// s でタイマー開始 -> tick が開始される
+	_, cmd := m.Update(key("s"))
+	if cmd == nil || !m.ticking {
+		t.Fatalf("starting a timer should start ticking")
+	}
+	if m.taskManager.RunningTimerIndex() != 0 {
+		t.Fatalf("expected running timer on first task")
+	}
+	if !strings.Contains(m.View(), "計測中") {
+		t.Fatalf("view should show the running timer")
+	}
+	// 計測中は tick が継続する
+	if _, cmd := m.Update(tickMsg(time.Now())); cmd == nil {
+		t.Fatalf("tick should continue while timer is running")
+	}
+	// もう一度 s で停止 -> 次の tick で止まる
+	m = sendKeys(m, "s")
+	if m.taskManager.RunningTimerIndex() != -1 {
+		t.Fatalf("timer should be stopped")
+	}
+	if _, cmd := m.Update(tickMsg(time.Now())); cmd != nil || m.ticking {
+		t.Fatalf("tick should stop when no timer is running")
+	}
+}
+
+func TestPomodoroCountsCompletedSessions(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter")
+
+	// p でポモドーロ開始 -> パネルが表示される
+	_, cmd := m.Update(key("p"))
+	if cmd == nil || m.pomodoro == nil {
+		t.Fatalf("pressing p should start a pomodoro")
+	}
+	if !strings.Contains(m.View(), "🍅 作業") {
+		t.Fatalf("view should show the pomodoro panel: %s", m.View())
+	}
+
+	// 作業時間の経過後、完了数が記録され通知される
+	cmd = m.advancePomodoro(m.pomodoro.endsAt)
+	if cmd == nil {
+		t.Fatalf("finishing a pomodoro should notify")
+	}
+	// 通知は tea.Println でプログラムの出力に書き込む
+	notified := fmt.Sprint(cmd())
+	if got := m.taskManager.GetTasks()[0].Pomodoros; got != 1 {
+		t.Fatalf("expected 1 completed pomodoro, got %d", got)
+	}
+	if m.pomodoro.phase != pomodoroShortBreak {
+		t.Fatalf("expected break after work, got %v", m.pomodoro.phase)
+	}
+	if !strings.Contains(notified, "\a") || !strings.Contains(notified, "\x1b]9;") {
+		t.Fatalf("expected bell and OSC 9 notification, got %q", notified)
+	}
+
+	// 完了数は保存されている
+	reloaded := NewModel()
+	if got := reloaded.taskManager.GetTasks()[0].Pomodoros; got != 1 {
+		t.Fatalf("pomodoro count should be persisted, got %d", got)
+	}
+
+	// 同じタスクでもう一度 p を押すと停止
+	m = sendKeys(m, "p")
+	if m.pomodoro != nil {
+		t.Fatalf("pressing p again should stop the pomodoro")
+	}
+}
+
+func TestBoardMoveTasksBetweenColumns(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter", "n", "b", "enter")
+	m = sendKeys(m, "b")
+	if m.layout != boardLayout || m.boardColumn != 0 {
+		t.Fatalf("expected board view focused on first column")
+	}
+	// L で todo -> doing に移動し、選択も追従する
+	m = sendKeys(m, "L")
+	if got := m.taskManager.GetTasks()[0].Status; got != "doing" {
+		t.Fatalf("expected doing, got %q", got)
+	}
+	if m.boardColumn != 1 || m.cursor != 0 {
+		t.Fatalf("selection should follow the task: column=%d cursor=%d", m.boardColumn, m.cursor)
+	}
+	// h で最初の列へ戻り、残りのタスクを選択
+	m = sendKeys(m, "h")
+	if m.cursor != 1 {
+		t.Fatalf("expected cursor on remaining todo task, got %d", m.cursor)
+	}
+	// L L L で done まで移動すると完了扱いになる
+	m = sendKeys(m, "L", "L", "L")
+	if task := m.taskManager.GetTasks()[1]; !task.Completed || task.Status != "done" {
+		t.Fatalf("task moved to done should be completed: %+v", task)
+	}
+	if !strings.Contains(m.View(), "review") {
+		t.Fatalf("board should render all columns")
+	}
+	m = sendKeys(m, "b")
+	if m.layout != listLayout {
+		t.Fatalf("b should switch back to list view")
+	}
+}
+
+func TestBoardHighlightsWIPLimit(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	options := DefaultOptions()
+	workflow, err := models.ParseWorkflow("todo:1,done")
+	if err != nil {
+		t.Fatalf("parse workflow: %v", err)
+	}
+	options.Workflow = workflow
+	m := NewModelWithOptions(options)
+	m = sendKeys(m, "n", "a", "enter", "n", "b", "enter", "b")
+	if !strings.Contains(m.View(), "WIP超過") {
+		t.Fatalf("exceeded WIP limit should be highlighted: %s", m.View())
+	}
+}
+
+func TestAgendaViewNavigatesCalendar(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter")
+	tomorrow := startOfDay(time.Now()).AddDate(0, 0, 1)
+	m.taskManager.SetDue(0, &tomorrow)
+
+	m = sendKeys(m, "a")
+	if m.layout != agendaLayout {
+		t.Fatalf("a should open the agenda view")
+	}
+	out := m.View()
+	if !strings.Contains(out, "明日 (1)") || !strings.Contains(out, tomorrow.Format("2006年01月")) {
+		t.Fatalf("agenda should group the task under tomorrow: %s", out)
+	}
+
+	// → で翌日へ、↓ で翌週へ移動
+	today := startOfDay(time.Now())
+	m = sendKeys(m, "right")
+	if !m.calendarDay.Equal(tomorrow) {
+		t.Fatalf("expected calendar on tomorrow, got %v", m.calendarDay)
+	}
+	m = sendKeys(m, "down", "up", "left")
+	if !m.calendarDay.Equal(today) {
+		t.Fatalf("expected calendar back on today, got %v", m.calendarDay)
+	}
+	// アジェンダ表示ではタスクを操作しない
+	m = sendKeys(m, "d")
+	if m.mode != normalMode {
+		t.Fatalf("task keys should be ignored in agenda view")
+	}
+	m = sendKeys(m, "a")
+	if m.layout != listLayout {
+		t.Fatalf("a should return to the list view")
+	}
+}
+
+func TestArchiveCompletedWithShiftA(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter", "n", "b", "enter")
+	m = sendKeys(m, "down", "enter", "A")
+	tasks := m.taskManager.GetTasks()
+	if len(tasks) != 1 || tasks[0].Title != "a" {
+		t.Fatalf("completed task should be archived: %+v", tasks)
+	}
+	if m.cursor != 0 {
+		t.Fatalf("cursor should be clamped, got %d", m.cursor)
+	}
+	if !strings.Contains(m.View(), "1件のタスクをアーカイブしました") {
+		t.Fatalf("archive message should be shown")
+	}
+	archive, err := m.storage.LoadArchive()
+	if err != nil || len(archive) != 1 || archive[0].Title != "b" {
+		t.Fatalf("archive file should contain b: %+v (%v)", archive, err)
+	}
+}
+
+func TestHistoryPanelShowsChanges(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	m = sendKeys(m, "n", "a", "enter", "e", "b", "enter", "enter")
+	m = sendKeys(m, "i")
+	out := m.View()
+	for _, want := range []string{"変更履歴", "作成: 'a'", "タイトル変更: 'a' → 'ab'", "完了"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("history panel should contain %q: %s", want, out)
+		}
+	}
+
+	// 履歴は保存され、次回起動時にも表示される
+	reloaded := NewModel()
+	if got := len(reloaded.taskManager.History(1)); got != 3 {
+		t.Fatalf("expected 3 persisted events, got %d", got)
+	}
+	m = sendKeys(m, "i")
+	if strings.Contains(m.View(), "変更履歴") {
+		t.Fatalf("i should hide the history panel")
+	}
+}
+
+func TestSaveErrorIsShown(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	m := NewModel()
+	// タスクファイルの場所をディレクトリにして書き込めなくする
+	path := m.storage.GetFilePath()
+	os.Remove(path)
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m = sendKeys(m, "n", "a", "enter")
+	if !strings.Contains(m.View(), "保存できません") {
+		t.Fatalf("save error should be shown in the footer")
+	}
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ボード表示の1列の幅
+const boardColumnWidth = 24
+
+// 指定された列に属するタスクのインデックスを返す
+func (m *Model) columnTasks(column int) []int {
+	workflow := m.taskManager.Workflow()
+	var indices []int
+	for i, task := range m.taskManager.GetTasks() {
+		if workflow.Index(m.taskManager.StatusOf(task)) == column {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// ボード上の選択位置を範囲内に収め、カーソルを選択中のタスクに合わせる
+func (m *Model) syncBoardCursor() {
+	columns := len(m.taskManager.Workflow().Columns)
+	if m.boardColumn >= columns {
+		m.boardColumn = columns - 1
+	}
+	if m.boardColumn < 0 {
+		m.boardColumn = 0
+	}
+
+	indices := m.columnTasks(m.boardColumn)
+	if m.boardRow >= len(indices) {
+		m.boardRow = len(indices) - 1
+	}
+	if m.boardRow < 0 {
+		m.boardRow = 0
+	}
+	if len(indices) > 0 {
+		m.cursor = indices[m.boardRow]
+	}
+}
+
+// 操作対象のタスクが選択されているか
+// ボード表示では選択中の列が空なら選択なしとみなす
+func (m *Model) hasSelection() bool {
+	tasks := m.taskManager.GetTasks()
+	if len(tasks) == 0 || m.cursor >= len(tasks) {
+		return false
+	}
+	return m.layout != boardLayout || len(m.columnTasks(m.boardColumn)) > 0
+}
+
+// ボード表示のキー入力を処理する（処理した場合はtrueを返す）
+func (m *Model) handleBoardKey(key string) bool {
+	columns := len(m.taskManager.Workflow().Columns)
+
+	switch key {
+	case "left", "h":
+		if m.boardColumn > 0 {
+			m.boardColumn--
+			m.boardRow = 0
+		}
+	case "right", "l":
+		if m.boardColumn < columns-1 {
+			m.boardColumn++
+			m.boardRow = 0
+		}
+	case "up", "k":
+		if m.boardRow > 0 {
+			m.boardRow--
+		}
+	case "down", "j":
+		m.boardRow++
+	case "H", "L":
+		// 選択中のタスクを隣の列に移動し、選択も追従させる
+		if len(m.columnTasks(m.boardColumn)) == 0 {
+			return true
+		}
+		delta := 1
+		if key == "H" {
+			delta = -1
+		}
+		if m.taskManager.MoveTask(m.cursor, delta) {
+			m.boardColumn += delta
+			m.boardRow = indexOf(m.columnTasks(m.boardColumn), m.cursor)
+			m.saveToFile()
+		}
+	default:
+		return false
+	}
+	m.syncBoardCursor()
+	return true
+}
+
+// ボード表示を描画する
+func (m *Model) boardView() string {
+	workflow := m.taskManager.Workflow()
+	tasks := m.taskManager.GetTasks()
+	theme := m.options.Theme
+
+	columnStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Width(boardColumnWidth).
+		Padding(0, 1)
+
+	focusedColumnStyle := columnStyle.
+		BorderForeground(theme.Accent)
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+
+	overLimitStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Alert)
+
+	selectedStyle := theme.selected()
+
+	rendered := make([]string, len(workflow.Columns))
+	for c, column := range workflow.Columns {
+		indices := m.columnTasks(c)
+
+		var body strings.Builder
+		header := fmt.Sprintf("%s (%d)", column.Name, len(indices))
+		if column.WIPLimit > 0 {
+			header = fmt.Sprintf("%s (%d/%d)", column.Name, len(indices), column.WIPLimit)
+		}
+		if column.WIPLimit > 0 && len(indices) > column.WIPLimit {
+			body.WriteString(overLimitStyle.Render(header + " ⚠ WIP超過"))
+		} else {
+			body.WriteString(titleStyle.Render(header))
+		}
+		body.WriteString("\n")
+
+		for _, i := range indices {
+			line := "• " + tasks[i].Title
+			if c == m.boardColumn && i == m.cursor {
+				line = selectedStyle.Render(line)
+			}
+			body.WriteString("\n")
+			body.WriteString(line)
+		}
+
+		style := columnStyle
+		if c == m.boardColumn {
+			style = focusedColumnStyle
+		}
+		if column.WIPLimit > 0 && len(indices) > column.WIPLimit {
+			style = style.BorderForeground(theme.Alert)
+		}
+		rendered[c] = style.Render(body.String())
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+// スライス内の値の位置を返す（見つからなければ0）
+func indexOf(values []int, v int) int {
+	for i, x := range values {
+		if x == v {
+			return i
+		}
+	}
+	return 0
+}
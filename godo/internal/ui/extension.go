@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+)
+
+// 一覧に表示する拡張機能の計算項目を問い合わせ直す
+// 問い合わせに失敗した拡張機能の項目は表示せず、エラーをフッターに表示する
+func (m *Model) refreshFields() {
+	if len(m.options.Extensions.Columns()) == 0 {
+		return
+	}
+	values, err := m.options.Extensions.Values(context.Background(), m.taskManager.GetTasks())
+	if err != nil {
+		m.message = fmt.Sprintf("拡張機能の項目を計算できません: %v", err)
+	}
+	m.fields = values
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"context"
+	"godo/pkg/extension"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExtensionColumnsInList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトの拡張機能は Windows では実行できません")
+	}
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	// ID 1 のタスクの文字数を計算項目として返す
+	dir := t.TempDir()
+	script := `#!/bin/sh
+read req
+case "$req" in
+*'"type":"describe"'*) echo '{"manifest":{"fields":[{"name":"chars","title":"文字数","column":true},{"name":"hidden"}]}}' ;;
+*) echo '{"values":{"1":{"chars":"3","hidden":"x"}}}' ;;
+esac
+`
+	os.WriteFile(filepath.Join(dir, "godo-chars"), []byte(script), 0755)
+	set, err := extension.Load(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultOptions()
+	options.Extensions = set
+	m := NewModelWithOptions(options)
+	m = sendKeys(m, "n", "a", "b", "c", "enter")
+
+	view := m.View()
+	if !strings.Contains(view, "文字数: 3") {
+		t.Errorf("view does not show the column:\n%s", view)
+	}
+	// column を指定していない項目は表示しない
+	if strings.Contains(view, "hidden") {
+		t.Errorf("view shows a non-column field:\n%s", view)
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"godo/internal/feed"
+	"godo/internal/hooks"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// サーバーからタスクの変更を受け取ったときのメッセージ
+type changeMsg feed.Change
+
+// サーバーに接続している場合は変更の配信を受け取り始める
+// 他のクライアントがタスクを変更するとすぐに読み込み直す
+func (m *Model) watchChanges() tea.Cmd {
+	if m.remote == nil {
+		return nil
+	}
+	changes := make(chan feed.Change, 16)
+	client := feed.NewClient(m.remote.Client.BaseURL+"/api/events", m.remote.Client.Token)
+	go client.Subscribe(context.Background(), func(c feed.Change) {
+		// 読み込み直すとすべての変更が反映されるので、溜まっている場合は捨てる
+		select {
+		case changes <- c:
+		default:
+		}
+	})
+	m.changes = changes
+	return waitForChange(changes)
+}
+
+// 次の変更を待つコマンド
+func waitForChange(changes <-chan feed.Change) tea.Cmd {
+	return func() tea.Msg {
+		return changeMsg(<-changes)
+	}
+}
+
+// 変更を受け取ったらサーバーから読み込み直す
+// 入力中や編集中はタスクの位置が変わらないように、次の保存のときに読み込み直す
+func (m *Model) handleChange(msg changeMsg) tea.Cmd {
+	if m.mode == normalMode {
+		m.reloadFromServer()
+	}
+	if m.changes == nil {
+		return nil
+	}
+	return waitForChange(m.changes)
+}
+
+// サーバーからタスクを読み込み直す
+func (m *Model) reloadFromServer() {
+	manager, err := m.remote.Load()
+	if err != nil {
+		m.message = fmt.Sprintf("サーバーから読み込めません: %v", err)
+		return
+	}
+	// このセッションの変更履歴は表示できるように残す
+	manager.LoadHistory(m.taskManager.AllHistory())
+	m.taskManager = manager
+	m.saved = hooks.Snapshot(manager.GetTasks())
+	m.refreshFields()
+	if m.cursor >= len(manager.GetTasks()) {
+		m.cursor = max(len(manager.GetTasks())-1, 0)
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"godo/internal/hooks"
+	"godo/internal/storage"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHooksVetoChangesInTUI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトのフックは Windows では実行できません")
+	}
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	dir := filepath.Join(tempHome, "hooks")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, hooks.OnAdd), []byte("#!/bin/sh\ngrep -q '\"title\":\"x\"' && { echo 短すぎます; exit 1; }\nexit 0\n"), 0755)
+	os.WriteFile(filepath.Join(dir, hooks.OnComplete), []byte("#!/bin/sh\necho 完了を記録しました\n"), 0755)
+
+	options := DefaultOptions()
+	options.Hooks = hooks.NewRunner(dir)
+	m := NewModelWithOptions(options)
+	m = sendKeys(m, "n", "a", "b", "c", "enter")
+	m = sendKeys(m, "n", "x", "enter")
+
+	// 取り消された追加は画面からもファイルからも消える
+	if tasks := m.taskManager.GetTasks(); len(tasks) != 1 || tasks[0].Title != "abc" {
+		t.Fatalf("tasks = %v", tasks)
+	}
+	if !strings.Contains(m.message, "短すぎます") {
+		t.Errorf("message = %q", m.message)
+	}
+	stored, _ := storage.NewTaskStorage().LoadTasks()
+	if len(stored) != 1 {
+		t.Fatalf("stored = %v", stored)
+	}
+
+	m = sendKeys(m, "enter")
+	if !m.taskManager.GetTaskByIndex(0).Completed || m.message != "完了を記録しました" {
+		t.Fatalf("completed = %v, message = %q", m.taskManager.GetTaskByIndex(0).Completed, m.message)
+	}
+}
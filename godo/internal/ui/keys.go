@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// KeyMap 通常モードの操作に割り当てるキー
+// キーは bubbletea のキー名（"enter"、"ctrl+d"、"x" など）で指定する
+type KeyMap struct {
+	Toggle   string // 完了状態の切り替え
+	Add      string // タスクの追加
+	Edit     string // タスクの編集
+	Delete   string // タスクの削除
+	Timer    string // タイマーの開始/停止
+	Pomodoro string // ポモドーロの開始/停止
+	Board    string // ボード表示の切り替え
+	Agenda   string // アジェンダ表示の切り替え
+	Archive  string // 完了済みのタスクのアーカイブ
+	History  string // 変更履歴の表示切り替え
+	Up       string // 上のタスクを選択（↑ も使える）
+	Down     string // 下のタスクを選択（↓ も使える）
+	Quit     string // 終了
+}
+
+// DefaultKeyMap デフォルトのキー割り当てを返す
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Toggle:   "enter",
+		Add:      "n",
+		Edit:     "e",
+		Delete:   "d",
+		Timer:    "s",
+		Pomodoro: "p",
+		Board:    "b",
+		Agenda:   "a",
+		Archive:  "A",
+		History:  "i",
+		Up:       "k",
+		Down:     "j",
+		Quit:     "q",
+	}
+}
+
+// KeyActions 設定で使う操作の名前と、その操作のキーの参照
+func (k *KeyMap) KeyActions() []KeyAction {
+	return []KeyAction{
+		{"toggle", &k.Toggle},
+		{"add", &k.Add},
+		{"edit", &k.Edit},
+		{"delete", &k.Delete},
+		{"timer", &k.Timer},
+		{"pomodoro", &k.Pomodoro},
+		{"board", &k.Board},
+		{"agenda", &k.Agenda},
+		{"archive", &k.Archive},
+		{"history", &k.History},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"quit", &k.Quit},
+	}
+}
+
+// KeyAction 操作の名前と割り当てたキー
+type KeyAction struct {
+	Name string
+	Key  *string
+}
+
+// reservedKeys 操作に割り当てられないキー（ボード・アジェンダ表示の移動と Esc に使う）
+var reservedKeys = []string{"up", "down", "left", "right", "h", "l", "H", "L", "t", "esc"}
+
+// KeyError キー割り当ての誤り
+type KeyError struct {
+	Action string // 誤りのある操作の名前
+	Err    error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("keys.%s: %v", e.Action, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// Validate 空のキーや重複したキーがないかを検証する
+func (k KeyMap) Validate() error {
+	used := map[string]string{}
+	for _, action := range k.KeyActions() {
+		key := *action.Key
+		if strings.TrimSpace(key) == "" {
+			return &KeyError{action.Name, errors.New("キーが空です")}
+		}
+		if slices.Contains(reservedKeys, key) {
+			return &KeyError{action.Name, fmt.Errorf("キー %q は移動などに使うので割り当てられません", key)}
+		}
+		if other, ok := used[key]; ok {
+			return &KeyError{action.Name, fmt.Errorf("キー %q は %s にも割り当てられています", key, other)}
+		}
+		used[key] = action.Name
+	}
+	return nil
+}
+
+// normalize 押されたキーを、デフォルトの割り当てで同じ操作をするキーに変換する
+// 通常モードのキー入力はデフォルトのキーで処理するので、割り当てを変えたデフォルトのキーは空文字にして無効にする
+func (k KeyMap) normalize(key string) string {
+	if key == "up" || key == "down" || key == "esc" {
+		return key
+	}
+	defaults := DefaultKeyMap()
+	custom, base := k.KeyActions(), defaults.KeyActions()
+	for i, action := range custom {
+		if *action.Key == key {
+			return *base[i].Key
+		}
+	}
+	for _, action := range base {
+		if *action.Key == key {
+			return ""
+		}
+	}
+	return key
+}
+
+// labels 操作説明に表示する名前にしたキー割り当てを返す
+func (k KeyMap) labels() KeyMap {
+	for _, action := range k.KeyActions() {
+		*action.Key = keyLabel(*action.Key)
+	}
+	return k
+}
+
+// keyLabel 操作説明に表示するキーの名前（"enter" は "Enter" にする）
+func keyLabel(key string) string {
+	if len(key) > 1 {
+		return strings.ToUpper(key[:1]) + key[1:]
+	}
+	return key
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCustomKeyMap(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	options := DefaultOptions()
+	options.Keys.Add = "o"
+	options.Keys.Toggle = "x"
+	options.Keys.Quit = "ctrl+c"
+	m := NewModelWithOptions(options)
+
+	// 割り当てを変えたデフォルトのキーは使えない
+	m = sendKeys(m, "n")
+	if m.mode != normalMode {
+		t.Fatalf("n で入力モードになりました")
+	}
+	m = sendKeys(m, "o", "a", "enter", "x")
+	tasks := m.taskManager.GetTasks()
+	if len(tasks) != 1 || !tasks[0].Completed {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	if _, cmd := m.Update(key("q")); cmd != nil {
+		t.Errorf("q で終了しました")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Errorf("ctrl+c で終了しません")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("ctrl+c で終了しません")
+	}
+	if footer := m.View(); !strings.Contains(footer, "x=完了切替 | o=追加") || !strings.Contains(footer, "Ctrl+c=終了") {
+		t.Errorf("操作説明が割り当てと違います:\n%s", footer)
+	}
+}
+
+func TestKeyMapValidate(t *testing.T) {
+	if err := DefaultKeyMap().Validate(); err != nil {
+		t.Fatalf("デフォルトの割り当てが不正です: %v", err)
+	}
+	for _, tt := range []struct {
+		change func(*KeyMap)
+		action string
+	}{
+		{func(k *KeyMap) { k.Edit = "" }, "edit"},
+		{func(k *KeyMap) { k.Quit = "n" }, "quit"},
+		{func(k *KeyMap) { k.Board = "h" }, "board"},
+	} {
+		keys := DefaultKeyMap()
+		tt.change(&keys)
+		var keyErr *KeyError
+		if err := keys.Validate(); !errors.As(err, &keyErr) || keyErr.Action != tt.action {
+			t.Errorf("Validate() = %v, want error for %s", err, tt.action)
+		}
+	}
+}
+
+func TestDateFormatAndTheme(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	options := DefaultOptions()
+	options.DateFormat = "01/02"
+	options.Theme = Themes["mono"]
+	options.DataDir = filepath.Join(tempHome, "data")
+	m := NewModelWithOptions(options)
+	m = sendKeys(m, "n", "a", "enter")
+
+	if want := "作成: " + time.Now().Format("01/02") + " |"; !strings.Contains(m.View(), want) {
+		t.Errorf("View に %q がありません:\n%s", want, m.View())
+	}
+	if _, err := os.Stat(filepath.Join(tempHome, "data", "tasks.json")); err != nil {
+		t.Errorf("DataDir に保存されていません: %v", err)
+	}
+	if _, err := LookupTheme("unknown"); err == nil {
+		t.Errorf("存在しないテーマがエラーになりません")
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PomodoroSettings ポモドーロタイマーの設定
+type PomodoroSettings struct {
+	Work           time.Duration // 作業時間
+	ShortBreak     time.Duration // 短い休憩
+	LongBreak      time.Duration // 長い休憩
+	LongBreakEvery int           // 何回の作業ごとに長い休憩を取るか（0以下なら長い休憩なし）
+}
+
+// DefaultPomodoroSettings 25分作業/5分休憩、4回ごとに15分休憩の設定を返す
+func DefaultPomodoroSettings() PomodoroSettings {
+	return PomodoroSettings{
+		Work:           25 * time.Minute,
+		ShortBreak:     5 * time.Minute,
+		LongBreak:      15 * time.Minute,
+		LongBreakEvery: 4,
+	}
+}
+
+// Validate 設定値が正しいかを検証する
+func (s PomodoroSettings) Validate() error {
+	if s.Work <= 0 {
+		return errors.New("ポモドーロの作業時間は0より大きくしてください")
+	}
+	if s.ShortBreak <= 0 || s.LongBreak <= 0 {
+		return errors.New("ポモドーロの休憩時間は0より大きくしてください")
+	}
+	return nil
+}
+
+// ポモドーロのフェーズ
+type pomodoroPhase int
+
+const (
+	pomodoroWork pomodoroPhase = iota
+	pomodoroShortBreak
+	pomodoroLongBreak
+)
+
+// フェーズの表示名
+func (p pomodoroPhase) String() string {
+	switch p {
+	case pomodoroShortBreak:
+		return "休憩"
+	case pomodoroLongBreak:
+		return "長い休憩"
+	default:
+		return "作業"
+	}
+}
+
+// タスクに紐づいた実行中のポモドーロ
+type pomodoro struct {
+	taskID   int
+	settings PomodoroSettings
+	phase    pomodoroPhase
+	startAt  time.Time
+	endsAt   time.Time
+	cycles   int // このセッションで完了した作業の回数
+}
+
+// 作業フェーズから始まるポモドーロを作成する
+func newPomodoro(taskID int, settings PomodoroSettings, now time.Time) *pomodoro {
+	p := &pomodoro{taskID: taskID, settings: settings}
+	p.begin(pomodoroWork, now)
+	return p
+}
+
+// フェーズを開始する
+func (p *pomodoro) begin(phase pomodoroPhase, now time.Time) {
+	p.phase = phase
+	p.startAt = now
+	p.endsAt = now.Add(p.length(phase))
+}
+
+// フェーズの長さを返す
+func (p *pomodoro) length(phase pomodoroPhase) time.Duration {
+	switch phase {
+	case pomodoroShortBreak:
+		return p.settings.ShortBreak
+	case pomodoroLongBreak:
+		return p.settings.LongBreak
+	default:
+		return p.settings.Work
+	}
+}
+
+// 現在のフェーズの残り時間を返す
+func (p *pomodoro) remaining(now time.Time) time.Duration {
+	if now.After(p.endsAt) {
+		return 0
+	}
+	return p.endsAt.Sub(now)
+}
+
+// 現在のフェーズの進捗（0.0〜1.0）を返す
+func (p *pomodoro) progress(now time.Time) float64 {
+	total := p.endsAt.Sub(p.startAt)
+	if total <= 0 {
+		return 1
+	}
+	done := float64(total-p.remaining(now)) / float64(total)
+	if done > 1 {
+		return 1
+	}
+	return done
+}
+
+// advance 現在のフェーズが終了していれば次のフェーズに進める
+// 終了したフェーズと、フェーズが終了したかどうかを返す
+func (p *pomodoro) advance(now time.Time) (pomodoroPhase, bool) {
+	if now.Before(p.endsAt) {
+		return p.phase, false
+	}
+
+	finished := p.phase
+	if finished == pomodoroWork {
+		p.cycles++
+		if p.settings.LongBreakEvery > 0 && p.cycles%p.settings.LongBreakEvery == 0 {
+			p.begin(pomodoroLongBreak, now)
+		} else {
+			p.begin(pomodoroShortBreak, now)
+		}
+	} else {
+		p.begin(pomodoroWork, now)
+	}
+	return finished, true
+}
+
+// ベルとOSC 9でフェーズの終了をターミナルに通知するコマンド
+// 描画と同じ出力に書き込むよう、画面の上にメッセージを表示する行として tea.Println で出力する
+func notify(message string) tea.Cmd {
+	return tea.Println(notification(message))
+}
+
+// 通知するメッセージの行（ベルとOSC 9のデスクトップ通知を含む）
+func notification(message string) string {
+	return fmt.Sprintf("🍅 %s\a\x1b]9;%s\x07", message, message)
+}
+
+// 残り時間を "04:59" の形式に変換する
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
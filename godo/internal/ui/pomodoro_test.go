@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPomodoro_cyclesThroughBreaks(t *testing.T) {
+	settings := PomodoroSettings{
+		Work:           25 * time.Minute,
+		ShortBreak:     5 * time.Minute,
+		LongBreak:      15 * time.Minute,
+		LongBreakEvery: 2,
+	}
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	p := newPomodoro(1, settings, now)
+
+	if _, ok := p.advance(now.Add(10 * time.Minute)); ok {
+		t.Fatalf("work phase should not finish before 25 minutes")
+	}
+	if got := p.remaining(now.Add(10 * time.Minute)); got != 15*time.Minute {
+		t.Fatalf("expected 15m remaining, got %v", got)
+	}
+
+	// 作業 -> 短い休憩 -> 作業 -> 長い休憩
+	expected := []struct {
+		finished pomodoroPhase
+		next     pomodoroPhase
+	}{
+		{pomodoroWork, pomodoroShortBreak},
+		{pomodoroShortBreak, pomodoroWork},
+		{pomodoroWork, pomodoroLongBreak},
+		{pomodoroLongBreak, pomodoroWork},
+	}
+	for i, e := range expected {
+		now = p.endsAt
+		finished, ok := p.advance(now)
+		if !ok || finished != e.finished || p.phase != e.next {
+			t.Fatalf("step %d: got finished=%v ok=%v next=%v", i, finished, ok, p.phase)
+		}
+	}
+	if p.cycles != 2 {
+		t.Fatalf("expected 2 completed cycles, got %d", p.cycles)
+	}
+}
+
+func TestPomodoroSettings_Validate(t *testing.T) {
+	if err := DefaultPomodoroSettings().Validate(); err != nil {
+		t.Fatalf("default settings should be valid: %v", err)
+	}
+	s := DefaultPomodoroSettings()
+	s.Work = 0
+	if err := s.Validate(); err == nil {
+		t.Fatalf("zero work duration should be invalid")
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	if got := formatCountdown(4*time.Minute + 59*time.Second); got != "04:59" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"godo/internal/api"
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemoteModeSavesThroughServer(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("USERPROFILE", tempHome)
+
+	ts := storage.NewTaskStorage()
+	srv := httptest.NewServer(api.NewServer(api.NewAccess(api.NewStorageStore(ts, models.DefaultWorkflow())), "token"))
+	defer srv.Close()
+
+	options := DefaultOptions()
+	options.Remote = api.NewRemote(srv.URL, "token", options.Workflow)
+	m := NewModelWithOptions(options)
+	m = sendKeys(m, "n", "a", "b", "c", "enter", "enter")
+
+	tasks, err := ts.LoadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "abc" || !tasks[0].Completed {
+		t.Fatalf("サーバーに保存されていません: %+v", tasks)
+	}
+
+	// 他のクライアントの変更は次の保存で読み込み直される
+	other := api.NewRemote(srv.URL, "token", options.Workflow)
+	tm, err := other.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.AddTask("他のクライアント")
+	if err := other.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	m = sendKeys(m, "enter")
+	if got := m.taskManager.GetTasks(); len(got) != 2 || got[0].Completed || got[1].Title != "他のクライアント" {
+		t.Fatalf("tasks = %+v", got)
+	}
+
+	// 変更の配信を受け取ると、キー入力がなくても読み込み直す
+	tm.AddTask("配信された変更")
+	if err := other.Save(tm); err != nil {
+		t.Fatal(err)
+	}
+	m.Update(changeMsg{})
+	if got := m.taskManager.GetTasks(); len(got) != 3 || got[2].Title != "配信された変更" {
+		t.Fatalf("tasks = %+v", got)
+	}
+
+	m = sendKeys(m, "A")
+	if m.message == "" {
+		t.Error("サーバーに接続している間のアーカイブはメッセージで知らせる")
+	}
+}
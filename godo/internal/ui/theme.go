@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme TUIの配色
+type Theme struct {
+	Accent    lipgloss.TerminalColor // ヘッダー・選択中の列・今日
+	Done      lipgloss.TerminalColor // 完了したタスク
+	Pending   lipgloss.TerminalColor // 未完了のタスク・期限のある日
+	Alert     lipgloss.TerminalColor // 期限切れ・WIP超過・ポモドーロ
+	Timer     lipgloss.TerminalColor // 計測中のタイマー
+	Muted     lipgloss.TerminalColor // 日時・曜日
+	Footer    lipgloss.TerminalColor // 操作説明
+	Border    lipgloss.TerminalColor // 枠線
+	Selection lipgloss.TerminalColor // 選択中の行の背景（NoColor なら反転表示）
+}
+
+// Themes 名前で選べる配色
+var Themes = map[string]Theme{
+	"default": {
+		Accent:    lipgloss.Color("205"),
+		Done:      lipgloss.Color("2"),
+		Pending:   lipgloss.Color("208"),
+		Alert:     lipgloss.Color("196"),
+		Timer:     lipgloss.Color("39"),
+		Muted:     lipgloss.Color("243"),
+		Footer:    lipgloss.Color("241"),
+		Border:    lipgloss.Color("240"),
+		Selection: lipgloss.Color("240"),
+	},
+	// 明るい背景の端末向け
+	"light": {
+		Accent:    lipgloss.Color("162"),
+		Done:      lipgloss.Color("28"),
+		Pending:   lipgloss.Color("166"),
+		Alert:     lipgloss.Color("160"),
+		Timer:     lipgloss.Color("25"),
+		Muted:     lipgloss.Color("242"),
+		Footer:    lipgloss.Color("244"),
+		Border:    lipgloss.Color("250"),
+		Selection: lipgloss.Color("253"),
+	},
+	// 色を使わない
+	"mono": {
+		Accent:    lipgloss.NoColor{},
+		Done:      lipgloss.NoColor{},
+		Pending:   lipgloss.NoColor{},
+		Alert:     lipgloss.NoColor{},
+		Timer:     lipgloss.NoColor{},
+		Muted:     lipgloss.NoColor{},
+		Footer:    lipgloss.NoColor{},
+		Border:    lipgloss.NoColor{},
+		Selection: lipgloss.NoColor{},
+	},
+}
+
+// DefaultTheme デフォルトの配色を返す
+func DefaultTheme() Theme {
+	return Themes["default"]
+}
+
+// ThemeNames 選べる配色の名前を返す
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme 名前から配色を返す
+func LookupTheme(name string) (Theme, error) {
+	theme, ok := Themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("テーマが不正です: %q (%s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// selected 選択中の行のスタイル
+func (t Theme) selected() lipgloss.Style {
+	if _, ok := t.Selection.(lipgloss.NoColor); ok || t.Selection == nil {
+		return lipgloss.NewStyle().Reverse(true)
+	}
+	return lipgloss.NewStyle().Background(t.Selection)
+}
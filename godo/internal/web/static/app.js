@@ -0,0 +1,45 @@
+// 削除の確認、n キーでの入力、他のクライアントの変更での再読み込みだけを行う
+document.addEventListener("DOMContentLoaded", () => {
+  for (const form of document.querySelectorAll("form[data-confirm]")) {
+    form.addEventListener("submit", (e) => {
+      if (!confirm(form.dataset.confirm)) {
+        e.preventDefault();
+      }
+    });
+  }
+
+  const editing = () =>
+    document.querySelector("details.edit[open]") !== null ||
+    ["INPUT", "SELECT", "TEXTAREA"].includes(document.activeElement?.tagName);
+
+  document.addEventListener("keydown", (e) => {
+    if (e.key === "n" && !editing() && !e.ctrlKey && !e.metaKey && !e.altKey) {
+      e.preventDefault();
+      document.getElementById("new-title")?.focus();
+    }
+  });
+
+  // 入力中や編集中は再読み込みせず、終わってから反映する
+  const url = document.body.dataset.events;
+  if (url && window.EventSource) {
+    let stale = false;
+    const reload = () => {
+      if (editing()) {
+        stale = true;
+      } else {
+        location.reload();
+      }
+    };
+    const source = new EventSource(url);
+    for (const type of ["created", "updated", "deleted"]) {
+      source.addEventListener(type, reload);
+    }
+    document.addEventListener("focusout", () => {
+      setTimeout(() => {
+        if (stale && !editing()) {
+          location.reload();
+        }
+      }, 0);
+    });
+  }
+});
@@ -0,0 +1,341 @@
+// Package web はブラウザからタスクを操作する Web 画面を提供する
+//
+// HTML のテンプレートと少しの JavaScript は embed.FS でバイナリに埋め込む。
+// 画面の操作はフォームの送信で行い、TUI と同じく TaskManager を通してタスクを変更して保存する。
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/report"
+	"godo/pkg/models"
+	"html/template"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed templates static
+var assets embed.FS
+
+// 日時の入力欄（input type="datetime-local"）の形式
+const inputTimeLayout = "2006-01-02T15:04"
+
+// Server Web 画面のサーバー
+type Server struct {
+	tasks *api.Access
+	csrf  string // フォームに埋め込み、他のサイトからの送信を拒否するためのトークン
+	mux   *http.ServeMux
+	page  *template.Template
+	feed  bool   // /events で変更を配信しているか
+	port  string // 待ち受けるポート（Host ヘッダーの確認に使う）
+}
+
+// NewServer tasks のタスクを読み書きする Web 画面のサーバーを作成する
+// events を指定すると /events で変更の配信を提供し、画面は他のクライアントの変更で再読み込みされる
+// addr は待ち受けるアドレスで、Host ヘッダーがループバックのアドレスとこのポートでないリクエストは拒否する
+func NewServer(tasks *api.Access, events http.Handler, addr string) (*Server, error) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("待ち受けるアドレスが不正です: %w", err)
+	}
+	page, err := template.New("index.html").Funcs(template.FuncMap{
+		"formatDue": report.FormatDue,
+		"inputTime": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.Format(inputTimeLayout)
+		},
+		"join":   strings.Join,
+		"etag":   api.ETag,
+		"status": func(tm *models.TaskManager, task *models.Task) string { return tm.StatusOf(task) },
+	}).ParseFS(assets, "templates/index.html")
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{tasks: tasks, csrf: hex.EncodeToString(b), mux: http.NewServeMux(), page: page, feed: events != nil, port: port}
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	s.mux.HandleFunc("POST /tasks", s.handleAdd)
+	s.mux.HandleFunc("POST /tasks/{id}/edit", s.handleEdit)
+	s.mux.HandleFunc("POST /tasks/{id}/toggle", s.handleToggle)
+	s.mux.HandleFunc("POST /tasks/{id}/delete", s.handleDelete)
+	if events != nil {
+		s.mux.Handle("GET /events", events)
+	}
+	return s, nil
+}
+
+// ServeHTTP Host と Origin を確認してからリクエストを処理する
+// 認証がないので、DNS リバインディングで他のサイトのページから読み書きされないように、
+// このコンピューターのアドレス（localhost や 127.0.0.1）と待ち受けるポート宛てのリクエストだけを受け付ける
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, fmt.Sprintf("Host が不正です: %q", r.Host), http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); r.Method == http.MethodPost && origin != "" && !s.allowedOrigin(origin) {
+		http.Error(w, fmt.Sprintf("他のサイトからの送信は受け付けません: %q", origin), http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowedHost Host ヘッダーがループバックのアドレスと待ち受けるポートかを返す
+// ポートに 0 を指定した場合（割り当てられたポートがわからない場合）はポートを確認しない
+func (s *Server) allowedHost(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil || (s.port != "0" && port != s.port) {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowedOrigin フォームを送信したページがこのサーバーのものかを返す
+func (s *Server) allowedOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Scheme == "http" && s.allowedHost(u.Host)
+}
+
+// pageData 一覧画面に渡す値
+type pageData struct {
+	Manager   *models.TaskManager
+	Tasks     []*models.Task
+	Completed int
+	Total     int
+	Filter    url.Values
+	Query     string // 操作の後に同じ絞り込みの一覧に戻るためのクエリ
+	Columns   []models.Column
+	Message   string
+	Error     string
+	CSRF      string
+	Events    bool
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data := pageData{Filter: query, Message: query.Get("message"), Error: query.Get("error"), CSRF: s.csrf}
+	filter, err := api.ParseFilter(query)
+	if err != nil {
+		data.Error = err.Error()
+	}
+	var tm *models.TaskManager
+	err = s.tasks.View(func(loaded *models.TaskManager) error {
+		tm = loaded
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data.Manager = tm
+	data.Completed, data.Total = tm.GetStats()
+	data.Columns = tm.Workflow().Columns
+	for _, task := range tm.GetTasks() {
+		if filter.Match(tm.Workflow(), task) {
+			data.Tasks = append(data.Tasks, task)
+		}
+	}
+	data.Query = filterQuery(query).Encode()
+	data.Events = s.feed
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.page.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	if !s.checkForm(w, r) {
+		return
+	}
+	title := strings.TrimSpace(r.PostFormValue("title"))
+	if title == "" {
+		s.redirect(w, r, "", "タイトルを入力してください")
+		return
+	}
+	task := &models.Task{Title: title}
+	if err := readFields(r, task); err != nil {
+		s.redirect(w, r, "", err.Error())
+		return
+	}
+	// 追加したタスクは最初の列から始める
+	task.Status = ""
+
+	err := s.tasks.Update(func(tm *models.TaskManager) error {
+		tm.ImportTask(task)
+		return nil
+	})
+	s.done(w, r, err, fmt.Sprintf("'%s' を追加しました", task.Title))
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if !s.checkForm(w, r) {
+		return
+	}
+	title := strings.TrimSpace(r.PostFormValue("title"))
+	if title == "" {
+		s.redirect(w, r, "", "タイトルを空にはできません")
+		return
+	}
+	var from models.Task
+	if err := readFields(r, &from); err != nil {
+		s.redirect(w, r, "", err.Error())
+		return
+	}
+
+	err := s.modify(r, func(tm *models.TaskManager, index int) {
+		tm.UpdateTask(index, title)
+		if from.Status != "" && tm.StatusOf(tm.GetTaskByIndex(index)) != from.Status {
+			tm.SetStatus(index, from.Status)
+		}
+		tm.SetPriority(index, from.Priority)
+		tm.SetProject(index, from.Project)
+		tm.SetTags(index, from.Tags)
+		tm.SetDue(index, from.DueAt)
+	})
+	s.done(w, r, err, fmt.Sprintf("'%s' を更新しました", title))
+}
+
+func (s *Server) handleToggle(w http.ResponseWriter, r *http.Request) {
+	if !s.checkForm(w, r) {
+		return
+	}
+	err := s.modify(r, func(tm *models.TaskManager, index int) {
+		tm.ToggleTask(index)
+	})
+	s.done(w, r, err, "")
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.checkForm(w, r) {
+		return
+	}
+	var title string
+	err := s.modify(r, func(tm *models.TaskManager, index int) {
+		title = tm.GetTaskByIndex(index).Title
+		tm.DeleteTask(index)
+	})
+	s.done(w, r, err, fmt.Sprintf("'%s' を削除しました", title))
+}
+
+// checkForm フォームを読み込み、CSRF トークンを確認する
+func (s *Server) checkForm(w http.ResponseWriter, r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf")), []byte(s.csrf)) != 1 {
+		http.Error(w, "フォームの有効期限が切れています。ページを読み込み直してください", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// modify パスの ID のタスクを fn で変更して保存する
+// フォームに ETag があれば、画面を表示してから他で変更されていないかを確認する
+func (s *Server) modify(r *http.Request, fn func(tm *models.TaskManager, index int)) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return fmt.Errorf("ID %s の%w", r.PathValue("id"), api.ErrNotFound)
+	}
+	return s.tasks.Update(func(tm *models.TaskManager) error {
+		index, err := api.Find(tm, id, r.PostFormValue("etag"))
+		if errors.Is(err, api.ErrPreconditionFailed) {
+			return fmt.Errorf("ID %d のタスクは他で変更されています。内容を確認してからもう一度操作してください", id)
+		}
+		if err != nil {
+			return err
+		}
+		fn(tm, index)
+		return nil
+	})
+}
+
+// done 操作の結果を表示するために一覧に戻る
+func (s *Server) done(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if err != nil {
+		s.redirect(w, r, "", err.Error())
+		return
+	}
+	s.redirect(w, r, message, "")
+}
+
+// redirect 操作の後に、元の絞り込みのまま一覧に戻る
+func (s *Server) redirect(w http.ResponseWriter, r *http.Request, message, errMessage string) {
+	query, _ := url.ParseQuery(r.PostFormValue("return"))
+	query = filterQuery(query)
+	if message != "" {
+		query.Set("message", message)
+	}
+	if errMessage != "" {
+		query.Set("error", errMessage)
+	}
+	target := "/"
+	if q := query.Encode(); q != "" {
+		target += "?" + q
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// filterQuery クエリから絞り込みの条件だけを取り出す
+func filterQuery(query url.Values) url.Values {
+	filtered := url.Values{}
+	for _, key := range []string{"completed", "project", "priority", "status", "tag", "q", "due_before"} {
+		if v := query.Get(key); v != "" {
+			filtered.Set(key, v)
+		}
+	}
+	return filtered
+}
+
+// readFields フォームのタイトル以外の項目をタスクに読み込む
+func readFields(r *http.Request, task *models.Task) error {
+	task.Project = strings.TrimSpace(r.PostFormValue("project"))
+	task.Status = r.PostFormValue("status")
+	task.Priority = strings.ToUpper(strings.TrimSpace(r.PostFormValue("priority")))
+	if err := models.ValidatePriority(task.Priority); err != nil {
+		return err
+	}
+	task.Tags = nil
+	for _, tag := range strings.Split(r.PostFormValue("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	if due := strings.TrimSpace(r.PostFormValue("due")); due != "" {
+		t, err := time.ParseInLocation(inputTimeLayout, due, time.Local)
+		if err != nil {
+			t, err = time.ParseInLocation("2006-01-02", due, time.Local)
+		}
+		if err != nil {
+			return fmt.Errorf("期限の形式が不正です: %q", due)
+		}
+		task.DueAt = &t
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package web
+
+import (
+	"godo/internal/api"
+	"godo/internal/api/apitest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, titles ...string) (*apitest.Store, *Server) {
+	t.Helper()
+	store := apitest.NewStore(titles...)
+	s, err := NewServer(api.NewAccess(store), nil, testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, s
+}
+
+// testAddr テストのサーバーが待ち受けるアドレス
+const testAddr = "127.0.0.1:8090"
+
+func get(t *testing.T, s *Server, target string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Host = testAddr
+	s.ServeHTTP(rec, req)
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+// post フォームを送信し、リダイレクト先を返す
+func post(t *testing.T, s *Server, target string, form url.Values) (int, string) {
+	t.Helper()
+	if !form.Has("csrf") {
+		form.Set("csrf", s.csrf)
+	}
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Host = testAddr
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec.Code, rec.Header().Get("Location")
+}
+
+func TestIndexListsAndFilters(t *testing.T) {
+	store, s := newTestServer(t, "レポートを書く", "牛乳を買う")
+	tm, _ := store.Load()
+	tm.SetProject(0, "work")
+	tm.ToggleTask(1)
+	store.Save(tm)
+
+	code, body := get(t, s, "/")
+	if code != http.StatusOK || !strings.Contains(body, "レポートを書く") || !strings.Contains(body, "牛乳を買う") || !strings.Contains(body, "完了 1 / 全体 2") {
+		t.Fatalf("code = %d, body = %s", code, body)
+	}
+
+	_, body = get(t, s, "/?completed=false")
+	if !strings.Contains(body, "レポートを書く") || strings.Contains(body, "牛乳を買う") {
+		t.Errorf("completed=false: %s", body)
+	}
+	_, body = get(t, s, "/?project=home")
+	if !strings.Contains(body, "該当するタスクはありません") {
+		t.Errorf("project=home: %s", body)
+	}
+
+	if code, body := get(t, s, "/static/app.js"); code != http.StatusOK || !strings.Contains(body, "EventSource") {
+		t.Errorf("static: %d", code)
+	}
+	if code, _ := get(t, s, "/unknown"); code != http.StatusNotFound {
+		t.Errorf("unknown path: %d", code)
+	}
+}
+
+func TestAddEditToggleDelete(t *testing.T) {
+	store, s := newTestServer(t)
+
+	code, location := post(t, s, "/tasks", url.Values{"title": {"新しいタスク"}, "priority": {"b"}, "due": {"2025-03-01T09:00"}, "return": {"completed=false"}})
+	if code != http.StatusSeeOther || !strings.HasPrefix(location, "/?") || !strings.Contains(location, "completed=false") {
+		t.Fatalf("add: %d %s", code, location)
+	}
+	tasks := store.Tasks(t)
+	if len(tasks) != 1 || tasks[0].Priority != "B" || tasks[0].DueAt == nil || tasks[0].DueAt.Hour() != 9 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+
+	post(t, s, "/tasks/1/edit", url.Values{
+		"title": {"変更後"}, "project": {"home"}, "tags": {"a, b"}, "status": {"doing"}, "etag": {api.ETag(tasks[0])},
+	})
+	task := store.Tasks(t)[0]
+	if task.Title != "変更後" || task.Project != "home" || strings.Join(task.Tags, ",") != "a,b" || task.Status != "doing" || task.DueAt != nil || task.Priority != "" {
+		t.Fatalf("edit: %+v", task)
+	}
+
+	post(t, s, "/tasks/1/toggle", url.Values{})
+	if task := store.Tasks(t)[0]; !task.Completed {
+		t.Fatalf("toggle: %+v", task)
+	}
+
+	post(t, s, "/tasks/1/delete", url.Values{})
+	if tasks := store.Tasks(t); len(tasks) != 0 {
+		t.Fatalf("delete: %+v", tasks)
+	}
+}
+
+func TestFormErrors(t *testing.T) {
+	store, s := newTestServer(t, "a")
+
+	// CSRF トークンのない送信は受け付けない
+	if code, _ := post(t, s, "/tasks", url.Values{"title": {"x"}, "csrf": {"wrong"}}); code != http.StatusForbidden {
+		t.Errorf("csrf: %d", code)
+	}
+
+	_, location := post(t, s, "/tasks", url.Values{"title": {" "}})
+	if !strings.Contains(location, "error=") {
+		t.Errorf("empty title: %s", location)
+	}
+	_, location = post(t, s, "/tasks/1/edit", url.Values{"title": {"x"}, "priority": {"AA"}})
+	if !strings.Contains(location, "error=") {
+		t.Errorf("invalid priority: %s", location)
+	}
+
+	// 画面を表示した後に他で変更されていたら上書きしない
+	old := api.ETag(store.Tasks(t)[0])
+	tm, _ := store.Load()
+	tm.UpdateTask(0, "他で変更")
+	store.Save(tm)
+	_, location = post(t, s, "/tasks/1/edit", url.Values{"title": {"古い画面からの変更"}, "etag": {old}})
+	if !strings.Contains(location, "error=") || store.Tasks(t)[0].Title != "他で変更" {
+		t.Errorf("stale etag: %s, %+v", location, store.Tasks(t)[0])
+	}
+
+	_, location = post(t, s, "/tasks/99/toggle", url.Values{})
+	if !strings.Contains(location, "error=") {
+		t.Errorf("missing task: %s", location)
+	}
+}
+
+func TestRejectsForeignHostAndOrigin(t *testing.T) {
+	store, s := newTestServer(t, "牛乳を買う")
+	events := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "data: tasks") })
+	s, err := NewServer(api.NewAccess(store), events, testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DNS リバインディングでは Host が攻撃者のドメインになる
+	for _, host := range []string{"evil.example:8090", "evil.example", "127.0.0.1:9999", "192.168.0.10:8090"} {
+		for _, target := range []string{"/", "/events"} {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Host = host
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "牛乳") || strings.Contains(rec.Body.String(), s.csrf) {
+				t.Errorf("GET %s (Host %s) = %d", target, host, rec.Code)
+			}
+		}
+	}
+	for _, host := range []string{"localhost:8090", "127.0.0.1:8090", "[::1]:8090"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET / (Host %s) = %d", host, rec.Code)
+		}
+	}
+
+	// 他のサイトのページからの送信は CSRF トークンがあっても拒否する
+	for origin, want := range map[string]int{"http://evil.example:8090": http.StatusForbidden, "null": http.StatusForbidden, "http://localhost:8090": http.StatusSeeOther} {
+		form := url.Values{"csrf": {s.csrf}}
+		req := httptest.NewRequest(http.MethodPost, "/tasks/1/toggle", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", origin)
+		req.Host = testAddr
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("POST from %s = %d, want %d", origin, rec.Code, want)
+		}
+	}
+	if tasks := store.Tasks(t); !tasks[0].Completed {
+		t.Errorf("同じサイトからの送信が反映されていません: %+v", tasks[0])
+	}
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/internal/feed"
+	"godo/internal/filelock"
+	"godo/pkg/models"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Delivery 送信待ちの変更1件
+type Delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       string          `json:"event"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts,omitempty"`
+	NextAttempt time.Time       `json:"next_attempt,omitempty"` // この時刻になるまで送り直さない
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Queue 送信待ちの変更と、変更履歴をどこまでキューに積んだか
+type Queue struct {
+	Cursor     int64       `json:"cursor"` // キューに積んだ最後の変更のイベントID
+	Deliveries []*Delivery `json:"deliveries"`
+}
+
+// LoadQueue キューを読み込む（ファイルがなければ nil を返す）
+func LoadQueue(path string) (*Queue, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Webhook の送信待ちのキューを読み込めません: %w", err)
+	}
+	var q Queue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("Webhook の送信待ちのキューが壊れています: %w", err)
+	}
+	return &q, nil
+}
+
+// Save キューを保存する。途中で止まってもキューが壊れないように一時ファイルから置き換える
+func (q *Queue) Save(path string) error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Webhook の送信待ちのキューを保存できません: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("Webhook の送信待ちのキューを保存できません: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("Webhook の送信待ちのキューを保存できません: %w", err)
+	}
+	return nil
+}
+
+// Pending URL ごとの送信待ちの件数を返す
+func (q *Queue) Pending() map[string]int {
+	counts := map[string]int{}
+	if q == nil {
+		return counts
+	}
+	for _, d := range q.Deliveries {
+		counts[d.URL]++
+	}
+	return counts
+}
+
+// ファイル名（タスクと同じディレクトリに置く）
+const (
+	ConfigFileName = "webhooks.json"
+	QueueFileName  = "webhooks-queue.json"
+	lockFileName   = "webhooks.lock"
+)
+
+// Dispatcher 変更履歴の変更をキューに積み、Webhook に送る
+type Dispatcher struct {
+	ConfigPath string
+	QueuePath  string
+	History    *feed.History
+	Current    func() ([]*models.Task, error) // 変更されたタスクの現在の内容を読み込む（nilなら履歴の内容だけを送る）
+	HTTP       *http.Client
+	Backoff    time.Duration // 最初に送り直すまでの間隔（失敗するたびに倍にする）
+	MaxBackoff time.Duration
+	Force      bool // 送り直しの時刻を待たずに送る
+	Now        func() time.Time
+}
+
+// NewDispatcher dir（~/.godo）の設定とキューを使う Dispatcher を作成する
+func NewDispatcher(dir string, history *feed.History, current func() ([]*models.Task, error)) *Dispatcher {
+	return &Dispatcher{
+		ConfigPath: filepath.Join(dir, ConfigFileName),
+		QueuePath:  filepath.Join(dir, QueueFileName),
+		History:    history,
+		Current:    current,
+		HTTP:       &http.Client{Timeout: 10 * time.Second},
+		Backoff:    30 * time.Second,
+		MaxBackoff: time.Hour,
+		Now:        time.Now,
+	}
+}
+
+// Reset 変更履歴の最後の変更までを送ったことにする
+// Webhook を初めて設定したときに、それまでの変更を送らないようにするために使う
+func (d *Dispatcher) Reset() error {
+	return d.locked(func(config *Config, q *Queue) error {
+		latest, err := d.History.Latest()
+		if err != nil {
+			return err
+		}
+		q.Cursor = latest
+		return nil
+	})
+}
+
+// Flush 新しい変更をキューに積み、送信する時刻になった変更を送る
+// 送信に失敗した変更はキューに残し、最初の失敗を返す。Webhook が設定されていなければ何もしない
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	return d.locked(func(config *Config, q *Queue) error {
+		if err := d.enqueue(config, q); err != nil {
+			return err
+		}
+		return d.deliver(ctx, config, q)
+	})
+}
+
+// Run ctx がキャンセルされるまで interval ごとに Flush する
+// 送信の失敗はキューに残して送り直すので、ここでは無視する
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.Flush(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// locked 他のプロセスと同時に送らないようにロックを取り、設定とキューを読み込んで fn を呼ぶ
+// fn の後でキューを保存する。他のプロセスが送信中の場合は何もしない
+func (d *Dispatcher) locked(fn func(config *Config, q *Queue) error) error {
+	config, err := LoadConfig(d.ConfigPath)
+	if err != nil || len(config.Webhooks) == 0 {
+		return err
+	}
+	// OS のファイルロックなので、送信に時間がかかっても、送信中のプロセスが異常終了しても正しく扱える
+	lock, err := filelock.TryLock(filepath.Join(filepath.Dir(d.QueuePath), lockFileName))
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	q, err := LoadQueue(d.QueuePath)
+	if err != nil {
+		return err
+	}
+	if q == nil {
+		// キューがまだなければ、これまでの変更は送らない
+		latest, err := d.History.Latest()
+		if err != nil {
+			return err
+		}
+		q = &Queue{Cursor: latest}
+	}
+	fnErr := fn(config, q)
+	if err := q.Save(d.QueuePath); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// enqueue 前回より後の変更を、購読している Webhook ごとにキューに積む
+func (d *Dispatcher) enqueue(config *Config, q *Queue) error {
+	changes, last, err := d.History.Changes(q.Cursor, d.Current)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		payload := Payload{
+			ID:     fmt.Sprintf("%d", c.ID),
+			Event:  eventOf(c),
+			Time:   c.Event.Time,
+			TaskID: c.TaskID,
+			Task:   c.Task,
+		}
+		if c.Event.Field != "" {
+			payload.Change = &Change{Field: c.Event.Field, Old: c.Event.Old, New: c.Event.New}
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		for _, w := range config.Webhooks {
+			if w.Wants(payload.Event) {
+				q.Deliveries = append(q.Deliveries, &Delivery{ID: payload.ID, URL: w.URL, Event: payload.Event, Body: body})
+			}
+		}
+	}
+	q.Cursor = last
+	return nil
+}
+
+// deliver 送信する時刻になった変更を送る
+// 同じ URL への変更は順番に送るため、失敗した URL への後の変更はこの回では送らない
+func (d *Dispatcher) deliver(ctx context.Context, config *Config, q *Queue) error {
+	now := d.Now()
+	failed := map[string]bool{}
+	var firstErr error
+	var remaining []*Delivery
+	for _, delivery := range q.Deliveries {
+		w := config.Find(delivery.URL)
+		if w == nil {
+			// 設定から削除した Webhook への変更は捨てる
+			continue
+		}
+		if failed[delivery.URL] || (!d.Force && delivery.NextAttempt.After(now)) || ctx.Err() != nil {
+			failed[delivery.URL] = true
+			remaining = append(remaining, delivery)
+			continue
+		}
+		if err := Send(ctx, d.HTTP, w, delivery.ID, delivery.Event, delivery.Body); err != nil {
+			delivery.Attempts++
+			delivery.NextAttempt = now.Add(d.backoff(delivery.Attempts))
+			delivery.LastError = err.Error()
+			failed[delivery.URL] = true
+			remaining = append(remaining, delivery)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	q.Deliveries = remaining
+	return firstErr
+}
+
+// backoff attempts 回失敗した後、次に送るまでの間隔
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Backoff
+	for i := 1; i < attempts && wait < d.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.MaxBackoff)
+}
+
+// eventOf 変更履歴の変更を送る変更の種類に変換する
+func eventOf(c feed.Change) string {
+	switch c.Type {
+	case feed.Created:
+		return EventCreated
+	case feed.Deleted:
+		return EventDeleted
+	}
+	if c.Event.Type == models.EventToggled && c.Event.New == "true" {
+		return EventCompleted
+	}
+	return EventUpdated
+}
@@ -0,0 +1,168 @@
+// Package webhook はタスクの変更を Webhook の URL に POST する
+//
+// 変更は変更履歴ファイル（history.jsonl）から読み取り、送信待ちのキューに積んでから送る。
+// 送信に失敗した変更はキューに残して間隔を空けながら送り直すので、受信側が止まっていた間の変更も失われない。
+// 本文には Webhook ごとの秘密鍵による HMAC-SHA256 の署名を X-Godo-Signature ヘッダーで付ける。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 送る変更の種類
+const (
+	EventCreated   = "task.created"
+	EventUpdated   = "task.updated"
+	EventCompleted = "task.completed"
+	EventDeleted   = "task.deleted"
+	EventPing      = "ping" // godo webhooks test で送る
+)
+
+// Events 購読できる変更の種類
+var Events = []string{EventCreated, EventUpdated, EventCompleted, EventDeleted}
+
+// 送信するリクエストのヘッダー
+const (
+	SignatureHeader = "X-Godo-Signature" // "sha256=" に続けて本文の HMAC-SHA256 を16進数で表したもの
+	EventHeader     = "X-Godo-Event"
+	DeliveryHeader  = "X-Godo-Delivery"
+)
+
+// Webhook 変更を送る先
+type Webhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events,omitempty"` // 送る変更の種類（空ならすべて）
+}
+
+// Wants event の変更を送るか
+func (w *Webhook) Wants(event string) bool {
+	return event == EventPing || len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// Config Webhook の設定
+type Config struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+// LoadConfig 設定を読み込む（ファイルがなければ空の設定を返す）
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Webhook の設定を読み込めません: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("Webhook の設定が壊れています: %w", err)
+	}
+	return &config, nil
+}
+
+// Save 設定を保存する。秘密鍵を含むので所有者だけが読めるようにする
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Webhook の設定を保存できません: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("Webhook の設定を保存できません: %w", err)
+	}
+	return nil
+}
+
+// Find URL の Webhook を返す（なければ nil）
+func (c *Config) Find(url string) *Webhook {
+	for _, w := range c.Webhooks {
+		if w.URL == url {
+			return w
+		}
+	}
+	return nil
+}
+
+// ValidateEvents 変更の種類の指定を検証する
+func ValidateEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("変更の種類が不正です: %q (%s)", event, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// Change 変更した項目と変更前後の値
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Payload 送信する本文
+type Payload struct {
+	ID     string       `json:"id"` // 同じ変更を送り直すときは同じ値（受信側で重複を除くのに使う）
+	Event  string       `json:"event"`
+	Time   time.Time    `json:"time"`
+	TaskID int          `json:"task_id,omitempty"`
+	Task   *models.Task `json:"task,omitempty"` // 変更後のタスク（削除の場合は削除前のタスク）
+	Change *Change      `json:"change,omitempty"`
+}
+
+// Sign 本文の署名（"sha256=<16進数>"）を返す
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 受信した本文の署名を確認する
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send 署名を付けて本文を POST する。2xx 以外の応答はエラーにする
+func Send(ctx context.Context, client *http.Client, w *Webhook, id, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "godo-webhook")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s が %s を返しました", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Ping 接続を確認するための本文を作る
+func Ping(now time.Time) (id string, body []byte, err error) {
+	id = fmt.Sprintf("ping-%d", now.UnixNano())
+	body, err = json.Marshal(Payload{ID: id, Event: EventPing, Time: now})
+	return id, body, err
+}
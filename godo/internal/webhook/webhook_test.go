@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"godo/internal/feed"
+	"godo/internal/filelock"
+	"godo/pkg/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver 受け取ったリクエストを記録するテスト用の受信側
+type receiver struct {
+	mu       sync.Mutex
+	status   int // 0 なら 204 を返す
+	payloads []Payload
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	var p Payload
+	json.Unmarshal(body, &p)
+	r.payloads = append(r.payloads, p)
+	r.headers = append(r.headers, req.Header.Clone())
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) received() []Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Payload{}, r.payloads...)
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// appendEvents 変更履歴ファイルに追記する（storage.AppendHistory と同じ形式）
+func appendEvents(t *testing.T, path string, events ...models.Event) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestDispatcher 一時ディレクトリの設定・キュー・変更履歴を使う Dispatcher を作成する
+func newTestDispatcher(t *testing.T, webhooks ...*Webhook) (*Dispatcher, string) {
+	t.Helper()
+	dir := t.TempDir()
+	history := filepath.Join(dir, "history.jsonl")
+	d := NewDispatcher(dir, feed.NewHistory(history), nil)
+	if err := (&Config{Webhooks: webhooks}).Save(d.ConfigPath); err != nil {
+		t.Fatal(err)
+	}
+	return d, history
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"task.created"}`)
+	signature := Sign("secret", body)
+	if signature != "sha256=b835dced16788582434913f6e29d9ff8b26a16bd0704d9238275b871c3e7f007" {
+		t.Fatalf("Sign = %q", signature)
+	}
+	if !Verify("secret", body, signature) {
+		t.Error("Verify failed for valid signature")
+	}
+	if Verify("other", body, signature) || Verify("secret", []byte(`{}`), signature) {
+		t.Error("Verify accepted invalid signature")
+	}
+}
+
+func TestFlushDeliversSignedPayloads(t *testing.T) {
+	all, completed := &receiver{}, &receiver{}
+	allSrv, completedSrv := httptest.NewServer(all), httptest.NewServer(completed)
+	defer allSrv.Close()
+	defer completedSrv.Close()
+	d, history := newTestDispatcher(t,
+		&Webhook{URL: allSrv.URL, Secret: "s1"},
+		&Webhook{URL: completedSrv.URL, Secret: "s2", Events: []string{EventCompleted}},
+	)
+
+	// キューを作る前の変更は送らない
+	appendEvents(t, history, models.Event{TaskID: 1, Type: models.EventCreated, New: "古いタスク"})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.received()) != 0 {
+		t.Fatalf("sent old history: %v", all.received())
+	}
+
+	appendEvents(t, history,
+		models.Event{TaskID: 2, Type: models.EventCreated, New: "リリースする", Task: &models.Task{ID: 2, Title: "リリースする"}},
+		models.Event{TaskID: 2, Type: models.EventToggled, Field: "completed", Old: "false", New: "true"},
+		models.Event{TaskID: 2, Type: models.EventDeleted, Old: "リリースする", Task: &models.Task{ID: 2, Title: "リリースする", Completed: true}},
+	)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := all.received()
+	if len(got) != 3 || got[0].Event != EventCreated || got[1].Event != EventCompleted || got[2].Event != EventDeleted {
+		t.Fatalf("all = %+v", got)
+	}
+	if got[0].ID != "2" || got[0].Task == nil || got[0].Task.Title != "リリースする" || got[1].Change == nil || got[1].Change.New != "true" {
+		t.Errorf("payloads = %+v", got)
+	}
+	for i, h := range all.headers {
+		if !Verify("s1", all.bodies[i], h.Get(SignatureHeader)) || h.Get(EventHeader) != got[i].Event || h.Get(DeliveryHeader) != got[i].ID {
+			t.Errorf("headers[%d] = %v", i, h)
+		}
+	}
+	if got := completed.received(); len(got) != 1 || got[0].Event != EventCompleted || !Verify("s2", completed.bodies[0], completed.headers[0].Get(SignatureHeader)) {
+		t.Errorf("completed = %+v", got)
+	}
+
+	q, _ := LoadQueue(d.QueuePath)
+	if q.Cursor != 4 || len(q.Deliveries) != 0 {
+		t.Errorf("queue = %+v", q)
+	}
+}
+
+func TestFlushRetriesWithBackoff(t *testing.T) {
+	r := &receiver{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	d, history := newTestDispatcher(t, &Webhook{URL: srv.URL, Secret: "s"})
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	d.Now = func() time.Time { return now }
+	d.Flush(context.Background())
+
+	appendEvents(t, history,
+		models.Event{TaskID: 1, Type: models.EventCreated, New: "a"},
+		models.Event{TaskID: 1, Type: models.EventRenamed, Field: "title", Old: "a", New: "b"},
+	)
+	if err := d.Flush(context.Background()); err == nil {
+		t.Fatal("expected delivery error")
+	}
+	q, _ := LoadQueue(d.QueuePath)
+	if len(q.Deliveries) != 2 || q.Deliveries[0].Attempts != 1 || !q.Deliveries[0].NextAttempt.Equal(now.Add(30*time.Second)) || q.Deliveries[0].LastError == "" {
+		t.Fatalf("queue = %+v", q.Deliveries)
+	}
+	// 後の変更は先の変更を送るまで送らない
+	if q.Deliveries[1].Attempts != 0 {
+		t.Errorf("second delivery attempted: %+v", q.Deliveries[1])
+	}
+
+	// 失敗するたびに間隔を倍にする
+	now = now.Add(30 * time.Second)
+	d.Flush(context.Background())
+	q, _ = LoadQueue(d.QueuePath)
+	if q.Deliveries[0].Attempts != 2 || !q.Deliveries[0].NextAttempt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("queue = %+v", q.Deliveries[0])
+	}
+
+	// 受信側が戻っても、時刻になるまでは送らない
+	r.setStatus(0)
+	if err := d.Flush(context.Background()); err != nil || len(r.received()) != 0 {
+		t.Fatalf("sent before backoff: %v, %v", r.received(), err)
+	}
+	now = now.Add(time.Minute)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := r.received()
+	if len(got) != 2 || got[0].Event != EventCreated || got[1].Event != EventUpdated || got[1].Change.New != "b" {
+		t.Fatalf("received = %+v", got)
+	}
+	q, _ = LoadQueue(d.QueuePath)
+	if len(q.Deliveries) != 0 {
+		t.Errorf("queue = %+v", q.Deliveries)
+	}
+}
+
+func TestFlushDropsRemovedWebhooks(t *testing.T) {
+	d, history := newTestDispatcher(t, &Webhook{URL: "http://127.0.0.1:1/hook", Secret: "s"})
+	d.Flush(context.Background())
+	appendEvents(t, history, models.Event{TaskID: 1, Type: models.EventCreated, New: "a"})
+	d.Flush(context.Background())
+	if q, _ := LoadQueue(d.QueuePath); len(q.Deliveries) != 1 {
+		t.Fatalf("queue = %+v", q)
+	}
+
+	// 別の Webhook に置き換えると、削除した Webhook への変更は捨てる
+	r := &receiver{}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	(&Config{Webhooks: []*Webhook{{URL: srv.URL, Secret: "s"}}}).Save(d.ConfigPath)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := LoadQueue(d.QueuePath); len(q.Deliveries) != 0 || len(r.received()) != 0 {
+		t.Fatalf("queue = %+v, received = %v", q, r.received())
+	}
+}
+
+func TestFlushSkipsWhileAnotherProcessDelivers(t *testing.T) {
+	r := &receiver{}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	d, history := newTestDispatcher(t, &Webhook{URL: srv.URL, Secret: "s"})
+	d.Flush(context.Background())
+	appendEvents(t, history, models.Event{TaskID: 1, Type: models.EventCreated, New: "a"})
+
+	// 送信に時間がかかってロックファイルが古くなっても、ロックを取っている間は送らない
+	path := filepath.Join(filepath.Dir(d.QueuePath), lockFileName)
+	lock, err := filelock.TryLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path, old, old)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.received(); len(got) != 0 {
+		t.Fatalf("delivered while locked: %v", got)
+	}
+
+	lock.Release()
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.received(); len(got) != 1 {
+		t.Fatalf("received = %v", got)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	d := NewDispatcher(t.TempDir(), nil, nil)
+	tests := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 8: time.Hour, 50: time.Hour}
+	for attempts, want := range tests {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache Describe の結果を保存するファイル
+// 実行ファイルの大きさと更新日時が変わっていなければ、拡張機能を実行せずに保存した Manifest を使う
+// nil の Cache は何も保存せず、毎回 Describe する
+type Cache struct {
+	path    string
+	entries map[string]cacheEntry // 実行ファイルのパスごとの Describe の結果
+	changed bool
+}
+
+type cacheEntry struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Manifest *Manifest `json:"manifest"`
+}
+
+// OpenCache path のキャッシュを読み込む（なければ空のキャッシュを返す）
+func OpenCache(path string) *Cache {
+	c := &Cache{path: path, entries: map[string]cacheEntry{}}
+	if data, err := os.ReadFile(path); err == nil {
+		// 壊れたキャッシュは捨てて作り直す
+		if json.Unmarshal(data, &c.entries) != nil {
+			c.entries = map[string]cacheEntry{}
+		}
+	}
+	return c
+}
+
+// Describe キャッシュにある Manifest を e に設定する。なければ拡張機能に問い合わせてキャッシュする
+func (c *Cache) Describe(ctx context.Context, e *Extension) (*Manifest, error) {
+	if c == nil {
+		return e.Describe(ctx)
+	}
+	info, err := os.Stat(e.Path)
+	if err != nil {
+		return e.Describe(ctx)
+	}
+	if entry, ok := c.entries[e.Path]; ok && entry.Manifest != nil && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		e.Manifest = entry.Manifest
+		return entry.Manifest, nil
+	}
+	manifest, err := e.Describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[e.Path] = cacheEntry{Size: info.Size(), ModTime: info.ModTime(), Manifest: manifest}
+	c.changed = true
+	return manifest, nil
+}
+
+// Save 新しく問い合わせた結果があればキャッシュを書き出す
+func (c *Cache) Save() error {
+	if c == nil || !c.changed {
+		return nil
+	}
+	// 削除された拡張機能の結果は残さない
+	for path := range c.entries {
+		if _, err := os.Stat(path); err != nil {
+			delete(c.entries, path)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.changed = false
+	return nil
+}
@@ -0,0 +1,310 @@
+// Package extension は godo-<name> という名前の外部コマンドで godo を拡張する仕組みを提供する
+//
+// git と同じように PATH から godo-<name> の実行ファイルを探し、標準入力に Request の JSON を1つ渡して、
+// 標準出力から Response の JSON を1つ受け取る。Request.Type によって次の処理をする。
+//
+//	describe  拡張機能が追加するサブコマンドと計算項目（Manifest）を返す
+//	command   サブコマンドを実行する。Output を表示し、Tasks を追加・変更したタスク、Deleted を削除したタスクの ID として保存する
+//	fields    タスクごとの計算項目の値（Values）を返す。Column を指定した項目は TUI の一覧にも表示する
+//
+// タスクは models.Task の JSON 表現で受け渡す。拡張機能は Go で書く場合は Main を使い、
+// それ以外の言語でも標準入出力の JSON を読み書きすれば作れる。
+package extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion Request と Response の形式のバージョン
+// 互換性のない変更をしたときに上げる。拡張機能は知らないバージョンのリクエストをエラーにしてよい
+const ProtocolVersion = 1
+
+// Prefix 拡張機能の実行ファイル名の接頭辞
+const Prefix = "godo-"
+
+// DefaultTimeout describe と fields の実行を打ち切るまでの時間
+const DefaultTimeout = 5 * time.Second
+
+// リクエストの種類
+const (
+	TypeDescribe = "describe"
+	TypeCommand  = "command"
+	TypeFields   = "fields"
+)
+
+// Request 拡張機能の標準入力に渡す JSON
+type Request struct {
+	Version int            `json:"version"`
+	Type    string         `json:"type"`
+	Command string         `json:"command,omitempty"` // command: 実行するサブコマンドの名前
+	Args    []string       `json:"args,omitempty"`    // command: サブコマンドに続く引数
+	Tasks   []*models.Task `json:"tasks,omitempty"`   // command / fields: 現在のタスク
+}
+
+// Response 拡張機能が標準出力に書く JSON
+type Response struct {
+	Manifest *Manifest                 `json:"manifest,omitempty"` // describe
+	Output   string                    `json:"output,omitempty"`   // command: 表示する文字列
+	Tasks    []*models.Task            `json:"tasks,omitempty"`    // command: 追加（ID が 0）・変更したタスク
+	Deleted  []int                     `json:"deleted,omitempty"`  // command: 削除したタスクの ID
+	Values   map[int]map[string]string `json:"values,omitempty"`   // fields: タスクの ID ごとの計算項目の値
+	Error    string                    `json:"error,omitempty"`    // 空でなければ失敗
+}
+
+// Manifest 拡張機能が追加するもの
+type Manifest struct {
+	Name        string    `json:"name,omitempty"` // 省略すると実行ファイル名の godo- より後
+	Description string    `json:"description,omitempty"`
+	Commands    []Command `json:"commands,omitempty"` // 省略すると godo <name> を追加する
+	Fields      []Field   `json:"fields,omitempty"`
+}
+
+// Command 拡張機能が追加するサブコマンド
+type Command struct {
+	Name  string `json:"name"`
+	Short string `json:"short,omitempty"`
+}
+
+// Field 拡張機能が計算するタスクの項目
+type Field struct {
+	Name   string `json:"name"`
+	Title  string `json:"title,omitempty"`  // 表示名（省略すると Name）
+	Column bool   `json:"column,omitempty"` // TUI の一覧にも表示する
+}
+
+// Label 表示名を返す
+func (f Field) Label() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return f.Name
+}
+
+// Extension 見つかった拡張機能
+type Extension struct {
+	Name     string // 実行ファイル名の godo- より後（拡張子を除く）
+	Path     string
+	Manifest *Manifest // Describe するまでは nil
+	Timeout  time.Duration
+}
+
+// Discover PATH（pathEnv）から godo-<name> の実行ファイルを探す
+// 同じ名前の実行ファイルが複数ある場合は PATH の先にあるものを使う
+func Discover(pathEnv string) []*Extension {
+	seen := map[string]bool{}
+	var found []*Extension
+	for _, dir := range searchDirs(pathEnv) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := extensionName(entry.Name())
+			if !ok || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !executable(path) {
+				continue
+			}
+			seen[name] = true
+			found = append(found, &Extension{Name: name, Path: path, Timeout: DefaultTimeout})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// Find PATH（pathEnv）から godo-<name> の実行ファイルを探す（なければ nil）
+func Find(pathEnv, name string) *Extension {
+	if name == "" || strings.ContainsAny(name, " \t/\\") {
+		return nil
+	}
+	names := []string{Prefix + name}
+	if runtime.GOOS == "windows" {
+		names = []string{Prefix + name + ".exe", Prefix + name + ".bat", Prefix + name + ".cmd"}
+	}
+	for _, dir := range searchDirs(pathEnv) {
+		for _, file := range names {
+			if path := filepath.Join(dir, file); executable(path) {
+				return &Extension{Name: name, Path: path, Timeout: DefaultTimeout}
+			}
+		}
+	}
+	return nil
+}
+
+// searchDirs 拡張機能を探す PATH のディレクトリ
+// exec.LookPath と同じく、カレントディレクトリのファイルを実行しないように空の要素や相対パスは使わない
+func searchDirs(pathEnv string) []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// extensionName 実行ファイル名から拡張機能の名前を取り出す
+func extensionName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if !slices.Contains([]string{".exe", ".bat", ".cmd"}, ext) {
+			return "", false
+		}
+		name = name[:len(name)-len(ext)]
+	}
+	return name, name != "" && !strings.ContainsAny(name, " \t")
+}
+
+// executable 実行できるファイルか
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Describe 拡張機能が追加するものを問い合わせ、Manifest に設定する
+func (e *Extension) Describe(ctx context.Context) (*Manifest, error) {
+	resp, err := e.call(ctx, &Request{Type: TypeDescribe}, true)
+	if err != nil {
+		return nil, err
+	}
+	manifest := resp.Manifest
+	if manifest == nil {
+		manifest = &Manifest{}
+	}
+	if manifest.Name == "" {
+		manifest.Name = e.Name
+	}
+	if len(manifest.Commands) == 0 {
+		manifest.Commands = []Command{{Name: e.Name, Short: manifest.Description}}
+	}
+	e.Manifest = manifest
+	return manifest, nil
+}
+
+// Run サブコマンドを実行する
+// 実行時間は制限しないので、打ち切る場合は ctx をキャンセルする
+func (e *Extension) Run(ctx context.Context, command string, args []string, tasks []*models.Task) (*Response, error) {
+	return e.call(ctx, &Request{Type: TypeCommand, Command: command, Args: args, Tasks: tasks}, false)
+}
+
+// Fields タスクごとの計算項目の値を問い合わせる
+func (e *Extension) Fields(ctx context.Context, tasks []*models.Task) (map[int]map[string]string, error) {
+	resp, err := e.call(ctx, &Request{Type: TypeFields, Tasks: tasks}, true)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
+
+// call 拡張機能を実行してリクエストを渡し、応答を返す
+// limit が true なら Timeout で打ち切る
+func (e *Extension) call(ctx context.Context, req *Request, limit bool) (*Response, error) {
+	req.Version = ProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if limit {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, e.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "GODO_EXTENSION="+e.Name, "GODO_REQUEST="+req.Type)
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	if limit && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("拡張機能 %s が %s 以内に終了しませんでした", e.Name, timeout)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("拡張機能 %s の実行に失敗しました: %v%s", e.Name, runErr, detail(stderr.String()))
+		}
+		return nil, fmt.Errorf("拡張機能 %s の応答を解釈できません: %v", e.Name, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("拡張機能 %s: %s", e.Name, resp.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("拡張機能 %s の実行に失敗しました: %v%s", e.Name, runErr, detail(stderr.String()))
+	}
+	return &resp, nil
+}
+
+// detail 標準エラー出力をエラーメッセージに添える
+func detail(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	return ": " + strings.ReplaceAll(stderr, "\n", " ")
+}
+
+// Apply command の応答のタスクの追加・変更・削除を tm に反映し、変更したタスクの件数を返す
+// ID が 0 か存在しないタスクは追加する。不正なタスクがあれば何も反映せずにエラーを返す
+func Apply(tm *models.TaskManager, resp *Response) (int, error) {
+	for _, task := range resp.Tasks {
+		if task == nil || strings.TrimSpace(task.Title) == "" {
+			return 0, errors.New("拡張機能が返したタスクのタイトルが空です")
+		}
+		if err := models.ValidatePriority(task.Priority); err != nil {
+			return 0, err
+		}
+	}
+	for _, id := range resp.Deleted {
+		if tm.FindIndexByID(id) < 0 {
+			return 0, fmt.Errorf("拡張機能が削除した ID %d のタスクが見つかりません", id)
+		}
+	}
+
+	count := 0
+	for _, task := range resp.Tasks {
+		task.Title = strings.TrimSpace(task.Title)
+		if index := tm.FindIndexByID(task.ID); task.ID != 0 && index >= 0 {
+			tm.ApplyTask(index, task)
+		} else {
+			tm.ImportTask(task)
+		}
+		count++
+	}
+	for _, id := range resp.Deleted {
+		if tm.DeleteTask(tm.FindIndexByID(id)) {
+			count++
+		}
+	}
+	return count, nil
+}
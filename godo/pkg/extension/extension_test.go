@@ -0,0 +1,213 @@
+package extension
+
+import (
+	"context"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeExtension シェルスクリプトの拡張機能を置く
+func writeExtension(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトの拡張機能は Windows では実行できません")
+	}
+	if err := os.WriteFile(filepath.Join(dir, Prefix+name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// estimate 見積もりの計算項目と、見積もりを付けるサブコマンドを追加する拡張機能
+const estimate = `read req
+case "$req" in
+*'"type":"describe"'*)
+	echo '{"manifest":{"description":"見積もり","commands":[{"name":"estimate","short":"見積もりを付ける"}],"fields":[{"name":"estimate","title":"見積","column":true},{"name":"words"}]}}' ;;
+*'"type":"fields"'*)
+	echo '{"values":{"1":{"estimate":"2h","words":"1"},"2":{"estimate":"30m"}}}' ;;
+*'"args":["fail"]'*)
+	echo '{"error":"見積もれません"}'; exit 1 ;;
+*'"type":"command"'*)
+	echo '{"output":"見積もりました","tasks":[{"id":1,"title":"牛乳を買う","priority":"A"},{"title":"見積もりを見直す"}],"deleted":[2]}' ;;
+esac
+`
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeExtension(t, first, "estimate", "exit 0\n")
+	writeExtension(t, second, "estimate", "exit 0\n")
+	writeExtension(t, second, "burndown", "exit 0\n")
+	// 実行権限のないファイルと名前の違うファイルは拡張機能として扱わない
+	os.WriteFile(filepath.Join(second, Prefix+"notes"), []byte("#!/bin/sh\n"), 0644)
+	os.WriteFile(filepath.Join(second, "estimate"), []byte("#!/bin/sh\n"), 0755)
+
+	found := Discover(first + string(os.PathListSeparator) + second)
+	if len(found) != 2 || found[0].Name != "burndown" || found[1].Name != "estimate" {
+		t.Fatalf("found = %v", found)
+	}
+	if found[1].Path != filepath.Join(first, Prefix+"estimate") {
+		t.Errorf("estimate path = %s", found[1].Path)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	dir := t.TempDir()
+	writeExtension(t, dir, "estimate", estimate)
+	writeExtension(t, dir, "hello", `echo '{"manifest":{"description":"挨拶する"}}'`+"\n")
+	writeExtension(t, dir, "broken", "echo 壊れています >&2\nexit 3\n")
+
+	set, err := Load(context.Background(), dir)
+	if err == nil || !strings.Contains(err.Error(), "壊れています") {
+		t.Errorf("err = %v", err)
+	}
+	if len(set) != 2 {
+		t.Fatalf("set = %v", set)
+	}
+	// サブコマンドを省略すると godo <name> を追加する
+	if hello := set.Command("hello"); hello == nil || hello.Manifest.Commands[0].Short != "挨拶する" {
+		t.Errorf("hello = %+v", hello)
+	}
+	if set.Command("estimate") == nil || set.Command("broken") != nil {
+		t.Errorf("commands = %+v", set)
+	}
+	columns := set.Columns()
+	if len(set.Fields()) != 2 || len(columns) != 1 || columns[0].Label() != "見積" {
+		t.Errorf("fields = %v, columns = %v", set.Fields(), columns)
+	}
+}
+
+func TestValues(t *testing.T) {
+	dir := t.TempDir()
+	writeExtension(t, dir, "estimate", estimate)
+	set, err := Load(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("牛乳を買う")
+	tm.AddTask("パンを買う")
+
+	values, err := set.Values(context.Background(), tm.GetTasks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[1]["estimate"] != "2h" || values[1]["words"] != "1" || values[2]["estimate"] != "30m" {
+		t.Errorf("values = %v", values)
+	}
+}
+
+func TestRunAndApply(t *testing.T) {
+	dir := t.TempDir()
+	writeExtension(t, dir, "estimate", estimate)
+	set, _ := Load(context.Background(), dir)
+	e := set.Command("estimate")
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("牛乳を買う")
+	tm.AddTask("パンを買う")
+
+	if _, err := e.Run(context.Background(), "estimate", []string{"fail"}, tm.GetTasks()); err == nil || !strings.Contains(err.Error(), "見積もれません") {
+		t.Fatalf("err = %v", err)
+	}
+	resp, err := e.Run(context.Background(), "estimate", nil, tm.GetTasks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Output != "見積もりました" {
+		t.Errorf("output = %q", resp.Output)
+	}
+	count, err := Apply(tm, resp)
+	if err != nil || count != 3 {
+		t.Fatalf("Apply = %d, %v", count, err)
+	}
+	tasks := tm.GetTasks()
+	if len(tasks) != 2 || tasks[0].Priority != "A" || tasks[1].ID != 3 || tasks[1].Title != "見積もりを見直す" {
+		t.Fatalf("tasks = %v", tasks)
+	}
+}
+
+func TestApplyRejectsInvalidTasks(t *testing.T) {
+	tm := models.NewTaskManager([]*models.Task{})
+	tm.AddTask("牛乳を買う")
+	for _, resp := range []*Response{
+		{Tasks: []*models.Task{{Title: " "}}},
+		{Tasks: []*models.Task{{Title: "パンを買う", Priority: "high"}}},
+		{Deleted: []int{9}},
+	} {
+		if _, err := Apply(tm, resp); err == nil {
+			t.Errorf("Apply(%+v) succeeded", resp)
+		}
+	}
+	if len(tm.GetTasks()) != 1 {
+		t.Errorf("tasks = %v", tm.GetTasks())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeExtension(t, dir, "slow", "sleep 5\n")
+	e := Discover(dir)[0]
+	e.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	if _, err := e.Describe(context.Background()); err == nil || !strings.Contains(err.Error(), "以内に終了しませんでした") {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("took %s", elapsed)
+	}
+}
+
+func TestDiscoverSkipsRelativePathEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeExtension(t, dir, "local", "exit 0\n")
+	wd, _ := os.Getwd()
+	relative, err := filepath.Rel(wd, dir)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 空の要素や相対パスはカレントディレクトリからの場所になるので使わない
+	pathEnv := strings.Join([]string{"", ".", relative}, string(os.PathListSeparator))
+	if found := Discover(pathEnv); len(found) != 0 {
+		t.Errorf("found = %v", found)
+	}
+	if e := Find(pathEnv, "local"); e != nil {
+		t.Errorf("Find = %+v", e)
+	}
+	if e := Find(dir, "local"); e == nil || e.Path != filepath.Join(dir, Prefix+"local") {
+		t.Errorf("Find = %+v", e)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	writeExtension(t, dir, "hello", "echo x >> '"+calls+"'\necho '{\"manifest\":{\"description\":\"挨拶する\"}}'\n")
+	cachePath := filepath.Join(t.TempDir(), "extensions.json")
+
+	for i := 0; i < 2; i++ {
+		cache := OpenCache(cachePath)
+		set, err := LoadCached(context.Background(), dir, cache)
+		if err != nil || set.Command("hello") == nil {
+			t.Fatalf("set = %v, err = %v", set, err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2回目はキャッシュを使い、拡張機能を実行しない
+	if data, _ := os.ReadFile(calls); string(data) != "x\n" {
+		t.Errorf("describe was called %d times", strings.Count(string(data), "x"))
+	}
+
+	// 実行ファイルが変わったら問い合わせ直す
+	writeExtension(t, dir, "hello", "echo x >> '"+calls+"'\necho '{\"manifest\":{\"description\":\"挨拶します\"}}'\n")
+	os.Chtimes(filepath.Join(dir, Prefix+"hello"), time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	set, _ := LoadCached(context.Background(), dir, OpenCache(cachePath))
+	if got := set.Command("hello").Manifest.Description; got != "挨拶します" {
+		t.Errorf("description = %q", got)
+	}
+}
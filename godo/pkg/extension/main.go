@@ -0,0 +1,34 @@
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Handler 拡張機能のリクエストを処理する
+type Handler func(req *Request) (*Response, error)
+
+// Main 拡張機能の main から呼ぶ
+// 標準入力のリクエストを handler に渡して応答を標準出力に書き、失敗した場合は終了コード 1 で終了する
+func Main(handler Handler) {
+	var req Request
+	resp, err := &Response{}, error(nil)
+	if err = json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		err = fmt.Errorf("リクエストを解釈できません: %w", err)
+	} else if req.Version > ProtocolVersion {
+		err = fmt.Errorf("対応していないバージョンです: %d", req.Version)
+	} else {
+		resp, err = handler(&req)
+	}
+	if resp == nil {
+		resp = &Response{}
+	}
+	if err != nil {
+		resp = &Response{Error: err.Error()}
+	}
+	json.NewEncoder(os.Stdout).Encode(resp)
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"godo/pkg/models"
+)
+
+// Set 見つかった拡張機能の一覧
+type Set []*Extension
+
+// Load PATH（pathEnv）から拡張機能を探して Describe する
+// Describe に失敗した拡張機能は一覧から除き、そのエラーをまとめて返す
+func Load(ctx context.Context, pathEnv string) (Set, error) {
+	return LoadCached(ctx, pathEnv, nil)
+}
+
+// LoadCached Load と同じく拡張機能を探し、変更されていない拡張機能は cache の Manifest を使う
+// 新しく問い合わせた結果は cache.Save で保存する
+func LoadCached(ctx context.Context, pathEnv string, cache *Cache) (Set, error) {
+	var set Set
+	var errs []error
+	for _, e := range Discover(pathEnv) {
+		if _, err := cache.Describe(ctx, e); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		set = append(set, e)
+	}
+	return set, errors.Join(errs...)
+}
+
+// Fields すべての拡張機能の計算項目を返す
+// 同じ名前の項目は先に見つかった拡張機能のものを使う
+func (s Set) Fields() []Field {
+	seen := map[string]bool{}
+	var fields []Field
+	for _, e := range s {
+		if e.Manifest == nil {
+			continue
+		}
+		for _, f := range e.Manifest.Fields {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// Columns TUI の一覧に表示する計算項目を返す
+func (s Set) Columns() []Field {
+	var columns []Field
+	for _, f := range s.Fields() {
+		if f.Column {
+			columns = append(columns, f)
+		}
+	}
+	return columns
+}
+
+// Values 計算項目のある拡張機能に問い合わせ、タスクの ID ごとの値をまとめて返す
+// 問い合わせに失敗した拡張機能の値は含めず、そのエラーをまとめて返す
+func (s Set) Values(ctx context.Context, tasks []*models.Task) (map[int]map[string]string, error) {
+	values := map[int]map[string]string{}
+	var errs []error
+	for _, e := range s {
+		if e.Manifest == nil || len(e.Manifest.Fields) == 0 {
+			continue
+		}
+		got, err := e.Fields(ctx, tasks)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for id, fields := range got {
+			if values[id] == nil {
+				values[id] = map[string]string{}
+			}
+			for name, value := range fields {
+				if _, ok := values[id][name]; !ok {
+					values[id][name] = value
+				}
+			}
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+// Command name のサブコマンドを追加した拡張機能を返す（なければ nil）
+func (s Set) Command(name string) *Extension {
+	for _, e := range s {
+		if e.Manifest == nil {
+			continue
+		}
+		for _, c := range e.Manifest.Commands {
+			if c.Name == name {
+				return e
+			}
+		}
+	}
+	return nil
+}
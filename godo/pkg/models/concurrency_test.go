@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// go test -race で、読み取りと変更を同時に行ってもデータ競合が起きないことを確かめる
+func TestTaskManager_concurrentReadersAndWriters(t *testing.T) {
+	tm := NewTaskManager([]*Task{})
+	for i := 0; i < 10; i++ {
+		tm.AddTask(fmt.Sprintf("タスク%d", i))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				index := (w + i) % 10
+				tm.ToggleTask(index)
+				tm.UpdateTask(index, fmt.Sprintf("更新%d-%d", w, i))
+				tm.SetPriority(index, "B")
+				tm.SetTags(index, []string{"並行"})
+				tm.SetStatus(index, "doing")
+				tm.StartTimer(index, "")
+				tm.StopTimer()
+				tm.AddPomodoro(index)
+			}
+		}(w)
+	}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for _, task := range tm.GetTasks() {
+					_ = task.Title + task.Status
+					_ = task.TrackedTime(time.Now())
+				}
+				tm.GetStats()
+				tm.FindIndexByID(i % 10)
+				tm.RunningTimerIndex()
+				tm.History(i % 10)
+				tm.AllHistory()
+				tm.StatusOf(tm.GetTaskByIndex(i % 10))
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			tm.AddTask("追加")
+			tm.TakePendingEvents()
+		}
+	}()
+	wg.Wait()
+
+	if len(tm.GetTasks()) != 30 {
+		t.Errorf("タスク数 = %d, want 30", len(tm.GetTasks()))
+	}
+}
+
+func TestTaskManager_readsReturnCopies(t *testing.T) {
+	tm := NewTaskManager([]*Task{})
+	tm.AddTask("牛乳を買う")
+	tm.SetTags(0, []string{"買い物"})
+
+	task := tm.GetTaskByIndex(0)
+	task.Title = "書き換え"
+	task.Completed = true
+	task.Tags[0] = "書き換え"
+	tm.GetTasks()[0].Priority = "A"
+
+	got := tm.GetTaskByIndex(0)
+	if got.Title != "牛乳を買う" || got.Completed || got.Tags[0] != "買い物" || got.Priority != "" {
+		t.Errorf("コピーの変更が TaskManager のタスクに反映されています: %+v", got)
+	}
+
+	// 変更はメソッドで行い、最終更新日時も更新される
+	before := got.UpdatedAt
+	time.Sleep(time.Millisecond)
+	tm.ToggleTask(0)
+	if got := tm.GetTaskByIndex(0); !got.Completed || !got.UpdatedAt.After(before) {
+		t.Errorf("ToggleTask 後 = %+v", got)
+	}
+}
+
+func TestTaskManager_doesNotShareArgumentsOrEvents(t *testing.T) {
+	tm := NewTaskManager([]*Task{})
+	tm.AddTask("a")
+
+	// 渡した時刻を後から変更しても、タスクの期限や完了日時は変わらない
+	due := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tm.SetDue(0, &due)
+	completed := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	tm.ApplyTask(0, &Task{Title: "a", Completed: true, CompletedAt: &completed, DueAt: &due})
+	due = due.AddDate(1, 0, 0)
+	completed = completed.AddDate(1, 0, 0)
+	task := tm.GetTaskByIndex(0)
+	if task.DueAt.Year() != 2025 || task.CompletedAt.Year() != 2025 {
+		t.Fatalf("caller's time was shared: due = %v, completed = %v", task.DueAt, task.CompletedAt)
+	}
+
+	// 返した変更履歴のタスクを変更しても、TaskManager の履歴は変わらない
+	tm.DeleteTask(0)
+	for _, events := range [][]Event{tm.History(1), tm.AllHistory(), tm.TakePendingEvents()} {
+		for _, e := range events {
+			if e.Task != nil {
+				e.Task.Title = "書き換え"
+			}
+		}
+	}
+	for _, e := range tm.AllHistory() {
+		if e.Task != nil && e.Task.Title != "a" {
+			t.Fatalf("event task was shared: %+v", e.Task)
+		}
+	}
+}
+
+func TestTaskManager_importAndRestoreDoNotModifyArgument(t *testing.T) {
+	tm := NewTaskManager([]*Task{})
+	tm.AddTask("a")
+
+	imported := &Task{Title: "b"}
+	stored := tm.ImportTask(imported)
+	if imported.ID != 0 || !imported.UpdatedAt.IsZero() || !imported.CreatedAt.IsZero() {
+		t.Fatalf("ImportTask modified its argument: %+v", imported)
+	}
+	if stored.ID != 2 || stored.CreatedAt.IsZero() {
+		t.Fatalf("stored = %+v", stored)
+	}
+
+	// 重複するIDのタスクを戻すと新しいIDになるが、渡したタスクのIDは変わらない
+	restored := &Task{ID: 1, Title: "c"}
+	stored = tm.RestoreTask(restored)
+	if restored.ID != 1 || !restored.UpdatedAt.IsZero() {
+		t.Fatalf("RestoreTask modified its argument: %+v", restored)
+	}
+	if stored.ID != 3 || tm.GetTaskByIndex(2).ID != 3 {
+		t.Fatalf("stored = %+v", stored)
+	}
+	stored.Title = "書き換え"
+	if tm.GetTaskByIndex(2).Title != "c" {
+		t.Fatalf("returned task was shared")
+	}
+}
+
+func TestTaskManager_recordsUpdatesOfEveryField(t *testing.T) {
+	tm := NewTaskManager([]*Task{})
+	tm.AddTask("a")
+	tm.TakePendingEvents()
+
+	tm.StartTimer(0, "")
+	tm.StopTimer()
+	tm.AddPomodoro(0)
+	tm.SetPomodoros(0, 3)
+	tm.SetContexts(0, []string{"office"})
+	tm.SetAnnotations(0, []Annotation{{Text: "memo"}})
+	tm.ReplaceMeta(0, map[string]string{"uid": "u1"})
+	tm.SetTimeEntries(0, nil)
+	// 変わらない値を設定しても記録しない
+	tm.SetContexts(0, []string{"office"})
+	tm.ReplaceMeta(0, map[string]string{"uid": "u1"})
+
+	var fields []string
+	for _, e := range tm.TakePendingEvents() {
+		if e.Type != EventUpdated || e.TaskID != 1 {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+		fields = append(fields, e.Field)
+	}
+	want := []string{"time_entries", "time_entries", "pomodoros", "pomodoros", "contexts", "annotations", "meta", "time_entries"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+}
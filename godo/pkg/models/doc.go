@@ -0,0 +1,5 @@
+// Package models は godo のタスクとタスクの操作を提供する
+//
+// godo を fork せずに拡張するための公開パッケージで、Task・TaskManager・Store は互換性を保って変更する。
+// タスクの JSON 表現（Task の json タグ）は拡張機能（godo-<name>）との受け渡しにも使う。
+package models
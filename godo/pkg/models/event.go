@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EventType タスクの変更の種類
+type EventType string
+
+const (
+	EventCreated         EventType = "created"
+	EventRenamed         EventType = "renamed"
+	EventToggled         EventType = "toggled"
+	EventPriorityChanged EventType = "priority_changed"
+	EventStatusChanged   EventType = "status_changed"
+	EventUpdated         EventType = "updated" // プロジェクトや期限などその他の項目の変更
+	EventDeleted         EventType = "deleted"
+	EventArchived        EventType = "archived"
+	EventRestored        EventType = "restored"
+)
+
+// Event タスクの変更履歴の1件
+type Event struct {
+	Time   time.Time `json:"time"`
+	TaskID int       `json:"task_id"`
+	Type   EventType `json:"type"`
+	Field  string    `json:"field,omitempty"`
+	Old    string    `json:"old,omitempty"`
+	New    string    `json:"new,omitempty"`
+	Task   *Task     `json:"task,omitempty"` // 作成・削除・アーカイブ時のタスクの内容（復元用）
+}
+
+// Summary 変更内容を表示用の文章に変換する
+func (e Event) Summary() string {
+	switch e.Type {
+	case EventCreated:
+		return fmt.Sprintf("作成: '%s'", e.New)
+	case EventRenamed:
+		return fmt.Sprintf("タイトル変更: '%s' → '%s'", e.Old, e.New)
+	case EventToggled:
+		if e.New == "true" {
+			return "完了"
+		}
+		return "未完了に戻す"
+	case EventPriorityChanged:
+		return fmt.Sprintf("優先度変更: %s → %s", orNone(e.Old), orNone(e.New))
+	case EventStatusChanged:
+		return fmt.Sprintf("状態変更: %s → %s", orNone(e.Old), orNone(e.New))
+	case EventUpdated:
+		return fmt.Sprintf("%s変更: %s → %s", e.Field, orNone(e.Old), orNone(e.New))
+	case EventDeleted:
+		return fmt.Sprintf("削除: '%s'", e.Old)
+	case EventArchived:
+		return "アーカイブ"
+	case EventRestored:
+		return "アーカイブから復元"
+	}
+	return string(e.Type)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "なし"
+	}
+	return s
+}
+
+// snapshot 履歴に残すためのタスクのコピーを返す
+func snapshot(task *Task) *Task {
+	return task.Clone()
+}
+
+// record 変更履歴を追加する
+func (tm *TaskManager) record(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	tm.history = append(tm.history, e)
+	tm.pending = append(tm.pending, e)
+}
+
+// LoadHistory 保存済みの変更履歴を読み込む
+func (tm *TaskManager) LoadHistory(events []Event) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.history = append(cloneEvents(events), tm.history...)
+}
+
+// History 指定されたIDのタスクの変更履歴を古い順に返す
+func (tm *TaskManager) History(taskID int) []Event {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	var events []Event
+	for _, e := range tm.history {
+		if e.TaskID == taskID {
+			events = append(events, cloneEvent(e))
+		}
+	}
+	return events
+}
+
+// AllHistory 全てのタスクの変更履歴を古い順に返す
+func (tm *TaskManager) AllHistory() []Event {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return cloneEvents(tm.history)
+}
+
+// TakePendingEvents まだ保存していない変更履歴を返し、未保存の一覧を空にする
+func (tm *TaskManager) TakePendingEvents() []Event {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	events := cloneEvents(tm.pending)
+	tm.pending = nil
+	return events
+}
+
+// cloneEvent 変更履歴のコピーを返す（タスクの内容もコピーする）
+func cloneEvent(e Event) Event {
+	e.Task = e.Task.Clone()
+	return e
+}
+
+func cloneEvents(events []Event) []Event {
+	if events == nil {
+		return nil
+	}
+	c := make([]Event, len(events))
+	for i, e := range events {
+		c[i] = cloneEvent(e)
+	}
+	return c
+}
+
+func formatBool(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+func formatDue(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
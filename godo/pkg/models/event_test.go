@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestTaskManager_recordsHistory(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.AddTask("other")
+	m.UpdateTask(0, "a2")
+	m.UpdateTask(0, "a2") // 変更なしは記録しない
+	m.ToggleTask(0)
+	m.SetPriority(0, "B")
+	m.DeleteTask(0)
+
+	got := eventTypes(m.History(1))
+	want := []EventType{EventCreated, EventRenamed, EventToggled, EventPriorityChanged, EventDeleted}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	events := m.History(1)
+	if events[1].Old != "a" || events[1].New != "a2" {
+		t.Fatalf("rename should record old and new titles: %+v", events[1])
+	}
+	if events[4].Task == nil || events[4].Task.Title != "a2" || events[4].Task.Priority != "B" {
+		t.Fatalf("delete should keep a snapshot for recovery: %+v", events[4].Task)
+	}
+	if len(m.History(2)) != 1 {
+		t.Fatalf("history should be filtered by task ID")
+	}
+}
+
+func TestTaskManager_TakePendingEvents(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.LoadHistory([]Event{{TaskID: 1, Type: EventCreated, New: "old"}})
+	m.AddTask("new")
+
+	pending := m.TakePendingEvents()
+	if len(pending) != 1 || pending[0].Type != EventCreated || pending[0].New != "new" {
+		t.Fatalf("only new events should be pending: %+v", pending)
+	}
+	if len(m.TakePendingEvents()) != 0 {
+		t.Fatalf("pending events should be cleared after taking")
+	}
+	if len(m.AllHistory()) != 2 {
+		t.Fatalf("history should contain loaded and new events")
+	}
+}
+
+func TestTaskManager_statusChangeRecordsToggle(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.MoveTask(0, 1)
+	m.SetStatus(0, "done")
+
+	got := eventTypes(m.History(1))
+	want := []EventType{EventCreated, EventStatusChanged, EventStatusChanged, EventToggled}
+	if len(got) != len(want) || got[3] != EventToggled {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSetPriority_validates(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	for _, p := range []string{"a", "AA", "1"} {
+		if m.SetPriority(0, p) {
+			t.Fatalf("priority %q should be rejected", p)
+		}
+	}
+	if !m.SetPriority(0, "Z") || !m.SetPriority(0, "") {
+		t.Fatalf("valid priorities should be accepted")
+	}
+}
+
+func TestEvent_Summary(t *testing.T) {
+	cases := map[string]Event{
+		"タイトル変更: 'a' → 'b'": {Type: EventRenamed, Old: "a", New: "b"},
+		"完了":                {Type: EventToggled, New: "true"},
+		"優先度変更: なし → A":     {Type: EventPriorityChanged, New: "A"},
+		"due変更: なし → 2025":  {Type: EventUpdated, Field: "due", New: "2025"},
+	}
+	for want, e := range cases {
+		if got := e.Summary(); !strings.Contains(got, want) {
+			t.Fatalf("summary: got %q want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+// Store タスクを読み書きする先
+// godo の保存先（~/.godo/tasks.json など）やサーバーの API を同じ方法で扱うために使う
+type Store interface {
+	Load() (*TaskManager, error)
+	Save(tm *TaskManager) error
+}
@@ -0,0 +1,879 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Task struct {
+	ID          int               `json:"id"`
+	Title       string            `json:"title"`
+	Completed   bool              `json:"completed"`
+	Status      string            `json:"status,omitempty"`   // ワークフロー上の状態（未設定ならCompletedから判断）
+	Priority    string            `json:"priority,omitempty"` // 優先度（"A"が最も高い、未設定なら空）
+	Project     string            `json:"project,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Contexts    []string          `json:"contexts,omitempty"`     // 作業する場所や状況（todo.txtの@context）
+	DueAt       *time.Time        `json:"due_at,omitempty"`       // 期限（未設定ならnil）
+	CompletedAt *time.Time        `json:"completed_at,omitempty"` // 完了日時（未完了ならnil）
+	TimeEntries []TimeEntry       `json:"time_entries,omitempty"`
+	Annotations []Annotation      `json:"annotations,omitempty"` // タスクに付けたメモ（他のツールの注釈）
+	Pomodoros   int               `json:"pomodoros,omitempty"`   // 完了したポモドーロの回数
+	Meta        map[string]string `json:"meta,omitempty"`        // 他のツールから読み込んだ、対応する項目のない値
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Annotation タスクに付けた日時付きのメモ
+type Annotation struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// TimeEntry タスクに記録された作業時間の1区間
+type TimeEntry struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"` // nilの場合は計測中
+	Note  string     `json:"note,omitempty"`
+}
+
+// IsRunning 計測中かどうかを返す
+func (e TimeEntry) IsRunning() bool {
+	return e.End == nil
+}
+
+// Duration 記録された時間を返す（計測中の場合はnowまでの経過時間）
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	if end.Before(e.Start) {
+		return 0
+	}
+	return end.Sub(e.Start)
+}
+
+// 新しいタスクを作成する関数
+func NewTask(id int, title string) *Task {
+	now := time.Now()
+	return &Task{
+		ID:        id,
+		Title:     title,
+		Completed: false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// RunningEntry 計測中のTimeEntryを返す（計測中でなければnil）
+func (t *Task) RunningEntry() *TimeEntry {
+	for i := range t.TimeEntries {
+		if t.TimeEntries[i].IsRunning() {
+			return &t.TimeEntries[i]
+		}
+	}
+	return nil
+}
+
+// TrackedTime 記録された作業時間の合計を返す
+func (t *Task) TrackedTime(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range t.TimeEntries {
+		total += e.Duration(now)
+	}
+	return total
+}
+
+// SetCompleted 完了状態を設定し、完了日時を記録する
+func (t *Task) SetCompleted(completed bool, now time.Time) {
+	if completed && !t.Completed {
+		t.CompletedAt = &now
+	}
+	if !completed {
+		t.CompletedAt = nil
+	}
+	t.Completed = completed
+}
+
+// CompletedTime 完了日時を返す
+// 完了日時が記録されていない古いデータは最終更新日時で代用する
+func (t *Task) CompletedTime() time.Time {
+	if t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	return t.UpdatedAt
+}
+
+// IsArchivable 完了してからolderThan以上経過しているかを返す
+func (t *Task) IsArchivable(olderThan time.Duration, now time.Time) bool {
+	return t.Completed && !t.CompletedTime().After(now.Add(-olderThan))
+}
+
+// SplitArchivable タスクをアーカイブ対象とそれ以外に分ける
+func SplitArchivable(tasks []*Task, olderThan time.Duration, now time.Time) (keep, archived []*Task) {
+	keep = []*Task{}
+	for _, task := range tasks {
+		if task.IsArchivable(olderThan, now) {
+			archived = append(archived, task)
+		} else {
+			keep = append(keep, task)
+		}
+	}
+	return keep, archived
+}
+
+// TaskManager タスク管理を行う構造体
+// タスクの変更はすべて TaskManager のメソッドで行い、読み取りのメソッドはタスクのコピーを返す
+// 受け取ったタスクや時刻もコピーして保存するので、呼び出し側で後から変更しても影響しない
+// 複数の goroutine から同時に使える。ただしインデックスを受け取るメソッドは、FindIndexByID などで
+// インデックスを調べてから呼ぶまでの間に他の goroutine がタスクを追加・削除すると別のタスクを変更してしまう。
+// 複数の goroutine から変更する場合は、pkg/tasks の Manager のように外側でロックを取ってから
+// インデックスを調べて変更すること
+type TaskManager struct {
+	mu       sync.RWMutex
+	tasks    []*Task
+	nextID   int
+	workflow Workflow
+	history  []Event // 読み込んだ履歴とこのセッションでの変更履歴
+	pending  []Event // まだ保存していない変更履歴
+}
+
+// NewTaskManager 新しいTaskManagerを作成する
+// tasks は TaskManager が管理するので、呼び出し側では以後変更しないこと
+func NewTaskManager(tasks []*Task) *TaskManager {
+	nextID := 1
+	if len(tasks) > 0 {
+		// 既存のタスクから最大IDを取得
+		for _, task := range tasks {
+			if task.ID >= nextID {
+				nextID = task.ID + 1
+			}
+		}
+	}
+	
+	return &TaskManager{
+		tasks:    tasks,
+		nextID:   nextID,
+		workflow: DefaultWorkflow(),
+	}
+}
+
+// AddTask 新しいタスクを追加する
+func (tm *TaskManager) AddTask(title string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	task := NewTask(tm.nextID, title)
+	tm.tasks = append(tm.tasks, task)
+	tm.nextID++
+	tm.record(Event{Time: task.CreatedAt, TaskID: task.ID, Type: EventCreated, New: title, Task: snapshot(task)})
+}
+
+// GetTasks 全てのタスクのコピーを取得する（コピーを変更しても TaskManager のタスクは変わらない）
+func (tm *TaskManager) GetTasks() []*Task {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	tasks := make([]*Task, len(tm.tasks))
+	for i, task := range tm.tasks {
+		tasks[i] = task.Clone()
+	}
+	return tasks
+}
+
+// DeleteTask 指定されたインデックスのタスクを削除する
+func (tm *TaskManager) DeleteTask(index int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+	
+	task := tm.tasks[index]
+	// スライスから要素を削除
+	tm.tasks = append(tm.tasks[:index], tm.tasks[index+1:]...)
+	tm.record(Event{TaskID: task.ID, Type: EventDeleted, Old: task.Title, Task: snapshot(task)})
+	return true
+}
+
+// ToggleTask 指定されたインデックスのタスクの完了状態を切り替える
+func (tm *TaskManager) ToggleTask(index int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.toggleTask(index)
+}
+
+func (tm *TaskManager) toggleTask(index int) bool {
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+	
+	now := time.Now()
+	task := tm.tasks[index]
+	task.SetCompleted(!task.Completed, now)
+	if task.Completed {
+		task.Status = tm.workflow.DoneStatus()
+	} else {
+		task.Status = tm.workflow.FirstStatus()
+	}
+	task.UpdatedAt = now
+	tm.record(Event{Time: now, TaskID: task.ID, Type: EventToggled, Field: "completed",
+		Old: formatBool(!task.Completed), New: formatBool(task.Completed)})
+	return true
+}
+
+// UpdateTask 指定されたインデックスのタスクのタイトルを更新する
+func (tm *TaskManager) UpdateTask(index int, title string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.updateTask(index, title)
+}
+
+func (tm *TaskManager) updateTask(index int, title string) bool {
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+	
+	task := tm.tasks[index]
+	if task.Title == title {
+		return true
+	}
+	old := task.Title
+	task.Title = title
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventRenamed, Field: "title", Old: old, New: title})
+	return true
+}
+
+// GetTaskByIndex 指定されたインデックスのタスクのコピーを取得する
+func (tm *TaskManager) GetTaskByIndex(index int) *Task {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return nil
+	}
+	return tm.tasks[index].Clone()
+}
+
+// GetStats 完了済みと未完了のタスク数を取得する
+func (tm *TaskManager) GetStats() (completed, total int) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	total = len(tm.tasks)
+	for _, task := range tm.tasks {
+		if task.Completed {
+			completed++
+		}
+	}
+	return completed, total
+}
+
+// FindIndexByID 指定されたIDのタスクのインデックスを返す（見つからなければ-1）
+func (tm *TaskManager) FindIndexByID(id int) int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.findIndex(id)
+}
+
+func (tm *TaskManager) findIndex(id int) int {
+	for i, task := range tm.tasks {
+		if task.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetProject 指定されたインデックスのタスクのプロジェクトを設定する
+func (tm *TaskManager) SetProject(index int, project string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.setProject(index, project)
+}
+
+func (tm *TaskManager) setProject(index int, project string) bool {
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	if task.Project == project {
+		return true
+	}
+	old := task.Project
+	task.Project = project
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: "project", Old: old, New: project})
+	return true
+}
+
+// RunningTimerIndex タイマー計測中のタスクのインデックスを返す（なければ-1）
+func (tm *TaskManager) RunningTimerIndex() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.runningIndex()
+}
+
+func (tm *TaskManager) runningIndex() int {
+	for i, task := range tm.tasks {
+		if task.RunningEntry() != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// StartTimer 指定されたインデックスのタスクのタイマーを開始する
+// 同時に計測できるタイマーは1つだけなので、他のタスクで計測中のタイマーは停止する
+func (tm *TaskManager) StartTimer(index int, note string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+	if tm.tasks[index].RunningEntry() != nil {
+		// 既に計測中
+		return false
+	}
+
+	tm.stopTimer()
+	now := time.Now()
+	task := tm.tasks[index]
+	old := len(task.TimeEntries)
+	task.TimeEntries = append(task.TimeEntries, TimeEntry{Start: now, Note: note})
+	task.UpdatedAt = now
+	tm.recordCount(task, "time_entries", old, len(task.TimeEntries))
+	return true
+}
+
+// StopTimer 計測中のタイマーを停止し、停止したタスクのコピーを返す（計測中でなければnil）
+func (tm *TaskManager) StopTimer() *Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.stopTimer().Clone()
+}
+
+func (tm *TaskManager) stopTimer() *Task {
+	index := tm.runningIndex()
+	if index < 0 {
+		return nil
+	}
+
+	now := time.Now()
+	task := tm.tasks[index]
+	for i := range task.TimeEntries {
+		if task.TimeEntries[i].IsRunning() {
+			end := now
+			task.TimeEntries[i].End = &end
+		}
+	}
+	task.UpdatedAt = now
+	tm.record(Event{Time: now, TaskID: task.ID, Type: EventUpdated, Field: "time_entries", Old: "計測中", New: "停止"})
+	return task
+}
+
+// AddPomodoro 指定されたインデックスのタスクの完了ポモドーロ数を1つ増やす
+func (tm *TaskManager) AddPomodoro(index int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	task.Pomodoros++
+	task.UpdatedAt = time.Now()
+	tm.recordCount(task, "pomodoros", task.Pomodoros-1, task.Pomodoros)
+	return true
+}
+
+// SetWorkflow タスクの状態として使うワークフローを設定する
+func (tm *TaskManager) SetWorkflow(w Workflow) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.workflow = w
+}
+
+// Workflow 現在のワークフローを返す
+func (tm *TaskManager) Workflow() Workflow {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.workflow
+}
+
+// StatusOf タスクのワークフロー上の状態を返す
+func (tm *TaskManager) StatusOf(task *Task) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.workflow.StatusOf(task)
+}
+
+// SetStatus 指定されたインデックスのタスクの状態を設定する
+// 最後の列（完了）に移動したタスクは完了扱いになる
+func (tm *TaskManager) SetStatus(index int, status string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.setStatus(index, status)
+}
+
+func (tm *TaskManager) setStatus(index int, status string) bool {
+	if index < 0 || index >= len(tm.tasks) || tm.workflow.Index(status) < 0 {
+		return false
+	}
+
+	now := time.Now()
+	task := tm.tasks[index]
+	oldStatus := tm.workflow.StatusOf(task)
+	wasCompleted := task.Completed
+	task.Status = status
+	task.SetCompleted(status == tm.workflow.DoneStatus(), now)
+	task.UpdatedAt = now
+	if oldStatus != status {
+		tm.record(Event{Time: now, TaskID: task.ID, Type: EventStatusChanged, Field: "status", Old: oldStatus, New: status})
+	}
+	if wasCompleted != task.Completed {
+		tm.record(Event{Time: now, TaskID: task.ID, Type: EventToggled, Field: "completed",
+			Old: formatBool(wasCompleted), New: formatBool(task.Completed)})
+	}
+	return true
+}
+
+// MoveTask 指定されたインデックスのタスクをワークフロー上でdelta列だけ移動する
+func (tm *TaskManager) MoveTask(index int, delta int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	column := tm.workflow.Index(tm.workflow.StatusOf(tm.tasks[index])) + delta
+	if column < 0 || column >= len(tm.workflow.Columns) {
+		return false
+	}
+	return tm.setStatus(index, tm.workflow.Columns[column].Name)
+}
+
+// SetDue 指定されたインデックスのタスクの期限を設定する（nilで解除）
+func (tm *TaskManager) SetDue(index int, due *time.Time) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.setDue(index, due)
+}
+
+func (tm *TaskManager) setDue(index int, due *time.Time) bool {
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	old := formatDue(task.DueAt)
+	task.DueAt = cloneTime(due)
+	task.UpdatedAt = time.Now()
+	if old != formatDue(due) {
+		tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: "due", Old: old, New: formatDue(due)})
+	}
+	return true
+}
+
+// ArchiveCompleted 完了してからolderThan以上経過したタスクを取り除いて返す
+func (tm *TaskManager) ArchiveCompleted(olderThan time.Duration, now time.Time) []*Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	keep, archived := SplitArchivable(tm.tasks, olderThan, now)
+	tm.tasks = keep
+	for _, task := range archived {
+		tm.record(Event{Time: now, TaskID: task.ID, Type: EventArchived, Task: snapshot(task)})
+	}
+	return archived
+}
+
+// NextID 次に追加するタスクに割り当てるIDを返す
+func (tm *TaskManager) NextID() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.nextID
+}
+
+// ReserveIDs next より小さいIDを新しいタスクに割り当てないようにする
+// 削除・アーカイブしたタスクのIDを再び使わないように、保存しておいた次のIDを渡す
+func (tm *TaskManager) ReserveIDs(next int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if next > tm.nextID {
+		tm.nextID = next
+	}
+}
+
+// RestoreTask アーカイブから戻したタスクを追加する
+// IDが既存のタスクと重複する場合（古いバージョンで再利用されたIDなど）は新しいIDを割り当てる
+// 受け取ったタスクは変更せず、割り当てたIDのタスクのコピーを返す
+func (tm *TaskManager) RestoreTask(task *Task) *Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	task = task.Clone()
+	oldID := task.ID
+	if task.ID <= 0 || tm.findIndex(task.ID) >= 0 {
+		task.ID = tm.nextID
+	}
+	if task.ID >= tm.nextID {
+		tm.nextID = task.ID + 1
+	}
+	task.UpdatedAt = time.Now()
+	tm.tasks = append(tm.tasks, task)
+
+	e := Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventRestored}
+	if oldID != task.ID {
+		e.Field, e.Old, e.New = "id", strconv.Itoa(oldID), strconv.Itoa(task.ID)
+	}
+	tm.record(e)
+	return task.Clone()
+}
+
+// SetPriority 指定されたインデックスのタスクの優先度を設定する
+// 優先度は "A"〜"Z" の1文字（"A"が最も高い）で、空文字で解除する
+func (tm *TaskManager) SetPriority(index int, priority string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.setPriority(index, priority)
+}
+
+func (tm *TaskManager) setPriority(index int, priority string) bool {
+	if index < 0 || index >= len(tm.tasks) || ValidatePriority(priority) != nil {
+		return false
+	}
+
+	task := tm.tasks[index]
+	if task.Priority == priority {
+		return true
+	}
+	old := task.Priority
+	task.Priority = priority
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventPriorityChanged, Field: "priority", Old: old, New: priority})
+	return true
+}
+
+// SetTags 指定されたインデックスのタスクのタグを設定する
+func (tm *TaskManager) SetTags(index int, tags []string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.setTags(index, tags)
+}
+
+func (tm *TaskManager) setTags(index int, tags []string) bool {
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	if slices.Equal(task.Tags, tags) {
+		return true
+	}
+	old := strings.Join(task.Tags, ",")
+	task.Tags = slices.Clone(tags)
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: "tags", Old: old, New: strings.Join(tags, ",")})
+	return true
+}
+
+// ValidatePriority 優先度が "A"〜"Z" の1文字か空文字であるかを検証する
+func ValidatePriority(priority string) error {
+	if priority == "" || (len(priority) == 1 && priority[0] >= 'A' && priority[0] <= 'Z') {
+		return nil
+	}
+	return fmt.Errorf("優先度は A〜Z の1文字で指定してください: %q", priority)
+}
+
+// ImportTask 他の形式から読み込んだタスクを新しいIDで追加する
+// 作成日時が設定されていない場合は現在時刻を使う
+// 受け取ったタスクは変更せず、割り当てたIDのタスクのコピーを返す
+func (tm *TaskManager) ImportTask(task *Task) *Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	task = task.Clone()
+	now := time.Now()
+	task.ID = tm.nextID
+	tm.nextID++
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+		// 完了日だけが分かっている場合は作成日が完了日より後にならないようにする
+		if task.CompletedAt != nil && task.CompletedAt.Before(now) {
+			task.CreatedAt = *task.CompletedAt
+		}
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = task.CreatedAt
+	}
+	tm.tasks = append(tm.tasks, task)
+	tm.record(Event{Time: now, TaskID: task.ID, Type: EventCreated, New: task.Title, Task: snapshot(task)})
+	return task.Clone()
+}
+
+// ApplyTask 他のアプリで変更されたタスクの内容を指定されたインデックスのタスクに反映する
+// ID・作成日時・作業時間は元のタスクのものを残す
+func (tm *TaskManager) ApplyTask(index int, from *Task) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	tm.updateTask(index, from.Title)
+	if task.Completed != from.Completed {
+		tm.toggleTask(index)
+		if task.Completed && from.CompletedAt != nil {
+			task.CompletedAt = cloneTime(from.CompletedAt)
+		}
+	}
+	if !task.Completed && from.Status != "" && tm.workflow.Index(from.Status) >= 0 && tm.workflow.StatusOf(task) != from.Status {
+		tm.setStatus(index, from.Status)
+	}
+	tm.setPriority(index, from.Priority)
+	tm.setProject(index, from.Project)
+	if formatDue(task.DueAt) != formatDue(from.DueAt) {
+		tm.setDue(index, from.DueAt)
+	}
+	tm.setTags(index, from.Tags)
+	for key, value := range from.Meta {
+		if task.Meta == nil {
+			task.Meta = map[string]string{}
+		}
+		task.Meta[key] = value
+	}
+	return true
+}
+
+// RevertTask 指定されたIDのタスクを変更前の内容に戻し、まだ保存していない変更履歴を取り消す
+// old が nil の場合（追加を取り消す場合）はタスクを取り除き、削除したタスクは index の位置に戻す
+func (tm *TaskManager) RevertTask(id int, old *Task, index int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	current := tm.findIndex(id)
+	switch {
+	case old == nil:
+		if current >= 0 {
+			tm.tasks = append(tm.tasks[:current], tm.tasks[current+1:]...)
+		}
+	case current >= 0:
+		tm.tasks[current] = old.Clone()
+	default:
+		index = min(max(index, 0), len(tm.tasks))
+		tm.tasks = append(tm.tasks[:index], append([]*Task{old.Clone()}, tm.tasks[index:]...)...)
+	}
+
+	var pending []Event
+	removed := 0
+	for _, e := range tm.pending {
+		if e.TaskID == id {
+			removed++
+		} else {
+			pending = append(pending, e)
+		}
+	}
+	tm.pending = pending
+	// 取り消した変更履歴は history の末尾にも残っている
+	for i := len(tm.history) - 1; i >= 0 && removed > 0; i-- {
+		if tm.history[i].TaskID == id {
+			tm.history = append(tm.history[:i], tm.history[i+1:]...)
+			removed--
+		}
+	}
+}
+
+// SetTimeEntries 指定されたインデックスのタスクの作業時間の記録を置き換える
+func (tm *TaskManager) SetTimeEntries(index int, entries []TimeEntry) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	old := len(task.TimeEntries)
+	task.TimeEntries = cloneEntries(entries)
+	task.UpdatedAt = time.Now()
+	tm.recordCount(task, "time_entries", old, len(entries))
+	return true
+}
+
+// SetPomodoros 指定されたインデックスのタスクの完了ポモドーロ数を設定する
+func (tm *TaskManager) SetPomodoros(index int, pomodoros int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) || pomodoros < 0 {
+		return false
+	}
+
+	task := tm.tasks[index]
+	old := task.Pomodoros
+	if old == pomodoros {
+		return true
+	}
+	task.Pomodoros = pomodoros
+	task.UpdatedAt = time.Now()
+	tm.recordCount(task, "pomodoros", old, pomodoros)
+	return true
+}
+
+// SetContexts 指定されたインデックスのタスクのコンテキストを設定する
+func (tm *TaskManager) SetContexts(index int, contexts []string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	if slices.Equal(task.Contexts, contexts) {
+		return true
+	}
+	old := strings.Join(task.Contexts, ",")
+	task.Contexts = slices.Clone(contexts)
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: "contexts", Old: old, New: strings.Join(contexts, ",")})
+	return true
+}
+
+// SetAnnotations 指定されたインデックスのタスクのメモを置き換える
+func (tm *TaskManager) SetAnnotations(index int, annotations []Annotation) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	old := len(task.Annotations)
+	task.Annotations = slices.Clone(annotations)
+	task.UpdatedAt = time.Now()
+	tm.recordCount(task, "annotations", old, len(annotations))
+	return true
+}
+
+// ReplaceMeta 指定されたインデックスのタスクのメタ情報を meta で置き換える
+func (tm *TaskManager) ReplaceMeta(index int, meta map[string]string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	old := formatMeta(task.Meta)
+	if old == formatMeta(meta) {
+		return true
+	}
+	task.Meta = nil
+	if len(meta) > 0 {
+		task.Meta = make(map[string]string, len(meta))
+		for key, value := range meta {
+			task.Meta[key] = value
+		}
+	}
+	task.UpdatedAt = time.Now()
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: "meta", Old: old, New: formatMeta(meta)})
+	return true
+}
+
+// recordCount 件数で表す項目（作業時間・メモ・ポモドーロ数）の変更を記録する
+func (tm *TaskManager) recordCount(task *Task, field string, old, new int) {
+	tm.record(Event{Time: task.UpdatedAt, TaskID: task.ID, Type: EventUpdated, Field: field, Old: strconv.Itoa(old), New: strconv.Itoa(new)})
+}
+
+// formatMeta メタ情報を変更履歴に記録する "key=value,..." の形式（キーの順）に変換する
+func formatMeta(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + meta[key]
+	}
+	return strings.Join(parts, ",")
+}
+
+// SetMeta 指定されたインデックスのタスクのメタ情報を設定する（空文字で削除）
+// 他のツールとの対応づけに使う値を記録するためのもので、UpdatedAt は変更しない
+func (tm *TaskManager) SetMeta(index int, key, value string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if index < 0 || index >= len(tm.tasks) {
+		return false
+	}
+
+	task := tm.tasks[index]
+	if value == "" {
+		delete(task.Meta, key)
+		return true
+	}
+	if task.Meta == nil {
+		task.Meta = map[string]string{}
+	}
+	task.Meta[key] = value
+	return true
+}
+
+// ChangeID 指定されたIDのタスクのIDを変更する（サーバーが割り当てたIDに合わせる場合など）
+// 変更後のIDが他のタスクと重複する場合は何もしない
+func (tm *TaskManager) ChangeID(oldID, newID int) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	index := tm.findIndex(oldID)
+	if index < 0 || newID <= 0 || (oldID != newID && tm.findIndex(newID) >= 0) {
+		return false
+	}
+
+	tm.tasks[index].ID = newID
+	if newID >= tm.nextID {
+		tm.nextID = newID + 1
+	}
+	return true
+}
+
+// Clone タスクのコピーを返す
+// スライス・マップ・ポインタの項目もコピーするので、コピーを変更しても元のタスクは変わらない
+func (t *Task) Clone() *Task {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Tags = slices.Clone(t.Tags)
+	c.Contexts = slices.Clone(t.Contexts)
+	c.DueAt = cloneTime(t.DueAt)
+	c.CompletedAt = cloneTime(t.CompletedAt)
+	c.Annotations = slices.Clone(t.Annotations)
+	c.TimeEntries = cloneEntries(t.TimeEntries)
+	if t.Meta != nil {
+		c.Meta = make(map[string]string, len(t.Meta))
+		for key, value := range t.Meta {
+			c.Meta[key] = value
+		}
+	}
+	return &c
+}
+
+func cloneEntries(entries []TimeEntry) []TimeEntry {
+	if entries == nil {
+		return nil
+	}
+	c := make([]TimeEntry, len(entries))
+	for i, e := range entries {
+		e.End = cloneTime(e.End)
+		c[i] = e
+	}
+	return c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTask(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	task := NewTask(1, "test")
+	if task.ID != 1 {
+		t.Fatalf("expected ID 1, got %d", task.ID)
+	}
+	if task.Title != "test" {
+		t.Fatalf("expected Title 'test', got %q", task.Title)
+	}
+	if task.Completed {
+		t.Fatalf("expected Completed false, got true")
+	}
+	if task.CreatedAt.Before(before) || task.UpdatedAt.Before(before) {
+		t.Fatalf("timestamps not set correctly")
+	}
+}
+
+func TestTaskManager_AddGetStats(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.AddTask("b")
+	if completed, total := m.GetStats(); completed != 0 || total != 2 {
+		t.Fatalf("expected (0,2), got (%d,%d)", completed, total)
+	}
+	// toggle first
+	if !m.ToggleTask(0) {
+		t.Fatalf("toggle should succeed")
+	}
+	if completed, total := m.GetStats(); completed != 1 || total != 2 {
+		t.Fatalf("expected (1,2), got (%d,%d)", completed, total)
+	}
+}
+
+func TestTaskManager_DeleteUpdateBounds(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.AddTask("b")
+	if !m.UpdateTask(1, "bb") {
+		t.Fatalf("update should succeed")
+	}
+	if m.GetTaskByIndex(1).Title != "bb" {
+		t.Fatalf("title not updated")
+	}
+	if !m.DeleteTask(0) {
+		t.Fatalf("delete should succeed")
+	}
+	if m.DeleteTask(10) {
+		t.Fatalf("out-of-range delete should fail")
+	}
+	if m.ToggleTask(-1) {
+		t.Fatalf("negative index toggle should fail")
+	}
+}
+
+func TestNewTaskManagerWithExistingTasksSetsNextID(t *testing.T) {
+	// existing tasks with max ID 5
+	existing := []*Task{
+		{ID: 2, Title: "x"},
+		{ID: 5, Title: "y"},
+	}
+	m := NewTaskManager(existing)
+	m.AddTask("z")
+	lastTask := m.GetTaskByIndex(len(m.GetTasks()) - 1)
+	if lastTask.ID != 6 {
+		// nextID should continue from max(existing)+1
+		t.Fatalf("expected new task ID 6, got %d", lastTask.ID)
+	}
+}
+
+
+
+func TestTaskManager_TimerOnlyOneRunning(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.AddTask("b")
+	if !m.StartTimer(0, "") {
+		t.Fatalf("start should succeed")
+	}
+	if m.StartTimer(0, "") {
+		t.Fatalf("starting an already running timer should fail")
+	}
+	if m.RunningTimerIndex() != 0 {
+		t.Fatalf("expected running timer on index 0")
+	}
+	// 別タスクで開始すると既存のタイマーは停止する
+	if !m.StartTimer(1, "review") {
+		t.Fatalf("start on another task should succeed")
+	}
+	if m.RunningTimerIndex() != 1 {
+		t.Fatalf("expected running timer on index 1, got %d", m.RunningTimerIndex())
+	}
+	if m.GetTaskByIndex(0).RunningEntry() != nil {
+		t.Fatalf("first task timer should be stopped")
+	}
+	stopped := m.StopTimer()
+	if stopped == nil || stopped.ID != 2 {
+		t.Fatalf("expected task 2 to be stopped, got %+v", stopped)
+	}
+	if m.StopTimer() != nil {
+		t.Fatalf("stop without running timer should return nil")
+	}
+	if got := m.GetTaskByIndex(1).TimeEntries[0].Note; got != "review" {
+		t.Fatalf("note not recorded: %q", got)
+	}
+}
+
+func TestTask_TrackedTime(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(30 * time.Minute)
+	task := &Task{TimeEntries: []TimeEntry{
+		{Start: start, End: &end},
+		{Start: start.Add(time.Hour)},
+	}}
+	now := start.Add(90 * time.Minute)
+	if got := task.TrackedTime(now); got != time.Hour {
+		t.Fatalf("expected 1h, got %v", got)
+	}
+	if task.RunningEntry() == nil {
+		t.Fatalf("expected running entry")
+	}
+}
+
+func TestTaskManager_AddPomodoro(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	if !m.AddPomodoro(0) || !m.AddPomodoro(0) {
+		t.Fatalf("add pomodoro should succeed")
+	}
+	if got := m.GetTaskByIndex(0).Pomodoros; got != 2 {
+		t.Fatalf("expected 2 pomodoros, got %d", got)
+	}
+	if m.AddPomodoro(1) {
+		t.Fatalf("out-of-range add pomodoro should fail")
+	}
+}
+
+func TestTaskManager_ArchiveAndRestore(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.AddTask("b")
+	m.ToggleTask(0)
+	now := time.Now()
+
+	if archived := m.ArchiveCompleted(time.Hour, now); len(archived) != 0 {
+		t.Fatalf("recently completed task should not be archived yet")
+	}
+	archived := m.ArchiveCompleted(time.Hour, now.Add(2*time.Hour))
+	if len(archived) != 1 || archived[0].Title != "a" || len(m.GetTasks()) != 1 {
+		t.Fatalf("unexpected archive result: %+v", archived)
+	}
+
+	// 重複しないIDはそのまま戻す
+	restored := m.RestoreTask(archived[0])
+	if restored.ID != 1 || len(m.GetTasks()) != 2 {
+		t.Fatalf("expected task 1 restored, got %+v", restored)
+	}
+	// 重複するIDは新しいIDを割り当てる
+	dup := m.RestoreTask(&Task{ID: 2, Title: "dup"})
+	if dup.ID != 3 {
+		t.Fatalf("expected new ID 3 for duplicate, got %d", dup.ID)
+	}
+	m.AddTask("c")
+	if last := m.GetTaskByIndex(len(m.GetTasks()) - 1); last.ID != 4 {
+		t.Fatalf("nextID should continue after restored IDs, got %d", last.ID)
+	}
+}
+
+func TestImportTask(t *testing.T) {
+	m := NewTaskManager([]*Task{{ID: 5, Title: "existing"}})
+	created := time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local)
+	imported := m.ImportTask(&Task{ID: 1, Title: "imported", CreatedAt: created})
+	if imported.ID != 6 || len(m.GetTasks()) != 2 {
+		t.Fatalf("expected imported task to get new ID 6, got %+v", imported)
+	}
+	if !imported.CreatedAt.Equal(created) || !imported.UpdatedAt.Equal(created) {
+		t.Fatalf("imported dates should be kept, got %+v", imported)
+	}
+	events := m.History(6)
+	if len(events) != 1 || events[0].Type != EventCreated {
+		t.Fatalf("expected created event, got %+v", events)
+	}
+
+	completed := time.Date(2025, 1, 5, 0, 0, 0, 0, time.Local)
+	done := m.ImportTask(&Task{Title: "done", Completed: true, CompletedAt: &completed})
+	if !done.CreatedAt.Equal(completed) {
+		t.Fatalf("CreatedAt should default to CompletedAt, got %v", done.CreatedAt)
+	}
+}
+
+func TestApplyTask(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	m.SetTimeEntries(0, []TimeEntry{{Start: time.Now()}})
+
+	due := time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)
+	completed := time.Date(2025, 1, 9, 12, 0, 0, 0, time.Local)
+	m.ApplyTask(0, &Task{ID: 99, Title: "b", Completed: true, CompletedAt: &completed, Priority: "B",
+		Project: "work", Tags: []string{"x"}, DueAt: &due, Meta: map[string]string{"uid": "u1"}})
+
+	task := m.GetTaskByIndex(0)
+	if task.ID != 1 || len(task.TimeEntries) != 1 {
+		t.Fatalf("ID and time entries should be kept, got %+v", task)
+	}
+	if task.Title != "b" || !task.Completed || !task.CompletedAt.Equal(completed) || task.Priority != "B" ||
+		task.Project != "work" || len(task.Tags) != 1 || task.DueAt == nil || task.Meta["uid"] != "u1" {
+		t.Fatalf("fields not applied: %+v", task)
+	}
+	// 作成・作業時間・タイトル・完了・優先度・プロジェクト・期限・タグ
+	if events := m.History(1); len(events) != 8 {
+		t.Fatalf("expected 8 events, got %+v", events)
+	}
+}
+
+func TestToggleTask_recordsCompletedAt(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	task := m.GetTaskByIndex(0)
+	if task.CompletedAt != nil {
+		t.Fatalf("new task should not have CompletedAt")
+	}
+	m.ToggleTask(0)
+	if task = m.GetTaskByIndex(0); task.CompletedAt == nil || task.CompletedAt.Before(task.CreatedAt) {
+		t.Fatalf("CompletedAt should be recorded on completion: %+v", task)
+	}
+	m.ToggleTask(0)
+	if m.GetTaskByIndex(0).CompletedAt != nil {
+		t.Fatalf("CompletedAt should be cleared when reopened")
+	}
+	// ボードで完了列に移動した場合も記録される
+	m.SetStatus(0, "done")
+	if m.GetTaskByIndex(0).CompletedAt == nil {
+		t.Fatalf("CompletedAt should be recorded when moved to done")
+	}
+	completedAt := *task.CompletedAt
+	m.SetStatus(0, "done")
+	if !task.CompletedAt.Equal(completedAt) {
+		t.Fatalf("CompletedAt should not change while staying completed")
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Column ワークフローの1列（タスクの状態）
+type Column struct {
+	Name     string
+	WIPLimit int // 同時に置けるタスク数の上限（0なら無制限）
+}
+
+// Workflow タスクの状態の並び
+// 最初の列が未着手、最後の列が完了を表す
+type Workflow struct {
+	Columns []Column
+}
+
+// DefaultWorkflow todo / doing / review / done の4列のワークフローを返す
+func DefaultWorkflow() Workflow {
+	return Workflow{Columns: []Column{
+		{Name: "todo"},
+		{Name: "doing"},
+		{Name: "review"},
+		{Name: "done"},
+	}}
+}
+
+// ParseWorkflow "todo,doing:3,review:2,done" の形式からワークフローを作成する
+// 列名の後ろの ":数値" はWIP制限を表す
+func ParseWorkflow(spec string) (Workflow, error) {
+	var w Workflow
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, limitText, hasLimit := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return Workflow{}, fmt.Errorf("列名が空です: %q", part)
+		}
+		if seen[name] {
+			return Workflow{}, fmt.Errorf("列名が重複しています: %q", name)
+		}
+		seen[name] = true
+
+		column := Column{Name: name}
+		if hasLimit {
+			limit, err := strconv.Atoi(strings.TrimSpace(limitText))
+			if err != nil || limit < 0 {
+				return Workflow{}, fmt.Errorf("WIP制限が不正です: %q", part)
+			}
+			column.WIPLimit = limit
+		}
+		w.Columns = append(w.Columns, column)
+	}
+	if len(w.Columns) < 2 {
+		return Workflow{}, fmt.Errorf("ワークフローには2列以上が必要です: %q", spec)
+	}
+	return w, nil
+}
+
+// String ParseWorkflowで読み込める形式に変換する
+func (w Workflow) String() string {
+	parts := make([]string, len(w.Columns))
+	for i, c := range w.Columns {
+		parts[i] = c.Name
+		if c.WIPLimit > 0 {
+			parts[i] += ":" + strconv.Itoa(c.WIPLimit)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Index 状態名の列番号を返す（見つからなければ-1）
+func (w Workflow) Index(status string) int {
+	for i, c := range w.Columns {
+		if c.Name == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// FirstStatus 未着手を表す最初の列の名前を返す
+func (w Workflow) FirstStatus() string {
+	return w.Columns[0].Name
+}
+
+// DoneStatus 完了を表す最後の列の名前を返す
+func (w Workflow) DoneStatus() string {
+	return w.Columns[len(w.Columns)-1].Name
+}
+
+// StatusOf タスクの状態を返す
+// 状態が未設定かワークフローにない場合は、Completedから未着手か完了かを判断する
+func (w Workflow) StatusOf(task *Task) string {
+	if task.Status != "" && w.Index(task.Status) >= 0 {
+		return task.Status
+	}
+	if task.Completed {
+		return w.DoneStatus()
+	}
+	return w.FirstStatus()
+}
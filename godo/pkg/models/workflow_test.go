@@ -0,0 +1,81 @@
+package models
+
+import "testing"
+
+func TestParseWorkflow(t *testing.T) {
+	w, err := ParseWorkflow("todo, doing:3 ,review:2,done")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(w.Columns) != 4 || w.Columns[1].Name != "doing" || w.Columns[1].WIPLimit != 3 {
+		t.Fatalf("unexpected workflow: %+v", w)
+	}
+	if w.FirstStatus() != "todo" || w.DoneStatus() != "done" {
+		t.Fatalf("unexpected first/done: %q %q", w.FirstStatus(), w.DoneStatus())
+	}
+	if got := w.String(); got != "todo,doing:3,review:2,done" {
+		t.Fatalf("String: got %q", got)
+	}
+
+	for _, spec := range []string{"", "todo", "todo,todo", "todo,doing:x,done", ":1,done"} {
+		if _, err := ParseWorkflow(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestWorkflow_StatusOfFallsBackToCompleted(t *testing.T) {
+	w := DefaultWorkflow()
+	// 状態が未設定の古いデータはCompletedから判断する
+	if got := w.StatusOf(&Task{Completed: true}); got != "done" {
+		t.Fatalf("expected done, got %q", got)
+	}
+	if got := w.StatusOf(&Task{}); got != "todo" {
+		t.Fatalf("expected todo, got %q", got)
+	}
+	// ワークフローにない状態も同様
+	if got := w.StatusOf(&Task{Status: "unknown"}); got != "todo" {
+		t.Fatalf("expected todo for unknown status, got %q", got)
+	}
+	if got := w.StatusOf(&Task{Status: "review"}); got != "review" {
+		t.Fatalf("expected review, got %q", got)
+	}
+}
+
+func TestTaskManager_MoveTaskKeepsCompletedInSync(t *testing.T) {
+	m := NewTaskManager([]*Task{})
+	m.AddTask("a")
+	if m.MoveTask(0, -1) {
+		t.Fatalf("cannot move before the first column")
+	}
+	for i := 0; i < 3; i++ {
+		if !m.MoveTask(0, 1) {
+			t.Fatalf("move %d should succeed", i)
+		}
+	}
+	task := m.GetTaskByIndex(0)
+	if task.Status != "done" || !task.Completed {
+		t.Fatalf("task in done column should be completed: %+v", task)
+	}
+	if m.MoveTask(0, 1) {
+		t.Fatalf("cannot move past the last column")
+	}
+	if !m.MoveTask(0, -1) {
+		t.Fatalf("moving back should succeed")
+	}
+	if task = m.GetTaskByIndex(0); task.Completed || task.Status != "review" {
+		t.Fatalf("moving back should un-complete the task: %+v", task)
+	}
+	// トグルは完了列と最初の列を行き来する
+	m.ToggleTask(0)
+	if task = m.GetTaskByIndex(0); task.Status != "done" {
+		t.Fatalf("toggle should move to done, got %q", task.Status)
+	}
+	m.ToggleTask(0)
+	if task = m.GetTaskByIndex(0); task.Status != "todo" {
+		t.Fatalf("toggle should move back to todo, got %q", task.Status)
+	}
+	if m.SetStatus(0, "nope") {
+		t.Fatalf("unknown status should be rejected")
+	}
+}
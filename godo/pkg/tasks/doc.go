@@ -0,0 +1,35 @@
+// Package tasks は godo のタスク管理を他の Go のプログラムに組み込むための公開パッケージ
+//
+// Manager は複数の goroutine から同時に使えるタスクの管理で、タスクの追加・変更・削除と、
+// Filter・SortOrder による検索を提供する。タスクを読み書きする先は Store で、
+// godo と同じファイル（~/.godo/tasks.json と変更履歴）を使う FileStore のほか、独自の Store も使える。
+//
+//	store := tasks.NewFileStore("")
+//	m, err := tasks.Open(store)
+//	if err != nil {
+//		return err
+//	}
+//	task, _ := m.Add("牛乳を買う")
+//	m.SetPriority(task.ID, "A")
+//	return m.Save(store)
+//
+// Manager が返すタスクはコピーなので、変更しても Manager のタスクは変わらない。
+// タスクは Manager のメソッドで変更し、変更日時と変更履歴を記録する。
+//
+// # モジュールのパス
+//
+// モジュールのパスはドメインのない godo のため go get では取得できない。
+// 使う側の go.mod で godo のリポジトリを clone した場所を replace で指定する。
+//
+//	require godo v0.0.0
+//	replace godo => ../godo
+//
+// # バージョン
+//
+// このパッケージの API は Version のセマンティックバージョニングに従う。
+// メジャーバージョンが同じ間は、エクスポートした名前の削除や互換性のない変更はしない。
+// タスクの JSON 表現（Task の json タグ）も同じ方針で変更する。
+package tasks
+
+// Version このパッケージの API のバージョン
+const Version = "1.0.0"
@@ -0,0 +1,110 @@
+package tasks_test
+
+import (
+	"fmt"
+	"godo/pkg/tasks"
+	"os"
+	"time"
+)
+
+func Example() {
+	dir, _ := os.MkdirTemp("", "godo")
+	defer os.RemoveAll(dir)
+
+	// godo と同じ形式のファイルに保存する
+	store := tasks.NewFileStore(dir)
+	m, err := tasks.Open(store)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	task, _ := m.Add("牛乳を買う")
+	m.SetPriority(task.ID, "A")
+	if err := m.Save(store); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 別のプログラムから読み込む
+	reopened, _ := tasks.Open(tasks.NewFileStore(dir))
+	saved, _ := reopened.Get(task.ID)
+	fmt.Println(saved.ID, saved.Title, saved.Priority)
+	// Output: 1 牛乳を買う A
+}
+
+func ExampleManager_List() {
+	m := tasks.New(nil)
+	for _, title := range []string{"レポートを書く", "牛乳を買う", "パンを買う"} {
+		m.Add(title)
+	}
+	m.SetPriority(3, "A")
+	m.SetPriority(1, "B")
+	m.Toggle(2)
+
+	notCompleted := false
+	for _, task := range m.List(tasks.Filter{Completed: &notCompleted}, tasks.SortByPriority) {
+		fmt.Println(task.Priority, task.Title)
+	}
+	// Output:
+	// A パンを買う
+	// B レポートを書く
+}
+
+func ExampleManager_Get() {
+	m := tasks.New(nil)
+	task, _ := m.Add("牛乳を買う")
+
+	// 返されるのはコピーなので、変更しても Manager のタスクは変わらない
+	task.Title = "豆乳を買う"
+	got, _ := m.Get(task.ID)
+	fmt.Println(got.Title)
+
+	if _, err := m.Get(99); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 牛乳を買う
+	// ID 99 のタスクが見つかりません
+}
+
+func ExampleFilter() {
+	due := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	list := []*tasks.Task{
+		{ID: 1, Title: "レポートを書く", Project: "work", DueAt: &due},
+		{ID: 2, Title: "牛乳を買う", Project: "home"},
+		{ID: 3, Title: "Review PR", Project: "work"},
+	}
+	workflow := tasks.DefaultWorkflow()
+
+	for _, task := range tasks.Select(list, workflow, tasks.Filter{Project: "work"}) {
+		fmt.Println(task.Title)
+	}
+	for _, task := range tasks.Select(list, workflow, tasks.Filter{DueBefore: due.AddDate(0, 0, 7)}) {
+		fmt.Println("期限:", task.Title)
+	}
+	// Output:
+	// レポートを書く
+	// Review PR
+	// 期限: レポートを書く
+}
+
+func ExampleSort() {
+	list := []*tasks.Task{
+		{ID: 1, Title: "b"},
+		{ID: 2, Title: "c", Priority: "B"},
+		{ID: 3, Title: "a", Priority: "A"},
+	}
+	tasks.Sort(list, tasks.SortByTitle)
+	fmt.Println(list[0].Title, list[1].Title, list[2].Title)
+
+	order, err := tasks.ParseSortOrder("priority")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tasks.Sort(list, order)
+	fmt.Println(list[0].ID, list[1].ID, list[2].ID)
+	// Output:
+	// a b c
+	// 3 2 1
+}
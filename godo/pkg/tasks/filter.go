@@ -0,0 +1,123 @@
+package tasks
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Filter タスクの絞り込み条件。空の項目では絞り込まない
+type Filter struct {
+	Completed *bool
+	Project   string
+	Priority  string
+	Status    string
+	Tag       string
+	Query     string    // タイトルかプロジェクトに含む文字列（大文字小文字は区別しない）
+	DueBefore time.Time // この日時より前に期限があるタスク
+}
+
+// Match タスクが絞り込み条件に合うかを返す
+// 状態（Status）は workflow で判断する
+func (f Filter) Match(workflow Workflow, task *Task) bool {
+	if f.Completed != nil && task.Completed != *f.Completed {
+		return false
+	}
+	if f.Project != "" && task.Project != f.Project {
+		return false
+	}
+	if f.Priority != "" && task.Priority != f.Priority {
+		return false
+	}
+	if f.Status != "" && workflow.StatusOf(task) != f.Status {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(task.Tags, f.Tag) {
+		return false
+	}
+	if q := strings.ToLower(strings.TrimSpace(f.Query)); q != "" &&
+		!strings.Contains(strings.ToLower(task.Title), q) && !strings.Contains(strings.ToLower(task.Project), q) {
+		return false
+	}
+	if !f.DueBefore.IsZero() && (task.DueAt == nil || !task.DueAt.Before(f.DueBefore)) {
+		return false
+	}
+	return true
+}
+
+// Select tasks のうち絞り込み条件に合うタスクを返す（tasks の並びは変えない）
+func Select(tasks []*Task, workflow Workflow, filter Filter) []*Task {
+	var selected []*Task
+	for _, task := range tasks {
+		if filter.Match(workflow, task) {
+			selected = append(selected, task)
+		}
+	}
+	return selected
+}
+
+// SortOrder タスクの並び順
+type SortOrder string
+
+const (
+	SortByID       SortOrder = "id"       // 追加した順（デフォルト）
+	SortByPriority SortOrder = "priority" // 優先度の高い順（優先度なしは最後）
+	SortByDue      SortOrder = "due"      // 期限の近い順（期限なしは最後）
+	SortByCreated  SortOrder = "created"  // 作成日時の新しい順
+	SortByUpdated  SortOrder = "updated"  // 更新日時の新しい順
+	SortByTitle    SortOrder = "title"    // タイトルの辞書順
+)
+
+// SortOrders 指定できる並び順
+var SortOrders = []SortOrder{SortByID, SortByPriority, SortByDue, SortByCreated, SortByUpdated, SortByTitle}
+
+// ParseSortOrder 並び順の名前を解釈する（空文字は SortByID）
+func ParseSortOrder(name string) (SortOrder, error) {
+	if name == "" {
+		return SortByID, nil
+	}
+	if order := SortOrder(name); slices.Contains(SortOrders, order) {
+		return order, nil
+	}
+	names := make([]string, len(SortOrders))
+	for i, order := range SortOrders {
+		names[i] = string(order)
+	}
+	return "", fmt.Errorf("並び順が不正です: %q (%s)", name, strings.Join(names, ", "))
+}
+
+// Sort tasks を order の順に並べ替える。同じ順位のタスクは ID の順にする
+func Sort(tasks []*Task, order SortOrder) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch order {
+		case SortByPriority:
+			if a.Priority != b.Priority {
+				return b.Priority == "" || (a.Priority != "" && a.Priority < b.Priority)
+			}
+		case SortByDue:
+			switch {
+			case a.DueAt == nil && b.DueAt == nil:
+			case a.DueAt == nil || b.DueAt == nil:
+				return b.DueAt == nil
+			case !a.DueAt.Equal(*b.DueAt):
+				return a.DueAt.Before(*b.DueAt)
+			}
+		case SortByCreated:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		case SortByUpdated:
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.After(b.UpdatedAt)
+			}
+		case SortByTitle:
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,54 @@
+package tasks
+
+import (
+	"godo/internal/storage"
+	"godo/pkg/models"
+	"os"
+	"path/filepath"
+)
+
+// FileStore godo と同じ形式のファイルにタスクを保存する Store
+// タスクは dir の tasks.json に、変更履歴は history.jsonl に保存する
+// godo を --storage journal で使っている（journal.jsonl がある）場合はジャーナルに追記する
+type FileStore struct {
+	storage  *storage.TaskStorage
+	Workflow Workflow // 読み込んだタスクの状態を判断するワークフロー
+}
+
+// NewFileStore dir にタスクを保存する FileStore を作成する（空なら godo と同じ ~/.godo）
+func NewFileStore(dir string) *FileStore {
+	if dir == "" {
+		dir = storage.DefaultDir()
+	}
+	ts := storage.NewTaskStorageAt(dir)
+	if _, err := os.Stat(filepath.Join(dir, storage.JournalFileName)); err == nil {
+		ts.SetMode(storage.JournalMode)
+	}
+	return &FileStore{storage: ts, Workflow: models.DefaultWorkflow()}
+}
+
+// Load タスクと変更履歴を読み込む
+func (s *FileStore) Load() (*models.TaskManager, error) {
+	tm, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	tm.SetWorkflow(s.Workflow)
+	history, err := s.storage.LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+	tm.LoadHistory(history)
+	return tm, nil
+}
+
+// Save タスクとまだ保存していない変更履歴を保存する
+func (s *FileStore) Save(tm *models.TaskManager) error {
+	return s.storage.Save(tm)
+}
+
+// Lock 読み込みから保存までの間、同じディレクトリを使う他のプロセス（godo serve など）が書き込まないようにロックを取る
+// ロックを外す関数を返す
+func (s *FileStore) Lock() (unlock func(), err error) {
+	return s.storage.Lock()
+}
@@ -0,0 +1,235 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"godo/pkg/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// godo のタスクの型
+type (
+	Task       = models.Task
+	TimeEntry  = models.TimeEntry
+	Annotation = models.Annotation
+	Event      = models.Event
+	Workflow   = models.Workflow
+	Store      = models.Store
+)
+
+// DefaultWorkflow godo のデフォルトのワークフロー（todo / doing / review / done）を返す
+func DefaultWorkflow() Workflow {
+	return models.DefaultWorkflow()
+}
+
+// ErrNotFound 指定した ID のタスクがない
+var ErrNotFound = errors.New("タスクが見つかりません")
+
+// Manager 複数の goroutine から同時に使えるタスクの管理
+// 変更はすべて Manager のメソッドを通して行い、読み取りのメソッドはタスクのコピーを返す
+type Manager struct {
+	mu sync.RWMutex
+	tm *models.TaskManager
+}
+
+// New tasks を管理する Manager を作成する
+func New(tasks []*Task) *Manager {
+	return Wrap(models.NewTaskManager(tasks))
+}
+
+// Wrap 既存の TaskManager を Manager にする
+// 以降は tm を直接使わず、Manager を通して操作すること
+func Wrap(tm *models.TaskManager) *Manager {
+	return &Manager{tm: tm}
+}
+
+// Open store からタスクを読み込む
+func Open(store Store) (*Manager, error) {
+	tm, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(tm), nil
+}
+
+// Save タスクとまだ保存していない変更履歴を store に保存する
+func (m *Manager) Save(store Store) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return store.Save(m.tm)
+}
+
+// Add タスクを追加し、追加したタスクを返す
+func (m *Manager) Add(title string) (*Task, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("タイトルを入力してください")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tm.AddTask(title)
+	tasks := m.tm.GetTasks()
+	return tasks[len(tasks)-1].Clone(), nil
+}
+
+// Get ID のタスクを返す
+func (m *Manager) Get(id int) (*Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	index, err := m.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.tm.GetTaskByIndex(index).Clone(), nil
+}
+
+// List 絞り込み条件に合うタスクを order の順に返す
+func (m *Manager) List(filter Filter, order SortOrder) []*Task {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	workflow := m.tm.Workflow()
+	var tasks []*Task
+	for _, task := range m.tm.GetTasks() {
+		if filter.Match(workflow, task) {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+	Sort(tasks, order)
+	return tasks
+}
+
+// Stats 完了済みのタスク数とタスクの総数を返す
+func (m *Manager) Stats() (completed, total int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tm.GetStats()
+}
+
+// History ID のタスクの変更履歴を古い順に返す
+func (m *Manager) History(id int) []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tm.History(id)
+}
+
+// Toggle 完了と未完了を切り替える
+func (m *Manager) Toggle(id int) (*Task, error) {
+	return m.modify(id, func(index int) error {
+		m.tm.ToggleTask(index)
+		return nil
+	})
+}
+
+// Rename タイトルを変更する
+func (m *Manager) Rename(id int, title string) (*Task, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("タイトルを入力してください")
+	}
+	return m.modify(id, func(index int) error {
+		m.tm.UpdateTask(index, title)
+		return nil
+	})
+}
+
+// SetPriority 優先度（"A"〜"Z"、空文字で解除）を設定する
+func (m *Manager) SetPriority(id int, priority string) (*Task, error) {
+	if err := models.ValidatePriority(priority); err != nil {
+		return nil, err
+	}
+	return m.modify(id, func(index int) error {
+		m.tm.SetPriority(index, priority)
+		return nil
+	})
+}
+
+// SetProject プロジェクトを設定する（空文字で解除）
+func (m *Manager) SetProject(id int, project string) (*Task, error) {
+	return m.modify(id, func(index int) error {
+		m.tm.SetProject(index, strings.TrimSpace(project))
+		return nil
+	})
+}
+
+// SetTags タグを設定する
+func (m *Manager) SetTags(id int, tags []string) (*Task, error) {
+	return m.modify(id, func(index int) error {
+		m.tm.SetTags(index, append([]string(nil), tags...))
+		return nil
+	})
+}
+
+// SetDue 期限を設定する（nil で解除）
+func (m *Manager) SetDue(id int, due *time.Time) (*Task, error) {
+	if due != nil {
+		d := *due
+		due = &d
+	}
+	return m.modify(id, func(index int) error {
+		m.tm.SetDue(index, due)
+		return nil
+	})
+}
+
+// SetStatus ワークフロー上の状態を設定する。最後の列の状態にすると完了になる
+func (m *Manager) SetStatus(id int, status string) (*Task, error) {
+	return m.modify(id, func(index int) error {
+		if !m.tm.SetStatus(index, status) {
+			return fmt.Errorf("状態が不正です: %q (%s)", status, m.tm.Workflow())
+		}
+		return nil
+	})
+}
+
+// Delete タスクを削除する
+func (m *Manager) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index, err := m.find(id)
+	if err != nil {
+		return err
+	}
+	m.tm.DeleteTask(index)
+	return nil
+}
+
+// Update 他の goroutine の変更を止めた状態で、fn に TaskManager を渡して直接操作させる
+// 複数の変更をまとめて行うときや、Manager にない操作をするときに使う。fn の外に tm を持ち出さないこと
+func (m *Manager) Update(fn func(tm *models.TaskManager) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(m.tm)
+}
+
+// View 他の goroutine の変更を止めた状態で、fn に TaskManager を渡して読み取らせる
+// fn の中でタスクを変更しないこと
+func (m *Manager) View(fn func(tm *models.TaskManager) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(m.tm)
+}
+
+// modify ID のタスクを fn で変更し、変更後のタスクを返す
+func (m *Manager) modify(id int, fn func(index int) error) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index, err := m.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(index); err != nil {
+		return nil, err
+	}
+	return m.tm.GetTaskByIndex(index).Clone(), nil
+}
+
+// find ID のタスクのインデックスを返す
+func (m *Manager) find(id int) (int, error) {
+	index := m.tm.FindIndexByID(id)
+	if index < 0 {
+		return -1, fmt.Errorf("ID %d の%w", id, ErrNotFound)
+	}
+	return index, nil
+}
@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerModifies(t *testing.T) {
+	m := New(nil)
+	task, err := m.Add("  牛乳を買う ")
+	if err != nil || task.ID != 1 || task.Title != "牛乳を買う" {
+		t.Fatalf("Add = %+v, %v", task, err)
+	}
+	if _, err := m.Add(" "); err == nil {
+		t.Error("Add accepted an empty title")
+	}
+
+	due := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	steps := []func() (*Task, error){
+		func() (*Task, error) { return m.Rename(1, "豆乳を買う") },
+		func() (*Task, error) { return m.SetPriority(1, "A") },
+		func() (*Task, error) { return m.SetProject(1, "home") },
+		func() (*Task, error) { return m.SetTags(1, []string{"shopping"}) },
+		func() (*Task, error) { return m.SetDue(1, &due) },
+		func() (*Task, error) { return m.SetStatus(1, "doing") },
+	}
+	for i, step := range steps {
+		if _, err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	task, _ = m.Get(1)
+	if task.Title != "豆乳を買う" || task.Priority != "A" || task.Project != "home" || task.Tags[0] != "shopping" ||
+		!task.DueAt.Equal(due) || task.Status != "doing" {
+		t.Fatalf("task = %+v", task)
+	}
+	if len(m.History(1)) != 7 {
+		t.Errorf("history = %v", m.History(1))
+	}
+
+	if _, err := m.SetPriority(1, "high"); err == nil {
+		t.Error("SetPriority accepted an invalid priority")
+	}
+	if _, err := m.SetStatus(1, "blocked"); err == nil {
+		t.Error("SetStatus accepted an unknown status")
+	}
+	if task, _ := m.Toggle(1); !task.Completed {
+		t.Errorf("Toggle = %+v", task)
+	}
+	if completed, total := m.Stats(); completed != 1 || total != 1 {
+		t.Errorf("Stats = %d, %d", completed, total)
+	}
+	if err := m.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Toggle(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Toggle after delete = %v", err)
+	}
+}
+
+func TestManagerReturnsCopies(t *testing.T) {
+	m := New(nil)
+	m.Add("牛乳を買う")
+	m.SetTags(1, []string{"shopping"})
+
+	task, _ := m.Get(1)
+	task.Completed = true
+	task.Tags[0] = "changed"
+	m.List(Filter{}, SortByID)[0].Title = "changed"
+
+	got, _ := m.Get(1)
+	if got.Completed || got.Tags[0] != "shopping" || got.Title != "牛乳を買う" {
+		t.Fatalf("task was changed through a copy: %+v", got)
+	}
+}
+
+func TestManagerConcurrentAccess(t *testing.T) {
+	m := New(nil)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				task, _ := m.Add(fmt.Sprintf("タスク %d-%d", i, j))
+				m.Toggle(task.ID)
+				m.SetPriority(task.ID, "A")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				for _, task := range m.List(Filter{Priority: "A"}, SortByUpdated) {
+					_ = task.Title
+				}
+				m.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+	if completed, total := m.Stats(); completed != 160 || total != 160 {
+		t.Fatalf("Stats = %d, %d", completed, total)
+	}
+}
+
+func TestSort(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	list := []*Task{
+		{ID: 1, Title: "c", DueAt: day(3), CreatedAt: *day(1), UpdatedAt: *day(5)},
+		{ID: 2, Title: "a", Priority: "B", CreatedAt: *day(2), UpdatedAt: *day(4)},
+		{ID: 3, Title: "b", Priority: "A", DueAt: day(1), CreatedAt: *day(3), UpdatedAt: *day(6)},
+		{ID: 4, Title: "a", DueAt: day(2), CreatedAt: *day(3), UpdatedAt: *day(6)},
+	}
+	tests := []struct {
+		order SortOrder
+		want  []int
+	}{
+		{SortByID, []int{1, 2, 3, 4}},
+		{SortByPriority, []int{3, 2, 1, 4}},
+		{SortByDue, []int{3, 4, 1, 2}},
+		{SortByCreated, []int{3, 4, 2, 1}},
+		{SortByUpdated, []int{3, 4, 1, 2}},
+		{SortByTitle, []int{2, 4, 3, 1}},
+	}
+	for _, tt := range tests {
+		Sort(list, tt.order)
+		for i, id := range tt.want {
+			if list[i].ID != id {
+				t.Errorf("%s: got %v, want %v", tt.order, ids(list), tt.want)
+				break
+			}
+		}
+	}
+	if _, err := ParseSortOrder("size"); err == nil {
+		t.Error("ParseSortOrder accepted an unknown order")
+	}
+}
+
+func ids(list []*Task) []int {
+	var ids []int
+	for _, task := range list {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
@@ -0,0 +1,6 @@
+// Package proto は gRPC の API の定義（godo/v1/tasks.proto）を置く
+//
+// 定義を変更したら protoc と protoc-gen-go / protoc-gen-go-grpc をインストールして go generate ./proto を実行する。
+package proto
+
+//go:generate protoc -I . --go_out=../.. --go-grpc_out=../.. godo/v1/tasks.proto